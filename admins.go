@@ -0,0 +1,382 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Роли сотрудников
+const (
+	RoleSuperAdmin     = "super_admin"
+	RoleHandler        = "handler"
+	RoleViewer         = "viewer"
+	RoleDepartmentHead = "department_head"
+)
+
+var supportedRoles = []string{RoleSuperAdmin, RoleHandler, RoleViewer, RoleDepartmentHead}
+
+// Permission - действие, доступ к которому определяется ролью
+type Permission string
+
+const (
+	PermViewStats         Permission = "view_stats"
+	PermViewFeedback      Permission = "view_feedback"
+	PermHandleFeedback    Permission = "handle_feedback"
+	PermManageDepartments Permission = "manage_departments"
+	PermManageForms       Permission = "manage_forms"
+	PermManageAdmins      Permission = "manage_admins"
+	PermManageSLA         Permission = "manage_sla"
+	// PermViewPersonalData - имя, username и телефон автора в выгрузках
+	PermViewPersonalData Permission = "view_personal_data"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleSuperAdmin: {
+		PermViewStats, PermViewFeedback, PermHandleFeedback, PermViewPersonalData,
+		PermManageDepartments, PermManageForms, PermManageAdmins, PermManageSLA,
+	},
+	RoleHandler:        {PermViewStats, PermViewFeedback, PermHandleFeedback, PermViewPersonalData},
+	RoleViewer:         {PermViewStats, PermViewFeedback},
+	RoleDepartmentHead: {PermViewStats, PermViewFeedback, PermHandleFeedback, PermViewPersonalData},
+}
+
+// Admin - сотрудник с доступом к административным функциям бота.
+// Заведующий отделением (department_head) видит и обрабатывает только обращения своего отделения.
+type Admin struct {
+	UserID       int64     `json:"user_id"`
+	Role         string    `json:"role"`
+	DepartmentID int64     `json:"department_id,omitempty"`
+	Name         string    `json:"name,omitempty"`
+	Email        string    `json:"email,omitempty"` // для уведомлений об эскалациях
+	AddedBy      int64     `json:"added_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (a *Admin) Can(permission Permission) bool {
+	for _, p := range rolePermissions[a.Role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeDepartmentID - отделение, которым ограничен доступ; 0 - без ограничений
+func (a *Admin) ScopeDepartmentID() int64 {
+	if a.Role == RoleDepartmentHead {
+		return a.DepartmentID
+	}
+	return 0
+}
+
+// CanAccess проверяет, что обращение входит в зону ответственности сотрудника
+func (a *Admin) CanAccess(feedback *Feedback) bool {
+	scope := a.ScopeDepartmentID()
+	return scope == 0 || feedback.DepartmentID == scope
+}
+
+func isSupportedRole(role string) bool {
+	for _, r := range supportedRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Database) GetAdmin(userID int64) (*Admin, error) {
+	query := `
+	SELECT user_id, role, COALESCE(department_id, 0), COALESCE(name, ''), COALESCE(email, ''), COALESCE(added_by, 0), created_at
+	FROM admins
+	WHERE user_id = ?
+	`
+
+	admin := &Admin{}
+	err := d.db.QueryRow(query, userID).Scan(
+		&admin.UserID,
+		&admin.Role,
+		&admin.DepartmentID,
+		&admin.Name,
+		&admin.Email,
+		&admin.AddedBy,
+		&admin.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin: %w", err)
+	}
+	return admin, nil
+}
+
+func (d *Database) ListAdmins() ([]*Admin, error) {
+	query := `
+	SELECT user_id, role, COALESCE(department_id, 0), COALESCE(name, ''), COALESCE(email, ''), COALESCE(added_by, 0), created_at
+	FROM admins
+	ORDER BY role ASC, user_id ASC
+	`
+
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query admins: %w", err)
+	}
+	defer rows.Close()
+
+	var admins []*Admin
+	for rows.Next() {
+		admin := &Admin{}
+		err := rows.Scan(
+			&admin.UserID,
+			&admin.Role,
+			&admin.DepartmentID,
+			&admin.Name,
+			&admin.Email,
+			&admin.AddedBy,
+			&admin.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan admin: %w", err)
+		}
+		admins = append(admins, admin)
+	}
+
+	return admins, nil
+}
+
+// SaveAdmin добавляет сотрудника или меняет роль существующего
+func (d *Database) SaveAdmin(admin *Admin) error {
+	query := `
+	INSERT INTO admins (user_id, role, department_id, name, added_by)
+	VALUES (?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE role = VALUES(role), department_id = VALUES(department_id),
+		name = COALESCE(VALUES(name), name), added_by = VALUES(added_by)
+	`
+
+	_, err := d.db.Exec(query,
+		admin.UserID,
+		admin.Role,
+		nullInt64(admin.DepartmentID),
+		nullString(admin.Name),
+		nullInt64(admin.AddedBy),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save admin: %w", err)
+	}
+	return nil
+}
+
+// SetAdminEmail задает адрес почты сотрудника; пустой адрес удаляет его.
+// false - сотрудника нет. Наличие проверяется отдельным запросом: если адрес не изменился,
+// MySQL не считает строку затронутой и RowsAffected вернул бы 0.
+func (d *Database) SetAdminEmail(userID int64, email string) (bool, error) {
+	admin, err := d.GetAdmin(userID)
+	if err != nil {
+		return false, err
+	}
+	if admin == nil {
+		return false, nil
+	}
+
+	if _, err := d.db.Exec(`UPDATE admins SET email = ? WHERE user_id = ?`, nullString(email), userID); err != nil {
+		return false, fmt.Errorf("failed to set admin email: %w", err)
+	}
+	return true, nil
+}
+
+// RemoveAdmin удаляет сотрудника; false - сотрудника нет
+func (d *Database) RemoveAdmin(userID int64) (bool, error) {
+	admin, err := d.GetAdmin(userID)
+	if err != nil {
+		return false, err
+	}
+	if admin == nil {
+		return false, nil
+	}
+
+	if _, err := d.db.Exec(`DELETE FROM admins WHERE user_id = ?`, userID); err != nil {
+		return false, fmt.Errorf("failed to remove admin: %w", err)
+	}
+	return true, nil
+}
+
+// admin возвращает сотрудника по ID пользователя или nil.
+// ADMIN_USER_ID из окружения всегда считается супер-администратором,
+// чтобы после первого запуска было кому назначить остальных.
+func (t *TelegramBot) admin(userID int64) *Admin {
+	if bootstrapID := getEnvAsInt64("ADMIN_USER_ID", 0); bootstrapID != 0 && userID == bootstrapID {
+		return &Admin{UserID: userID, Role: RoleSuperAdmin}
+	}
+
+	admin, err := t.database.GetAdmin(userID)
+	if err != nil {
+		t.logger.Error("Failed to get admin: ", err)
+		return nil
+	}
+	return admin
+}
+
+// authorize возвращает сотрудника, если его роль разрешает действие, иначе nil
+func (t *TelegramBot) authorize(userID int64, permission Permission) *Admin {
+	admin := t.admin(userID)
+	if admin == nil || !admin.Can(permission) {
+		return nil
+	}
+	return admin
+}
+
+// canAccessFeedback проверяет и роль, и отделение сотрудника для конкретного обращения
+func (t *TelegramBot) canAccessFeedback(userID int64, permission Permission, feedback *Feedback) bool {
+	admin := t.authorize(userID, permission)
+	return admin != nil && admin.CanAccess(feedback)
+}
+
+// roleDisplayName - название роли на языке lang
+func roleDisplayName(lang, role string) string {
+	return Translate(lang, "role."+role)
+}
+
+// handleAdminCommand обрабатывает /admins, /admin_add, /admin_email и /admin_remove
+func (t *TelegramBot) handleAdminCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+
+	switch message.Command() {
+	case "admins":
+		admins, err := t.database.ListAdmins()
+		if err != nil {
+			t.logger.Error("Failed to list admins: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+			return
+		}
+
+		lang := t.lang(chatID)
+		var sb strings.Builder
+		sb.WriteString(t.tr(chatID, "admins.title") + "\n\n")
+		if len(admins) == 0 {
+			sb.WriteString(t.tr(chatID, "admins.empty") + "\n")
+		}
+		for _, admin := range admins {
+			sb.WriteString(fmt.Sprintf("• %d — %s", admin.UserID, roleDisplayName(lang, admin.Role)))
+			if admin.DepartmentID != 0 {
+				if department, err := t.database.GetDepartment(admin.DepartmentID); err == nil && department != nil {
+					sb.WriteString(" (" + department.Name + ")")
+				}
+			}
+			if admin.Name != "" {
+				sb.WriteString(" — " + admin.Name)
+			}
+			if admin.Email != "" {
+				sb.WriteString(" <" + admin.Email + ">")
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n" + t.tr(chatID, "admins.hint"))
+		t.sendMessage(chatID, sb.String())
+	case "admin_add":
+		if len(args) < 2 {
+			t.sendMessage(chatID, t.tr(chatID, "admins.add_usage"))
+			return
+		}
+
+		userID, err := strconv.ParseInt(args[0], 10, 64)
+		role := strings.ToLower(args[1])
+		if err != nil || userID <= 0 || !isSupportedRole(role) {
+			t.sendMessage(chatID, t.tr(chatID, "admins.add_usage"))
+			return
+		}
+
+		admin := &Admin{UserID: userID, Role: role, AddedBy: message.From.ID}
+		rest := args[2:]
+		if role == RoleDepartmentHead {
+			if len(rest) == 0 {
+				t.sendMessage(chatID, t.tr(chatID, "admins.department_required"))
+				return
+			}
+			department, err := t.database.GetDepartmentByCode(rest[0])
+			if err != nil {
+				t.logger.Error("Failed to get department: ", err)
+			}
+			if department == nil {
+				t.sendMessage(chatID, t.tr(chatID, "departments.not_found"))
+				return
+			}
+			admin.DepartmentID = department.ID
+			rest = rest[1:]
+		} else if role == RoleHandler && len(rest) > 0 {
+			// Обработчик может быть закреплен за отделением для автоназначения, видит он при этом все обращения
+			if department, err := t.database.GetDepartmentByCode(rest[0]); err == nil && department != nil {
+				admin.DepartmentID = department.ID
+				rest = rest[1:]
+			}
+		}
+		admin.Name = strings.Join(rest, " ")
+
+		if err := t.database.SaveAdmin(admin); err != nil {
+			t.logger.Error("Failed to save admin: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "error.save"))
+			return
+		}
+		t.sendMessage(chatID, t.tr(chatID, "admins.saved", admin.UserID, roleDisplayName(t.lang(chatID), admin.Role)))
+	case "admin_email":
+		if len(args) < 1 || len(args) > 2 {
+			t.sendMessage(chatID, t.tr(chatID, "admins.email_usage"))
+			return
+		}
+
+		userID, err := strconv.ParseInt(args[0], 10, 64)
+		email := ""
+		if len(args) == 2 {
+			email = args[1]
+		}
+		if err != nil || (email != "" && !strings.Contains(email, "@")) {
+			t.sendMessage(chatID, t.tr(chatID, "admins.email_usage"))
+			return
+		}
+
+		found, err := t.database.SetAdminEmail(userID, email)
+		if err != nil {
+			t.logger.Error("Failed to set admin email: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "error.save"))
+			return
+		}
+		if !found {
+			t.sendMessage(chatID, t.tr(chatID, "admins.not_found"))
+			return
+		}
+		t.sendMessage(chatID, t.tr(chatID, "admins.email_saved"))
+	case "admin_remove":
+		if len(args) != 1 {
+			t.sendMessage(chatID, t.tr(chatID, "admins.remove_usage"))
+			return
+		}
+
+		userID, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			t.sendMessage(chatID, t.tr(chatID, "admins.remove_usage"))
+			return
+		}
+		// Не даем супер-администратору случайно лишить себя доступа
+		if userID == message.From.ID {
+			t.sendMessage(chatID, t.tr(chatID, "admins.remove_self"))
+			return
+		}
+
+		found, err := t.database.RemoveAdmin(userID)
+		if err != nil {
+			t.logger.Error("Failed to remove admin: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+			return
+		}
+		if !found {
+			t.sendMessage(chatID, t.tr(chatID, "admins.not_found"))
+			return
+		}
+		t.sendMessage(chatID, t.tr(chatID, "admins.removed"))
+	}
+}
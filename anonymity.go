@@ -0,0 +1,287 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Режимы хранения личности автора анонимного обращения
+const (
+	// AnonymityMapping - данные автора лежат в отдельной таблице feedback_identities
+	AnonymityMapping = "mapping"
+	// AnonymityNone - данные автора не хранятся, остается только зашифрованный чат для ответа
+	AnonymityNone = "none"
+)
+
+// FeedbackIdentity - связь анонимного обращения с автором.
+// Таблица feedback_identities читается только для доставки ответа пациенту
+// и не используется в письмах, статистике и выгрузках.
+type FeedbackIdentity struct {
+	FeedbackID   int64
+	ReplyToken   string
+	UserHash     string
+	UserID       int64
+	ChatID       int64
+	ChatIDSealed []byte
+	Username     string
+	FirstName    string
+	LastName     string
+	CreatedAt    time.Time
+}
+
+// IdentityVault скрывает личность автора анонимного обращения
+type IdentityVault struct {
+	mode string
+	key  []byte
+}
+
+func NewIdentityVaultFromEnv() (*IdentityVault, error) {
+	mode := getEnv("ANONYMITY_STORAGE", AnonymityMapping)
+	if mode != AnonymityMapping && mode != AnonymityNone {
+		return nil, fmt.Errorf("unknown anonymity storage: %s", mode)
+	}
+
+	// Секрет нужен в любом режиме: без него user_hash - HMAC на известном ключе,
+	// и перебором Telegram ID можно найти автора анонимного обращения
+	secret := getEnv("ANONYMITY_SECRET", "")
+	if secret == "" {
+		return nil, fmt.Errorf("ANONYMITY_SECRET is required")
+	}
+
+	key := sha256.Sum256([]byte(secret))
+	return &IdentityVault{mode: mode, key: key[:]}, nil
+}
+
+// NewIdentity формирует запись о личности автора в соответствии с режимом хранения
+func (v *IdentityVault) NewIdentity(feedbackID int64, replyToken string, from *tgbotapi.User, chatID int64) (*FeedbackIdentity, error) {
+	identity := &FeedbackIdentity{
+		FeedbackID: feedbackID,
+		ReplyToken: replyToken,
+		UserHash:   v.UserHash(from.ID),
+	}
+
+	if v.mode == AnonymityMapping {
+		identity.UserID = from.ID
+		identity.ChatID = chatID
+		identity.Username = from.UserName
+		identity.FirstName = from.FirstName
+		identity.LastName = from.LastName
+		return identity, nil
+	}
+
+	sealed, err := v.seal(chatID)
+	if err != nil {
+		return nil, err
+	}
+	identity.ChatIDSealed = sealed
+	return identity, nil
+}
+
+// ChatID возвращает чат автора для ответа по анонимному обращению
+func (v *IdentityVault) ChatID(identity *FeedbackIdentity) (int64, error) {
+	if identity.ChatID != 0 {
+		return identity.ChatID, nil
+	}
+	if len(identity.ChatIDSealed) == 0 {
+		return 0, fmt.Errorf("identity has no chat for feedback %d", identity.FeedbackID)
+	}
+	return v.open(identity.ChatIDSealed)
+}
+
+// UserHash - необратимый идентификатор пользователя для поиска его анонимных обращений
+func (v *IdentityVault) UserHash(userID int64) string {
+	mac := hmac.New(sha256.New, v.key)
+	mac.Write([]byte(strconv.FormatInt(userID, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (v *IdentityVault) seal(chatID int64) ([]byte, error) {
+	gcm, err := v.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	plain := make([]byte, 8)
+	binary.BigEndian.PutUint64(plain, uint64(chatID))
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func (v *IdentityVault) open(sealed []byte) (int64, error) {
+	gcm, err := v.gcm()
+	if err != nil {
+		return 0, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return 0, fmt.Errorf("sealed chat id is too short")
+	}
+
+	nonce, data := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, data, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open sealed chat id: %w", err)
+	}
+	return int64(binary.BigEndian.Uint64(plain)), nil
+}
+
+func (v *IdentityVault) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(v.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// newReplyToken создает непрозрачный токен для ответа автору анонимного обращения
+func newReplyToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate reply token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// saveFeedbackIdentity сохраняет связь с автором в транзакции сохранения обращения
+func saveFeedbackIdentity(tx *sql.Tx, identity *FeedbackIdentity) error {
+	query := `
+	INSERT INTO feedback_identities (feedback_id, reply_token, user_hash, user_id, chat_id, chat_id_sealed, username, first_name, last_name)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := tx.Exec(query,
+		identity.FeedbackID,
+		identity.ReplyToken,
+		identity.UserHash,
+		nullInt64(identity.UserID),
+		nullInt64(identity.ChatID),
+		identity.ChatIDSealed,
+		nullString(identity.Username),
+		nullString(identity.FirstName),
+		nullString(identity.LastName),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save feedback identity: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) GetFeedbackIdentityByToken(replyToken string) (*FeedbackIdentity, error) {
+	query := `
+	SELECT feedback_id, reply_token, user_hash, COALESCE(user_id, 0), COALESCE(chat_id, 0), chat_id_sealed,
+		COALESCE(username, ''), COALESCE(first_name, ''), COALESCE(last_name, ''), created_at
+	FROM feedback_identities
+	WHERE reply_token = ?
+	`
+
+	identity := &FeedbackIdentity{}
+	err := d.db.QueryRow(query, replyToken).Scan(
+		&identity.FeedbackID,
+		&identity.ReplyToken,
+		&identity.UserHash,
+		&identity.UserID,
+		&identity.ChatID,
+		&identity.ChatIDSealed,
+		&identity.Username,
+		&identity.FirstName,
+		&identity.LastName,
+		&identity.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback identity: %w", err)
+	}
+
+	return identity, nil
+}
+
+// askAnonymity предлагает отправить обращение анонимно
+func (t *TelegramBot) askAnonymity(chatID int64, state *UserState) bool {
+	if getEnv("ANONYMOUS_MODE_ENABLED", "true") != "true" {
+		return false
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "anonymity.no"), "anon:no"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "anonymity.yes"), "anon:yes"),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, t.tr(chatID, "anonymity.ask"))
+	msg.ReplyMarkup = keyboard
+	t.bot.Send(msg)
+	return true
+}
+
+// handleAnonymitySelection обрабатывает нажатие anon:yes / anon:no
+func (t *TelegramBot) handleAnonymitySelection(chatID int64, from *tgbotapi.User, state *UserState, value string) {
+	if state.State != StateWaitingForAnonymity {
+		t.sendMainMenu(chatID, from.ID)
+		return
+	}
+
+	if value == "yes" {
+		state.Data["anonymous"] = "1"
+	} else {
+		delete(state.Data, "anonymous")
+	}
+	t.advanceForm(chatID, from, state)
+}
+
+// anonymousIdentity готовит связь анонимного обращения с автором; для обычного обращения - nil.
+// ID обращения проставляет SaveFeedback в той же транзакции.
+func (t *TelegramBot) anonymousIdentity(feedback *Feedback, from *tgbotapi.User, chatID int64) (*FeedbackIdentity, error) {
+	if !feedback.Anonymous {
+		return nil, nil
+	}
+	return t.vault.NewIdentity(feedback.ID, feedback.ReplyToken, from, chatID)
+}
+
+// patientChatID определяет чат автора обращения, в том числе анонимного
+func (t *TelegramBot) patientChatID(feedback *Feedback) (int64, error) {
+	if !feedback.Anonymous {
+		return feedback.UserID, nil
+	}
+
+	identity, err := t.database.GetFeedbackIdentityByToken(feedback.ReplyToken)
+	if err != nil {
+		return 0, err
+	}
+	if identity == nil {
+		return 0, fmt.Errorf("no identity for feedback %d", feedback.ID)
+	}
+	return t.vault.ChatID(identity)
+}
+
+// SendToPatient доставляет сообщение автору обращения, не раскрывая его личность отправителю.
+// build получает чат автора, чтобы составить сообщение на его языке; вызывающий код чат не видит.
+func (t *TelegramBot) SendToPatient(feedback *Feedback, build func(chatID int64) tgbotapi.MessageConfig) (tgbotapi.Message, error) {
+	chatID, err := t.patientChatID(feedback)
+	if err != nil {
+		return tgbotapi.Message{}, fmt.Errorf("failed to resolve patient chat: %w", err)
+	}
+	sent, err := t.bot.Send(build(chatID))
+	if err != nil {
+		return tgbotapi.Message{}, fmt.Errorf("failed to send message to patient: %w", err)
+	}
+	return sent, nil
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Причины, по которым антиспам отклоняет обращение
+const (
+	SpamRateHour  = "rate_hour"
+	SpamRateDay   = "rate_day"
+	SpamTooShort  = "too_short"
+	SpamTooLong   = "too_long"
+	SpamDuplicate = "duplicate"
+)
+
+var spamReasons = []string{SpamRateHour, SpamRateDay, SpamTooShort, SpamTooLong, SpamDuplicate}
+
+// AntispamConfig - ограничения на отправку обращений; нулевое значение отключает проверку
+type AntispamConfig struct {
+	MaxPerHour        int
+	MaxPerDay         int
+	MinLength         int // в символах; не проверяется, если к обращению приложены файлы
+	MaxLength         int
+	DuplicateWindow   time.Duration
+	DuplicateDistance int // сколько бит simhash могут отличаться у повторного обращения
+}
+
+func antispamConfigFromEnv() AntispamConfig {
+	return AntispamConfig{
+		MaxPerHour:        getEnvAsInt("ANTISPAM_MAX_PER_HOUR", 3),
+		MaxPerDay:         getEnvAsInt("ANTISPAM_MAX_PER_DAY", 10),
+		MinLength:         getEnvAsInt("ANTISPAM_MIN_LENGTH", 10),
+		MaxLength:         getEnvAsInt("ANTISPAM_MAX_LENGTH", 4000),
+		DuplicateWindow:   getEnvAsDuration("ANTISPAM_DUPLICATE_WINDOW", 24*time.Hour),
+		DuplicateDistance: getEnvAsInt("ANTISPAM_DUPLICATE_DISTANCE", 6),
+	}
+}
+
+// retention - сколько хранить журнал отправок, чтобы хватило на все проверки
+func (c AntispamConfig) retention() time.Duration {
+	if c.DuplicateWindow > 24*time.Hour {
+		return c.DuplicateWindow
+	}
+	return 24 * time.Hour
+}
+
+// spamRejection - отказ антиспама с текстом для пользователя
+type spamRejection struct {
+	Reason string
+	Args   []interface{}
+}
+
+// checkLength проверяет длину текста обращения
+func (c AntispamConfig) checkLength(text string, hasAttachments bool) *spamRejection {
+	length := len([]rune(strings.TrimSpace(text)))
+	if c.MaxLength > 0 && length > c.MaxLength {
+		return &spamRejection{Reason: SpamTooLong, Args: []interface{}{length, c.MaxLength}}
+	}
+	if c.MinLength > 0 && length < c.MinLength && !hasAttachments {
+		return &spamRejection{Reason: SpamTooShort, Args: []interface{}{c.MinLength}}
+	}
+	return nil
+}
+
+// normalizeForFingerprint оставляет только буквы и цифры в нижнем регистре (ё считается за е),
+// чтобы повтор с другой пунктуацией, регистром или пробелами давал тот же отпечаток
+func normalizeForFingerprint(text string) []rune {
+	var normalized []rune
+	space := true
+	for _, r := range strings.ReplaceAll(strings.ToLower(text), "ё", "е") {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			normalized = append(normalized, r)
+			space = false
+		} else if !space {
+			normalized = append(normalized, ' ')
+			space = true
+		}
+	}
+	if len(normalized) > 0 && normalized[len(normalized)-1] == ' ' {
+		normalized = normalized[:len(normalized)-1]
+	}
+	return normalized
+}
+
+// textFingerprint - simhash по триграммам символов: у почти одинаковых текстов
+// отпечатки отличаются в нескольких битах
+func textFingerprint(text string) uint64 {
+	runes := normalizeForFingerprint(text)
+	if len(runes) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	add := func(feature []rune) {
+		h := fnv.New64a()
+		h.Write([]byte(string(feature)))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	if len(runes) < 3 {
+		add(runes)
+	}
+	for i := 0; i+3 <= len(runes); i++ {
+		add(runes[i : i+3])
+	}
+
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << uint(bit)
+		}
+	}
+	return fingerprint
+}
+
+// SubmissionCounts возвращает число обращений пользователя за последний час и за сутки
+func (d *Database) SubmissionCounts(userHash string, now time.Time) (int, int, error) {
+	query := `
+	SELECT COALESCE(SUM(created_at >= ?), 0), COUNT(*)
+	FROM feedback_submissions
+	WHERE user_hash = ? AND created_at >= ?
+	`
+
+	var hour, day int
+	err := d.db.QueryRow(query, now.Add(-time.Hour).UTC(), userHash, now.Add(-24*time.Hour).UTC()).Scan(&hour, &day)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count submissions: %w", err)
+	}
+	return hour, day, nil
+}
+
+// RecentFingerprints возвращает отпечатки обращений пользователя, отправленных после since
+func (d *Database) RecentFingerprints(userHash string, since time.Time) ([]uint64, error) {
+	rows, err := d.db.Query(`SELECT fingerprint FROM feedback_submissions WHERE user_hash = ? AND created_at >= ?`, userHash, since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fingerprints: %w", err)
+	}
+	defer rows.Close()
+
+	var fingerprints []uint64
+	for rows.Next() {
+		var fingerprint int64
+		if err := rows.Scan(&fingerprint); err != nil {
+			return nil, fmt.Errorf("failed to scan fingerprint: %w", err)
+		}
+		fingerprints = append(fingerprints, uint64(fingerprint))
+	}
+	return fingerprints, nil
+}
+
+// RecordSubmission записывает обращение, отправленное в момент at, и удаляет записи пользователя старше retention
+func (d *Database) RecordSubmission(userHash string, fingerprint uint64, at, now time.Time, retention time.Duration) error {
+	query := `
+	INSERT INTO feedback_submissions (user_hash, fingerprint, created_at)
+	VALUES (?, ?, ?)
+	`
+
+	if _, err := d.db.Exec(query, userHash, int64(fingerprint), at.UTC()); err != nil {
+		return fmt.Errorf("failed to record submission: %w", err)
+	}
+	if _, err := d.db.Exec(`DELETE FROM feedback_submissions WHERE user_hash = ? AND created_at < ?`, userHash, now.Add(-retention).UTC()); err != nil {
+		return fmt.Errorf("failed to purge submissions: %w", err)
+	}
+	return nil
+}
+
+// CountSpamRejection увеличивает счетчик отказов за день
+func (d *Database) CountSpamRejection(reason string, day time.Time) error {
+	query := `
+	INSERT INTO spam_rejections (day, reason, count)
+	VALUES (?, ?, 1)
+	ON DUPLICATE KEY UPDATE count = count + 1
+	`
+
+	if _, err := d.db.Exec(query, day.Format(holidayLayout), reason); err != nil {
+		return fmt.Errorf("failed to count spam rejection: %w", err)
+	}
+	return nil
+}
+
+// SpamRejectionCounts - число отказов по причинам начиная с дня since
+func (d *Database) SpamRejectionCounts(since time.Time) (map[string]int, error) {
+	rows, err := d.db.Query(`SELECT reason, SUM(count) FROM spam_rejections WHERE day >= ? GROUP BY reason`, since.Format(holidayLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spam rejections: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var reason string
+		var count int
+		if err := rows.Scan(&reason, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan spam rejections: %w", err)
+		}
+		counts[reason] = count
+	}
+	return counts, nil
+}
+
+// checkRateLimit проверяет, не превысил ли пользователь число обращений за час и за сутки
+func (t *TelegramBot) checkRateLimit(userID int64) *spamRejection {
+	if t.antispam.MaxPerHour <= 0 && t.antispam.MaxPerDay <= 0 {
+		return nil
+	}
+
+	hour, day, err := t.database.SubmissionCounts(t.vault.UserHash(userID), time.Now())
+	if err != nil {
+		// Сбой проверки не должен мешать пациенту отправить обращение
+		t.logger.Error("Failed to check rate limit: ", err)
+		return nil
+	}
+	if t.antispam.MaxPerHour > 0 && hour >= t.antispam.MaxPerHour {
+		return &spamRejection{Reason: SpamRateHour, Args: []interface{}{t.antispam.MaxPerHour}}
+	}
+	if t.antispam.MaxPerDay > 0 && day >= t.antispam.MaxPerDay {
+		return &spamRejection{Reason: SpamRateDay, Args: []interface{}{t.antispam.MaxPerDay}}
+	}
+	return nil
+}
+
+// checkDuplicate ищет почти такое же обращение пользователя в окне ANTISPAM_DUPLICATE_WINDOW
+func (t *TelegramBot) checkDuplicate(userID int64, text string) *spamRejection {
+	if t.antispam.DuplicateWindow <= 0 || strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	fingerprints, err := t.database.RecentFingerprints(t.vault.UserHash(userID), time.Now().Add(-t.antispam.DuplicateWindow))
+	if err != nil {
+		t.logger.Error("Failed to check duplicates: ", err)
+		return nil
+	}
+	fingerprint := textFingerprint(text)
+	for _, previous := range fingerprints {
+		if bits.OnesCount64(fingerprint^previous) <= t.antispam.DuplicateDistance {
+			return &spamRejection{Reason: SpamDuplicate}
+		}
+	}
+	return nil
+}
+
+// rejectSpam отвечает пользователю вежливым отказом и увеличивает счетчик причины
+func (t *TelegramBot) rejectSpam(chatID int64, rejection *spamRejection) {
+	if err := t.database.CountSpamRejection(rejection.Reason, nowInTimezone()); err != nil {
+		t.logger.Error("Failed to count spam rejection: ", err)
+	}
+	t.sendMessage(chatID, t.tr(chatID, "antispam."+rejection.Reason, rejection.Args...))
+}
+
+// recordSubmission запоминает отправленное обращение для следующих проверок.
+// Для анонимного обращения время округляется вверх до часа - окна лимита, чтобы запись
+// нельзя было сопоставить с feedback.created_at и узнать автора; лимиты от этого только строже.
+func (t *TelegramBot) recordSubmission(userID int64, text string, anonymous bool) {
+	now := time.Now()
+	at := now
+	if anonymous {
+		at = now.Truncate(time.Hour).Add(time.Hour)
+	}
+	err := t.database.RecordSubmission(t.vault.UserHash(userID), textFingerprint(text), at, now, t.antispam.retention())
+	if err != nil {
+		t.logger.Error("Failed to record submission: ", err)
+	}
+}
+
+// showAntispam показывает ограничения и счетчики отказов за сегодня, 7 и 30 дней
+func (t *TelegramBot) showAntispam(chatID int64) {
+	now := nowInTimezone()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	lang := t.lang(chatID)
+	var periods []map[string]int
+	for _, days := range []int{1, 7, 30} {
+		counts, err := t.database.SpamRejectionCounts(today.AddDate(0, 0, 1-days))
+		if err != nil {
+			t.logger.Error("Failed to get spam rejections: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "stats.error"))
+			return
+		}
+		periods = append(periods, counts)
+	}
+
+	config := t.antispam
+	var sb strings.Builder
+	sb.WriteString(Translate(lang, "antispam.title") + "\n\n")
+	sb.WriteString(Translate(lang, "antispam.limits", config.MaxPerHour, config.MaxPerDay, config.MinLength, config.MaxLength,
+		formatStatsDuration(lang, config.DuplicateWindow)) + "\n\n")
+	sb.WriteString(Translate(lang, "antispam.counters"))
+	for _, reason := range spamReasons {
+		sb.WriteString("\n" + Translate(lang, "antispam.counter", Translate(lang, "antispam.reason."+reason),
+			periods[0][reason], periods[1][reason], periods[2][reason]))
+	}
+
+	t.sendMessage(chatID, sb.String())
+}
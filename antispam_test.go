@@ -0,0 +1,109 @@
+package main
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func TestTextFingerprintDistance(t *testing.T) {
+	const threshold = 6
+
+	tests := []struct {
+		name          string
+		a, b          string
+		wantDuplicate bool
+	}{
+		{
+			"identical",
+			"В приемном отделении пришлось ждать врача больше трех часов",
+			"В приемном отделении пришлось ждать врача больше трех часов",
+			true,
+		},
+		{
+			"case, punctuation and spaces",
+			"В приемном отделении пришлось ждать врача больше трех часов!",
+			"в  приемном отделении, пришлось ждать врача больше трех часов",
+			true,
+		},
+		{
+			"yo folded to ye",
+			"Врач не пришёл на обход, ждём с утра в палате номер пять",
+			"Врач не пришел на обход, ждем с утра в палате номер пять",
+			true,
+		},
+		{
+			"one word changed",
+			"В приемном отделении пришлось ждать врача больше трех часов",
+			"В приемном отделении пришлось ждать врача больше четырех часов",
+			true,
+		},
+		{
+			"unrelated complaints",
+			"В приемном отделении пришлось ждать врача больше трех часов",
+			"В столовой подают холодную еду, а в палате не работает розетка",
+			false,
+		},
+		{
+			"unrelated in kazakh",
+			"Дәрігер өте мұқият болды, рахмет барлық мейірбикелерге",
+			"Қабылдау бөлімінде кезек өте ұзақ, үш сағат күттік",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distance := bits.OnesCount64(textFingerprint(tt.a) ^ textFingerprint(tt.b))
+			if gotDuplicate := distance <= threshold; gotDuplicate != tt.wantDuplicate {
+				t.Fatalf("distance = %d, duplicate = %v, want %v", distance, gotDuplicate, tt.wantDuplicate)
+			}
+		})
+	}
+}
+
+func TestTextFingerprintEdgeCases(t *testing.T) {
+	if got := textFingerprint(" !?… "); got != 0 {
+		t.Fatalf("fingerprint of text without letters = %x, want 0", got)
+	}
+	if textFingerprint("ok") == 0 {
+		t.Fatal("fingerprint of a two-letter text is 0")
+	}
+	if got, want := string(normalizeForFingerprint("  Ёлка, ЁЖ!  ")), "елка еж"; got != want {
+		t.Fatalf("normalizeForFingerprint = %q, want %q", got, want)
+	}
+}
+
+func TestAntispamCheckLength(t *testing.T) {
+	config := AntispamConfig{MinLength: 10, MaxLength: 20}
+
+	tests := []struct {
+		name           string
+		text           string
+		hasAttachments bool
+		want           string
+	}{
+		{"fits", "достаточно текста", false, ""},
+		{"too short", "коротко", false, SpamTooShort},
+		{"short with attachments", "фото", true, ""},
+		{"spaces are not counted", "   коротко   ", false, SpamTooShort},
+		{"too long", "очень длинный текст обращения", false, SpamTooLong},
+		{"too long with attachments", "очень длинный текст обращения", true, SpamTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rejection := config.checkLength(tt.text, tt.hasAttachments)
+			got := ""
+			if rejection != nil {
+				got = rejection.Reason
+			}
+			if got != tt.want {
+				t.Fatalf("checkLength(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	if rejection := (AntispamConfig{}).checkLength("", false); rejection != nil {
+		t.Fatalf("zero config rejected text: %+v", rejection)
+	}
+}
@@ -17,6 +17,8 @@ type App struct {
 	bot      *TelegramBot
 	database *Database
 	email    *EmailService
+	posters  *PosterRenderer
+	charts   *ChartRenderer
 	server   *http.Server
 }
 
@@ -47,16 +49,46 @@ func (a *App) Run() error {
 	a.database = db
 	a.logger.Info("Database connection established")
 
+	// Инициализируем хранилище вложений
+	attachments, err := NewAttachmentStorageFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize attachments storage: %w", err)
+	}
+
 	// Инициализируем email сервис
-	emailService := NewEmailService()
+	emailService := NewEmailService(attachments)
 	a.email = emailService
 
+	// Инициализируем хранилище состояний диалога
+	states := NewStateStoreFromEnv(a.database)
+
+	// Инициализируем хранилище личности авторов анонимных обращений
+	vault, err := NewIdentityVaultFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize identity vault: %w", err)
+	}
+
+	// Инициализируем генератор графиков статистики
+	charts, err := NewChartRendererFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to initialize chart renderer: %w", err)
+	}
+	a.charts = charts
+
 	// Инициализируем Telegram бота
-	bot, err := NewTelegramBot(a.database, a.email, a.logger)
+	bot, err := NewTelegramBot(a.database, a.email, states, attachments, vault, charts, a.logger)
 	if err != nil {
 		return fmt.Errorf("failed to initialize telegram bot: %w", err)
 	}
 	a.bot = bot
+	go a.purgeExpiredStates(states, attachments)
+
+	// Инициализируем генератор плакатов с QR-кодами
+	posters, err := NewPosterRendererFromEnv(a.bot.Username())
+	if err != nil {
+		return fmt.Errorf("failed to initialize poster renderer: %w", err)
+	}
+	a.posters = posters
 
 	// Запускаем Telegram бота
 	go func() {
@@ -65,10 +97,22 @@ func (a *App) Run() error {
 		}
 	}()
 
+	// Запускаем контроль сроков ответа и эскалацию просроченных обращений
+	go a.runEscalations()
+
+	// Запускаем отправку сводок руководству по расписанию DIGEST_SCHEDULE
+	go a.runDigests()
+
 	// Настраиваем HTTP сервер
 	mux := http.NewServeMux()
 	mux.HandleFunc("/health", a.healthHandler)
 	mux.HandleFunc("/feedback", a.feedbackHandler)
+	mux.HandleFunc("/feedback/status", a.statusHandler)
+	mux.HandleFunc("/feedback/history", a.statusHistoryHandler)
+	mux.HandleFunc("/feedback/export", a.exportHandler)
+	mux.HandleFunc("/qr/poster", a.posterHandler)
+	mux.HandleFunc("/qr/sheet", a.posterSheetHandler)
+	mux.HandleFunc("/stats/chart", a.chartHandler)
 
 	a.server = &http.Server{
 		Addr:         ":" + getEnv("PORT", "8080"),
@@ -121,4 +165,25 @@ func (a *App) feedbackHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"message":"Feedback endpoint"}`))
 }
 
+// purgeExpiredStates периодически удаляет устаревшие состояния диалога
+// вместе с файлами, присланными для так и не отправленных обращений,
+// и языки пользователей, не писавших боту дольше LANGUAGE_CACHE_TTL
+func (a *App) purgeExpiredStates(states StateStore, attachments AttachmentStorage) {
+	ticker := time.NewTicker(getEnvAsDuration("STATE_PURGE_INTERVAL", time.Hour))
+	defer ticker.Stop()
+
+	for range ticker.C {
+		a.bot.forgetIdleLanguages(getEnvAsDuration("LANGUAGE_CACHE_TTL", 24*time.Hour))
+
+		expired, err := states.PurgeExpired()
+		if err != nil {
+			a.logger.Error("Failed to purge expired states: ", err)
+			continue
+		}
+		for _, state := range expired {
+			discardAttachments(a.database, attachments, pendingAttachments(state), a.logger)
+		}
+	}
+}
+
 // getEnv function moved to utils.go
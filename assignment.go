@@ -0,0 +1,284 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// NextAssignee выбирает следующего сотрудника по кругу среди candidates для отделения.
+// Последний назначенный хранится в assignment_rotation, отделение 0 - обращения без отделения.
+func (d *Database) NextAssignee(departmentID int64, candidates []int64) (int64, error) {
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var last int64
+	err = tx.QueryRow(`SELECT last_user_id FROM assignment_rotation WHERE department_id = ? FOR UPDATE`, departmentID).Scan(&last)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to get assignment rotation: %w", err)
+	}
+
+	next := candidates[0]
+	for _, candidate := range candidates {
+		if candidate > last {
+			next = candidate
+			break
+		}
+	}
+
+	query := `
+	INSERT INTO assignment_rotation (department_id, last_user_id)
+	VALUES (?, ?)
+	ON DUPLICATE KEY UPDATE last_user_id = VALUES(last_user_id)
+	`
+	if _, err := tx.Exec(query, departmentID, next); err != nil {
+		return 0, fmt.Errorf("failed to save assignment rotation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit assignment rotation: %w", err)
+	}
+	return next, nil
+}
+
+// assigneeName - имя сотрудника для карточки и списков: из таблицы admins или его ID
+func assigneeName(admin *Admin) string {
+	if admin.Name != "" {
+		return admin.Name
+	}
+	return strconv.FormatInt(admin.UserID, 10)
+}
+
+// staffName - имя сотрудника по ID для фильтров и статистики
+func (t *TelegramBot) staffName(userID int64) string {
+	if admin := t.admin(userID); admin != nil {
+		return assigneeName(admin)
+	}
+	return strconv.FormatInt(userID, 10)
+}
+
+// assignmentCandidates - сотрудники, которые могут вести обращение.
+// Сначала берутся закрепленные за отделением обращения, если таких нет - обработчики без отделения.
+func (t *TelegramBot) assignmentCandidates(feedback *Feedback) ([]*Admin, error) {
+	admins, err := t.database.ListAdmins()
+	if err != nil {
+		return nil, err
+	}
+
+	var department, common []*Admin
+	for _, admin := range admins {
+		if !admin.Can(PermHandleFeedback) || !admin.CanAccess(feedback) || admin.Role == RoleSuperAdmin {
+			continue
+		}
+		switch {
+		case feedback.DepartmentID != 0 && admin.DepartmentID == feedback.DepartmentID:
+			department = append(department, admin)
+		case admin.DepartmentID == 0:
+			common = append(common, admin)
+		}
+	}
+
+	if len(department) > 0 {
+		return department, nil
+	}
+	return common, nil
+}
+
+// nextAssignee выбирает ответственного по кругу; nil - подходящих сотрудников нет
+func (t *TelegramBot) nextAssignee(feedback *Feedback) (*Admin, error) {
+	candidates, err := t.assignmentCandidates(feedback)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for _, admin := range candidates {
+		ids = append(ids, admin.UserID)
+	}
+	next, err := t.database.NextAssignee(feedback.DepartmentID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, admin := range candidates {
+		if admin.UserID == next {
+			return admin, nil
+		}
+	}
+	return nil, nil
+}
+
+// autoAssign назначает новое обращение по кругу среди сотрудников отделения (AUTO_ASSIGN_ENABLED=true)
+func (t *TelegramBot) autoAssign(feedback *Feedback) {
+	if getEnv("AUTO_ASSIGN_ENABLED", "false") != "true" {
+		return
+	}
+
+	admin, err := t.nextAssignee(feedback)
+	if err != nil {
+		t.logger.Error("Failed to choose assignee: ", err)
+		return
+	}
+	if admin != nil {
+		t.assignTo(feedback, admin)
+	}
+}
+
+// assignTo назначает обращение сотруднику и сообщает ему об этом
+func (t *TelegramBot) assignTo(feedback *Feedback, admin *Admin) error {
+	feedback.AssigneeID = admin.UserID
+	feedback.AssigneeName = assigneeName(admin)
+	if err := t.database.AssignFeedback(feedback.ID, feedback.AssigneeID, feedback.AssigneeName); err != nil {
+		t.logger.Error("Failed to assign feedback: ", err)
+		return err
+	}
+
+	t.refreshFeedbackCard(feedback)
+	t.notifyAssignee(feedback)
+	return nil
+}
+
+// notifyAssignee присылает сотруднику карточку назначенного ему обращения в личные сообщения
+func (t *TelegramBot) notifyAssignee(feedback *Feedback) {
+	lang := t.lang(feedback.AssigneeID)
+	text := Translate(lang, "assign.notify", ticketLabel(feedback)) + "\n\n" + feedbackCardText(lang, feedback)
+
+	msg := tgbotapi.NewMessage(feedback.AssigneeID, truncateRunes(text, 4000))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "assign.button.open"), fmt.Sprintf("inbox:v:%d:0:", feedback.ID)),
+		),
+	)
+	if _, err := t.bot.Send(msg); err != nil {
+		t.logger.Error("Failed to notify assignee: ", err)
+	}
+}
+
+// handleAssignCommand обрабатывает /assign <номер обращения> <user_id|me|auto>
+func (t *TelegramBot) handleAssignCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		t.sendMessage(chatID, t.tr(chatID, "assign.usage"))
+		return
+	}
+
+	feedback, err := t.findFeedbackByTicket(args[0])
+	if err != nil {
+		t.logger.Error("Failed to find feedback: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+	if feedback == nil || !t.canAccessFeedback(message.From.ID, PermHandleFeedback, feedback) {
+		t.sendMessage(chatID, t.tr(chatID, "thread.not_found"))
+		return
+	}
+	t.loadFeedbackDetails(feedback)
+
+	var assignee *Admin
+	switch target := strings.ToLower(args[1]); target {
+	case "auto":
+		assignee, err = t.nextAssignee(feedback)
+		if err != nil {
+			t.logger.Error("Failed to choose assignee: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+			return
+		}
+	case "me":
+		assignee = t.admin(message.From.ID)
+		if assignee != nil && assignee.Name == "" {
+			assignee.Name = staffUserName(message.From)
+		}
+	default:
+		userID, err := strconv.ParseInt(target, 10, 64)
+		if err != nil {
+			t.sendMessage(chatID, t.tr(chatID, "assign.usage"))
+			return
+		}
+		assignee = t.admin(userID)
+	}
+
+	if assignee == nil || !assignee.Can(PermHandleFeedback) || !assignee.CanAccess(feedback) {
+		t.sendMessage(chatID, t.tr(chatID, "assign.not_staff"))
+		return
+	}
+
+	if err := t.assignTo(feedback, assignee); err != nil {
+		t.sendMessage(chatID, t.tr(chatID, "error.save"))
+		return
+	}
+	t.sendMessage(chatID, t.tr(chatID, "assign.done", ticketLabel(feedback), feedback.AssigneeName))
+}
+
+// showAssignees предлагает выбрать ответственного из подробного просмотра обращения
+func (t *TelegramBot) showAssignees(chatID int64, from *tgbotapi.User, id int64, messageID int) {
+	feedback, err := t.database.GetFeedback(id)
+	if err != nil || feedback == nil || !t.canAccessFeedback(from.ID, PermHandleFeedback, feedback) {
+		t.sendMessage(chatID, t.tr(chatID, "thread.not_found"))
+		return
+	}
+
+	admins, err := t.database.ListAdmins()
+	if err != nil {
+		t.logger.Error("Failed to list admins: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+
+	lang := t.lang(chatID)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, admin := range admins {
+		if !admin.Can(PermHandleFeedback) || !admin.CanAccess(feedback) {
+			continue
+		}
+		label := assigneeName(admin)
+		if admin.UserID == feedback.AssigneeID {
+			label = "✅ " + label
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("inbox:A:%d:%d", feedback.ID, admin.UserID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "assign.button.back"), fmt.Sprintf("inbox:v:%d:0:", feedback.ID)),
+	))
+
+	t.sendOrEdit(chatID, messageID, Translate(lang, "assign.choose", ticketLabel(feedback)), tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// setAssignee назначает выбранного в списке сотрудника и возвращает к обращению
+func (t *TelegramBot) setAssignee(callback *tgbotapi.CallbackQuery, id, userID int64) {
+	chatID := callback.Message.Chat.ID
+	feedback, err := t.database.GetFeedback(id)
+	if err != nil || feedback == nil || !t.canAccessFeedback(callback.From.ID, PermHandleFeedback, feedback) {
+		t.bot.Request(tgbotapi.NewCallback(callback.ID, t.tr(chatID, "access.denied")))
+		return
+	}
+
+	assignee := t.admin(userID)
+	if assignee == nil || !assignee.Can(PermHandleFeedback) || !assignee.CanAccess(feedback) {
+		t.bot.Request(tgbotapi.NewCallback(callback.ID, t.tr(chatID, "assign.not_staff")))
+		return
+	}
+
+	t.loadFeedbackDetails(feedback)
+	if err := t.assignTo(feedback, assignee); err != nil {
+		t.bot.Request(tgbotapi.NewCallback(callback.ID, t.tr(chatID, "error.save")))
+		return
+	}
+
+	t.bot.Request(tgbotapi.NewCallback(callback.ID, t.tr(chatID, "assign.done", ticketLabel(feedback), feedback.AssigneeName)))
+	t.showFeedbackDetail(chatID, callback.From, id, inboxFilter{}, 0, callback.Message.MessageID)
+}
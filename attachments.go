@@ -0,0 +1,300 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Виды вложений
+const (
+	AttachmentPhoto     = "photo"
+	AttachmentDocument  = "document"
+	AttachmentVoice     = "voice"
+	AttachmentAudio     = "audio"
+	AttachmentVideo     = "video"
+	AttachmentVideoNote = "video_note"
+)
+
+type Attachment struct {
+	ID           int64     `json:"id"`
+	FeedbackID   int64     `json:"feedback_id"`
+	Kind         string    `json:"kind"`
+	FileID       string    `json:"file_id"`
+	FileUniqueID string    `json:"file_unique_id"`
+	FileName     string    `json:"file_name"`
+	MimeType     string    `json:"mime_type"`
+	Size         int64     `json:"size"`
+	StoragePath  string    `json:"storage_path"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AttachmentStorage сохраняет содержимое вложений и отдает его по ключу
+type AttachmentStorage interface {
+	Save(name string, r io.Reader) (key string, size int64, err error)
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// errAttachmentTooLarge - файл больше ATTACHMENT_MAX_SIZE_MB
+var errAttachmentTooLarge = errors.New("attachment is too large")
+
+// LocalAttachmentStorage хранит вложения в каталоге на диске
+type LocalAttachmentStorage struct {
+	baseDir string
+}
+
+func NewLocalAttachmentStorage(baseDir string) (*LocalAttachmentStorage, error) {
+	if err := os.MkdirAll(baseDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create attachments dir: %w", err)
+	}
+	return &LocalAttachmentStorage{baseDir: baseDir}, nil
+}
+
+// Save сохраняет файл под новым уникальным ключом: повторная отправка того же файла
+// не перезаписывает копию, на которую уже ссылается сохраненное обращение
+func (s *LocalAttachmentStorage) Save(name string, r io.Reader) (string, int64, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", 0, fmt.Errorf("failed to generate attachment key: %w", err)
+	}
+	fileName := hex.EncodeToString(suffix) + "_" + sanitizeFileName(name)
+	key := filepath.ToSlash(filepath.Join(time.Now().Format("2006/01"), fileName))
+	path := filepath.Join(s.baseDir, filepath.FromSlash(key))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", 0, fmt.Errorf("failed to create attachment dir: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o640)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create attachment file: %w", err)
+	}
+	defer file.Close()
+
+	size, err := io.Copy(file, r)
+	if err != nil {
+		os.Remove(path)
+		return "", 0, fmt.Errorf("failed to write attachment file: %w", err)
+	}
+
+	return key, size, nil
+}
+
+func (s *LocalAttachmentStorage) Open(key string) (io.ReadCloser, error) {
+	path := filepath.Join(s.baseDir, filepath.FromSlash(filepath.Clean("/"+key)))
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open attachment: %w", err)
+	}
+	return file, nil
+}
+
+func (s *LocalAttachmentStorage) Delete(key string) error {
+	path := filepath.Join(s.baseDir, filepath.FromSlash(filepath.Clean("/"+key)))
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	return nil
+}
+
+// NewAttachmentStorageFromEnv выбирает хранилище вложений по ATTACHMENTS_STORAGE
+func NewAttachmentStorageFromEnv() (AttachmentStorage, error) {
+	switch storage := getEnv("ATTACHMENTS_STORAGE", "local"); storage {
+	case "local":
+		return NewLocalAttachmentStorage(getEnv("ATTACHMENTS_DIR", "attachments"))
+	default:
+		return nil, fmt.Errorf("unknown attachments storage: %s", storage)
+	}
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+func sanitizeFileName(name string) string {
+	name = unsafeFileNameChars.ReplaceAllString(filepath.Base(name), "_")
+	name = strings.Trim(name, "._")
+	if name == "" {
+		name = "file"
+	}
+	return name
+}
+
+// extractAttachments собирает вложения из сообщения Telegram
+func extractAttachments(message *tgbotapi.Message) []*Attachment {
+	var attachments []*Attachment
+
+	if len(message.Photo) > 0 {
+		// Последний размер фотографии - самый крупный
+		photo := message.Photo[len(message.Photo)-1]
+		attachments = append(attachments, &Attachment{
+			Kind:         AttachmentPhoto,
+			FileID:       photo.FileID,
+			FileUniqueID: photo.FileUniqueID,
+			FileName:     photo.FileUniqueID + ".jpg",
+			MimeType:     "image/jpeg",
+			Size:         int64(photo.FileSize),
+		})
+	}
+	if doc := message.Document; doc != nil {
+		attachments = append(attachments, &Attachment{
+			Kind:         AttachmentDocument,
+			FileID:       doc.FileID,
+			FileUniqueID: doc.FileUniqueID,
+			FileName:     doc.FileName,
+			MimeType:     doc.MimeType,
+			Size:         int64(doc.FileSize),
+		})
+	}
+	if voice := message.Voice; voice != nil {
+		attachments = append(attachments, &Attachment{
+			Kind:         AttachmentVoice,
+			FileID:       voice.FileID,
+			FileUniqueID: voice.FileUniqueID,
+			FileName:     voice.FileUniqueID + ".ogg",
+			MimeType:     voice.MimeType,
+			Size:         int64(voice.FileSize),
+		})
+	}
+	if audio := message.Audio; audio != nil {
+		attachments = append(attachments, &Attachment{
+			Kind:         AttachmentAudio,
+			FileID:       audio.FileID,
+			FileUniqueID: audio.FileUniqueID,
+			FileName:     audio.FileName,
+			MimeType:     audio.MimeType,
+			Size:         int64(audio.FileSize),
+		})
+	}
+	if video := message.Video; video != nil {
+		attachments = append(attachments, &Attachment{
+			Kind:         AttachmentVideo,
+			FileID:       video.FileID,
+			FileUniqueID: video.FileUniqueID,
+			FileName:     video.FileName,
+			MimeType:     video.MimeType,
+			Size:         int64(video.FileSize),
+		})
+	}
+	if note := message.VideoNote; note != nil {
+		attachments = append(attachments, &Attachment{
+			Kind:         AttachmentVideoNote,
+			FileID:       note.FileID,
+			FileUniqueID: note.FileUniqueID,
+			FileName:     note.FileUniqueID + ".mp4",
+			MimeType:     "video/mp4",
+			Size:         int64(note.FileSize),
+		})
+	}
+
+	for _, attachment := range attachments {
+		if attachment.FileName == "" {
+			attachment.FileName = attachment.FileUniqueID
+		}
+	}
+
+	return attachments
+}
+
+// attachmentMaxSizeMB - максимальный размер вложения в мегабайтах
+func attachmentMaxSizeMB() int {
+	return getEnvAsInt("ATTACHMENT_MAX_SIZE_MB", 20)
+}
+
+// sizeLimitedReader возвращает errAttachmentTooLarge, если данных больше limit,
+// вместо того чтобы молча обрезать файл
+type sizeLimitedReader struct {
+	r     io.Reader
+	limit int64
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.limit -= int64(n)
+	if l.limit < 0 {
+		return n, errAttachmentTooLarge
+	}
+	return n, err
+}
+
+// downloadAttachment скачивает файл через Bot API и кладет его в хранилище вложений.
+// Размер проверяется и по FileSize от Telegram, и по фактически скачанным байтам.
+func (t *TelegramBot) downloadAttachment(attachment *Attachment) error {
+	maxSize := int64(attachmentMaxSizeMB()) << 20
+	if attachment.Size > maxSize {
+		return fmt.Errorf("%w: %d bytes", errAttachmentTooLarge, attachment.Size)
+	}
+
+	url, err := t.bot.GetFileDirectURL(attachment.FileID)
+	if err != nil {
+		return fmt.Errorf("failed to get file url: %w", err)
+	}
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	name := attachment.FileUniqueID + "_" + attachment.FileName
+	key, size, err := t.attachments.Save(name, &sizeLimitedReader{r: resp.Body, limit: maxSize})
+	if err != nil {
+		return err
+	}
+
+	attachment.StoragePath = key
+	attachment.Size = size
+	return nil
+}
+
+// discardPendingAttachments удаляет из хранилища файлы, присланные для неотправленного обращения
+func (t *TelegramBot) discardPendingAttachments(state *UserState) {
+	discardAttachments(t.database, t.attachments, pendingAttachments(state), t.logger)
+	delete(state.Data, "attachments")
+}
+
+// discardAttachments удаляет файлы черновика. Файл, на который ссылается сохраненное обращение,
+// не удаляется ни при каких условиях; при ошибке проверки файл тоже остается.
+func discardAttachments(database *Database, storage AttachmentStorage, attachments []*Attachment, logger *logrus.Logger) {
+	for _, attachment := range attachments {
+		if attachment.StoragePath == "" {
+			continue
+		}
+		inUse, err := database.AttachmentPathInUse(attachment.StoragePath)
+		if err != nil {
+			logger.Error("Failed to check attachment usage: ", err)
+			continue
+		}
+		if inUse {
+			continue
+		}
+		if err := storage.Delete(attachment.StoragePath); err != nil {
+			logger.Error("Failed to delete pending attachment: ", err)
+		}
+	}
+}
+
+// AttachmentPathInUse проверяет, ссылается ли на файл хотя бы одно сохраненное обращение
+func (d *Database) AttachmentPathInUse(storagePath string) (bool, error) {
+	var exists bool
+	err := d.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM feedback_attachments WHERE storage_path = ?)`, storagePath).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check attachment usage: %w", err)
+	}
+	return exists, nil
+}
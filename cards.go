@@ -0,0 +1,326 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// cardMessageLimit - сколько символов текста обращения показывать в карточке
+const cardMessageLimit = 2500
+
+// pendingCardsLimit - сколько карточек без публикации выкладывать за один запуск
+const pendingCardsLimit = 50
+
+func (d *Database) AssignFeedback(id, assigneeID int64, assigneeName string) error {
+	query := `UPDATE feedback SET assignee_id = ?, assignee_name = ? WHERE id = ?`
+	if _, err := d.db.Exec(query, assigneeID, nullString(assigneeName), id); err != nil {
+		return fmt.Errorf("failed to assign feedback: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) SaveFeedbackCard(id, chatID int64, messageID int) error {
+	query := `UPDATE feedback SET card_chat_id = ?, card_message_id = ? WHERE id = ?`
+	if _, err := d.db.Exec(query, chatID, messageID, id); err != nil {
+		return fmt.Errorf("failed to save feedback card: %w", err)
+	}
+	return nil
+}
+
+// GetFeedbackByCard ищет обращение по его карточке в чате сотрудников
+func (d *Database) GetFeedbackByCard(chatID int64, messageID int) (*Feedback, error) {
+	feedback, err := scanFeedback(d.db.QueryRow(feedbackSelect+"WHERE f.card_chat_id = ? AND f.card_message_id = ?", chatID, messageID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback by card: %w", err)
+	}
+	return feedback, nil
+}
+
+// staffUserName - имя сотрудника для карточки: имя и фамилия или @username
+func staffUserName(user *tgbotapi.User) string {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if name == "" && user.UserName != "" {
+		name = "@" + user.UserName
+	}
+	if name == "" {
+		name = strconv.FormatInt(user.ID, 10)
+	}
+	return name
+}
+
+// postFeedbackCard публикует карточку нового обращения в чате сотрудников
+func (t *TelegramBot) postFeedbackCard(feedback *Feedback) {
+	chatID := staffChatID()
+	if chatID == 0 {
+		return
+	}
+
+	lang := staffLanguage()
+	msg := tgbotapi.NewMessage(chatID, feedbackCardText(lang, feedback))
+	msg.ReplyMarkup = feedbackCardKeyboard(lang, feedback)
+	sent, err := t.bot.Send(msg)
+	if err != nil {
+		t.logger.Error("Failed to post feedback card: ", err)
+		return
+	}
+
+	feedback.CardChatID = chatID
+	feedback.CardMessageID = sent.MessageID
+	if err := t.database.SaveFeedbackCard(feedback.ID, chatID, sent.MessageID); err != nil {
+		t.logger.Error("Failed to save feedback card: ", err)
+	}
+}
+
+// postPendingCards публикует карточки новых обращений, для которых карточки еще нет:
+// например, если чат сотрудников был недоступен в момент отправки
+func (t *TelegramBot) postPendingCards() {
+	if staffChatID() == 0 {
+		return
+	}
+
+	filter := FeedbackFilter{Statuses: []string{"new"}, WithoutCard: true}
+	feedbacks, _, err := t.database.ListFeedbacks(filter, pendingCardsLimit, 0)
+	if err != nil {
+		t.logger.Error("Failed to get new feedbacks: ", err)
+		return
+	}
+
+	for _, feedback := range feedbacks {
+		t.loadFeedbackDetails(feedback)
+		t.postFeedbackCard(feedback)
+	}
+}
+
+// loadFeedbackDetails подгружает вложения и ответы анкеты для карточки
+func (t *TelegramBot) loadFeedbackDetails(feedback *Feedback) {
+	attachments, err := t.database.GetFeedbackAttachments(feedback.ID)
+	if err != nil {
+		t.logger.Error("Failed to get feedback attachments: ", err)
+	}
+	feedback.Attachments = attachments
+
+	answers, err := t.database.GetFeedbackAnswers(feedback.ID)
+	if err != nil {
+		t.logger.Error("Failed to get feedback answers: ", err)
+	}
+	feedback.Answers = answers
+}
+
+// refreshFeedbackCard перерисовывает карточку, чтобы все в чате видели текущий статус
+func (t *TelegramBot) refreshFeedbackCard(feedback *Feedback) {
+	if err := editFeedbackCard(t.bot, feedback); err != nil {
+		t.logger.Error("Failed to update feedback card: ", err)
+	}
+}
+
+// editFeedbackCard перерисовывает опубликованную карточку обращения; без карточки ничего не делает
+func editFeedbackCard(bot *tgbotapi.BotAPI, feedback *Feedback) error {
+	if feedback.CardMessageID == 0 {
+		return nil
+	}
+
+	lang := staffLanguage()
+	edit := tgbotapi.NewEditMessageTextAndMarkup(feedback.CardChatID, feedback.CardMessageID,
+		feedbackCardText(lang, feedback), feedbackCardKeyboard(lang, feedback))
+	if _, err := bot.Send(edit); err != nil {
+		return fmt.Errorf("failed to edit feedback card: %w", err)
+	}
+	return nil
+}
+
+// refreshFeedbackCardCLI перерисовывает карточку из командной строки, где бот не запущен:
+// для этого создается отдельный клиент Bot API с тем же TELEGRAM_BOT_TOKEN
+func refreshFeedbackCardCLI(db *Database, feedback *Feedback) error {
+	if feedback.CardMessageID == 0 {
+		return nil
+	}
+	token := getEnv("TELEGRAM_BOT_TOKEN", "")
+	if token == "" {
+		return fmt.Errorf("TELEGRAM_BOT_TOKEN is not set")
+	}
+	bot, err := tgbotapi.NewBotAPI(token)
+	if err != nil {
+		return fmt.Errorf("failed to create bot API: %w", err)
+	}
+
+	if feedback.Attachments, err = db.GetFeedbackAttachments(feedback.ID); err != nil {
+		return err
+	}
+	if feedback.Answers, err = db.GetFeedbackAnswers(feedback.ID); err != nil {
+		return err
+	}
+	return editFeedbackCard(bot, feedback)
+}
+
+// handleCardAction обрабатывает кнопки карточки card:<действие>:<id обращения>
+func (t *TelegramBot) handleCardAction(callback *tgbotapi.CallbackQuery, payload string) {
+	lang := staffLanguage()
+	action, rawID, _ := strings.Cut(payload, ":")
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return
+	}
+
+	feedback, err := t.database.GetFeedback(id)
+	if err != nil || feedback == nil {
+		t.logger.Error("Failed to get feedback for card action: ", err)
+		t.bot.Request(tgbotapi.NewCallback(callback.ID, Translate(lang, "error.generic")))
+		return
+	}
+
+	// Обрабатывать обращение может сотрудник с нужной ролью и доступом к отделению
+	if !t.canAccessFeedback(callback.From.ID, PermHandleFeedback, feedback) {
+		t.bot.Request(tgbotapi.NewCallback(callback.ID, Translate(lang, "access.denied")))
+		return
+	}
+
+	var status, notice string
+	switch action {
+	case "take":
+		status, notice = StatusInProgress, "card.taken"
+	case "done":
+		status, notice = StatusResolved, "card.processed"
+	default:
+		return
+	}
+
+	// Карточка могла быть опубликована заново, поэтому редактируем именно нажатое сообщение
+	feedback.CardChatID = callback.Message.Chat.ID
+	feedback.CardMessageID = callback.Message.MessageID
+	if err := t.changeFeedbackStatus(feedback, status, "", callback.From, action == "take"); err != nil {
+		if errors.Is(err, ErrInvalidTransition) {
+			t.refreshFeedbackCard(feedback)
+			t.bot.Request(tgbotapi.NewCallback(callback.ID, Translate(lang, "status.transition_denied")))
+			return
+		}
+		t.logger.Error("Failed to change feedback status: ", err)
+		t.bot.Request(tgbotapi.NewCallback(callback.ID, Translate(lang, "error.generic")))
+		return
+	}
+	t.bot.Request(tgbotapi.NewCallback(callback.ID, Translate(lang, notice)))
+}
+
+// changeFeedbackStatus меняет статус обращения от имени сотрудника и обновляет его карточку.
+// Ответственным становится сотрудник, если он берет обращение (take) или его еще никто не взял.
+func (t *TelegramBot) changeFeedbackStatus(feedback *Feedback, status, comment string, staff *tgbotapi.User, take bool) error {
+	change := &StatusChange{
+		FeedbackID: feedback.ID,
+		ToStatus:   status,
+		ActorID:    staff.ID,
+		ActorName:  staffUserName(staff),
+		Source:     StatusSourceBot,
+		Comment:    comment,
+	}
+	if err := t.database.ChangeFeedbackStatus(change); err != nil {
+		return err
+	}
+	feedback.Status = status
+
+	if take || feedback.AssigneeID == 0 {
+		feedback.AssigneeID = staff.ID
+		feedback.AssigneeName = staffUserName(staff)
+		if err := t.database.AssignFeedback(feedback.ID, feedback.AssigneeID, feedback.AssigneeName); err != nil {
+			t.logger.Error("Failed to assign feedback: ", err)
+		}
+	}
+
+	t.loadFeedbackDetails(feedback)
+	t.refreshFeedbackCard(feedback)
+	return nil
+}
+
+// feedbackCardText - текст карточки обращения для сотрудников. Карточка видна всему чату
+// и пересылается в уведомлениях, поэтому идентификаторы в тексте пациента скрыты.
+func feedbackCardText(lang string, feedback *Feedback) string {
+	return feedbackText(lang, redactFeedback(lang, feedback), cardMessageLimit, true)
+}
+
+// feedbackText описывает обращение, обрезая его текст до messageLimit символов
+// showPersonal - показывать ли имя, username и телефон автора полностью (PermViewPersonalData);
+// без права они маскируются так же, как в выгрузке
+func feedbackText(lang string, feedback *Feedback, messageLimit int, showPersonal bool) string {
+	if !showPersonal {
+		masked := *feedback
+		maskPersonalData(&masked)
+		feedback = &masked
+	}
+
+	var sb strings.Builder
+	sb.WriteString(Translate(lang, "card.title", ticketLabel(feedback)) + "\n\n")
+	sb.WriteString(Translate(lang, "card.type", feedbackTypeLabel(lang, feedback.Type)) + "\n")
+	if feedback.Rating != 0 {
+		sb.WriteString(Translate(lang, "card.rating", ratingStars(feedback.Rating)) + "\n")
+	}
+	if feedback.DepartmentName != "" {
+		sb.WriteString(Translate(lang, "card.department", feedback.DepartmentName) + "\n")
+	}
+	if feedback.Location != "" {
+		sb.WriteString(Translate(lang, "card.location", locationDisplayName(lang, feedback.Location)) + "\n")
+	}
+
+	if feedback.Anonymous {
+		sb.WriteString(Translate(lang, "card.sender_anonymous") + "\n")
+	} else {
+		sender := strings.TrimSpace(feedback.FirstName + " " + feedback.LastName)
+		if feedback.Username != "" {
+			sender = strings.TrimSpace(sender + " @" + feedback.Username)
+		}
+		sb.WriteString(Translate(lang, "card.sender", sender) + "\n")
+		if feedback.Phone != "" {
+			sb.WriteString(Translate(lang, "card.phone", feedback.Phone) + "\n")
+		}
+	}
+	sb.WriteString(Translate(lang, "card.date", inTimezone(feedback.CreatedAt).Format("02.01.2006 15:04")) + "\n")
+
+	for _, answer := range feedback.Answers {
+		sb.WriteString(fmt.Sprintf("• %s: %s\n", answer.Question, answer.Label))
+	}
+	if len(feedback.Attachments) > 0 {
+		sb.WriteString(Translate(lang, "card.files", len(feedback.Attachments)) + "\n")
+	}
+
+	sb.WriteString("\n💬 " + truncateRunes(feedback.Message, messageLimit) + "\n\n")
+	sb.WriteString(Translate(lang, "card.status", statusDisplayName(lang, feedback.Status)))
+	if feedback.AssigneeName != "" {
+		sb.WriteString("\n" + Translate(lang, "card.assignee", feedback.AssigneeName))
+	}
+	if !feedback.DueAt.IsZero() && isSLAWatched(feedback.Status) {
+		key := "card.due"
+		if time.Now().After(feedback.DueAt) {
+			key = "card.overdue"
+		}
+		sb.WriteString("\n" + Translate(lang, key, inTimezone(feedback.DueAt).Format("02.01.2006 15:04")))
+	}
+	return sb.String()
+}
+
+// feedbackCardKeyboard - кнопки карточки; набор зависит от статуса обращения
+func feedbackCardKeyboard(lang string, feedback *Feedback) tgbotapi.InlineKeyboardMarkup {
+	id := strconv.FormatInt(feedback.ID, 10)
+
+	var actions []tgbotapi.InlineKeyboardButton
+	if canTransition(feedback.Status, StatusInProgress) {
+		actions = append(actions, tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "button.take"), "card:take:"+id))
+	}
+	if canTransition(feedback.Status, StatusResolved) {
+		actions = append(actions, tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "button.mark_processed"), "card:done:"+id))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if len(actions) > 0 {
+		rows = append(rows, actions)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "thread.button.reply"), "staffreply:"+id),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,492 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Виды графиков статистики
+const (
+	ChartTrend      = "trend"
+	ChartStatus     = "status"
+	ChartDepartment = "department"
+)
+
+var chartKinds = []string{ChartTrend, ChartStatus, ChartDepartment}
+
+const chartWidth = 1000
+
+var (
+	chartBackground = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	chartText       = color.RGBA{R: 51, G: 51, B: 51, A: 255}
+	chartMuted      = color.RGBA{R: 140, G: 140, B: 140, A: 255}
+	chartGrid       = color.RGBA{R: 229, G: 229, B: 229, A: 255}
+	chartComplaint  = color.RGBA{R: 217, G: 83, B: 79, A: 255}
+	chartReview     = color.RGBA{R: 92, G: 184, B: 92, A: 255}
+)
+
+// chartStatusColors - цвета столбцов статусов
+var chartStatusColors = map[string]color.RGBA{
+	StatusNew:             {R: 30, G: 111, B: 184, A: 255},
+	StatusAcknowledged:    {R: 91, G: 192, B: 222, A: 255},
+	StatusInProgress:      {R: 240, G: 173, B: 78, A: 255},
+	StatusAwaitingPatient: {R: 155, G: 89, B: 182, A: 255},
+	StatusResolved:        {R: 92, G: 184, B: 92, A: 255},
+	StatusRejected:        {R: 149, G: 165, B: 166, A: 255},
+	StatusReopened:        {R: 217, G: 83, B: 79, A: 255},
+}
+
+// ChartRenderer рисует графики статистики в PNG без внешних сервисов
+type ChartRenderer struct {
+	font *sfnt.Font
+}
+
+func NewChartRendererFromEnv() (*ChartRenderer, error) {
+	f, err := loadFontFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return &ChartRenderer{font: f}, nil
+}
+
+// chartCanvas - холст графика с подготовленными начертаниями шрифта
+type chartCanvas struct {
+	img    *image.RGBA
+	font   *sfnt.Font
+	title  font.Face
+	label  font.Face
+	small  font.Face
+	closer []font.Face
+}
+
+func (r *ChartRenderer) newCanvas(width, height int) (*chartCanvas, error) {
+	c := &chartCanvas{img: image.NewRGBA(image.Rect(0, 0, width, height)), font: r.font}
+	draw.Draw(c.img, c.img.Bounds(), image.NewUniform(chartBackground), image.Point{}, draw.Src)
+
+	for _, face := range []struct {
+		dst  *font.Face
+		size float64
+	}{{&c.title, 26}, {&c.label, 17}, {&c.small, 14}} {
+		f, err := opentype.NewFace(r.font, &opentype.FaceOptions{Size: face.size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			c.close()
+			return nil, fmt.Errorf("failed to create font face: %w", err)
+		}
+		*face.dst = f
+		c.closer = append(c.closer, f)
+	}
+	return c, nil
+}
+
+func (c *chartCanvas) close() {
+	for _, face := range c.closer {
+		face.Close()
+	}
+}
+
+// clean заменяет недостающие казахские буквы и убирает символы, которых нет в шрифте (эмодзи)
+func (c *chartCanvas) clean(text string) string {
+	var buf sfnt.Buffer
+	text = strings.Map(func(ch rune) rune {
+		if index, err := c.font.GlyphIndex(&buf, ch); err != nil || index == 0 {
+			return -1
+		}
+		return ch
+	}, fallbackGlyphs(c.font, text))
+	return strings.TrimSpace(text)
+}
+
+func (c *chartCanvas) rect(x0, y0, x1, y1 int, col color.Color) {
+	draw.Draw(c.img, image.Rect(x0, y0, x1, y1), image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+// line рисует отрезок толщиной width алгоритмом Брезенхэма
+func (c *chartCanvas) line(x0, y0, x1, y1, width int, col color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	half := width / 2
+	for e := dx + dy; ; {
+		c.rect(x0-half, y0-half, x0-half+width, y0-half+width, col)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e2 := 2 * e; e2 >= dy {
+			e += dy
+			x0 += sx
+		} else if e2 <= dx {
+			e += dx
+			y0 += sy
+		} else {
+			e += dy + dx
+			x0 += sx
+			y0 += sy
+		}
+	}
+}
+
+// text выводит строку; align: -1 - по левому краю от x, 0 - по центру, 1 - по правому краю
+func (c *chartCanvas) text(face font.Face, s string, x, baseline, align int, col color.Color) {
+	drawer := &font.Drawer{Dst: c.img, Src: image.NewUniform(col), Face: face}
+	s = c.clean(s)
+	width := drawer.MeasureString(s).Ceil()
+	switch align {
+	case 0:
+		x -= width / 2
+	case 1:
+		x -= width
+	}
+	drawer.Dot = fixed.P(x, baseline)
+	drawer.DrawString(s)
+}
+
+// fit обрезает подпись с многоточием, чтобы она уместилась в maxWidth пикселей
+func (c *chartCanvas) fit(face font.Face, s string, maxWidth int) string {
+	drawer := &font.Drawer{Face: face}
+	s = c.clean(s)
+	if drawer.MeasureString(s).Ceil() <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && drawer.MeasureString(string(runes)+"…").Ceil() > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// legend рисует подписи серий в строку, начиная с x
+func (c *chartCanvas) legend(x, baseline int, labels []string, colors []color.RGBA) {
+	drawer := &font.Drawer{Face: c.label}
+	for i, label := range labels {
+		label = c.clean(label)
+		c.rect(x, baseline-13, x+16, baseline+3, colors[i])
+		c.text(c.label, label, x+24, baseline, -1, chartText)
+		x += 24 + drawer.MeasureString(label).Ceil() + 30
+	}
+}
+
+func (c *chartCanvas) encode(w io.Writer) error {
+	return png.Encode(w, c.img)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// niceMax округляет максимум шкалы вверх до 1, 2 или 5 × 10^n
+func niceMax(value int) int {
+	if value < 1 {
+		return 1
+	}
+	for step := 1; ; step *= 10 {
+		for _, m := range []int{1, 2, 5} {
+			if value <= m*step {
+				return m * step
+			}
+		}
+	}
+}
+
+// chartSubtitle - период и отделение под заголовком графика
+func chartSubtitle(stats *PeriodStats, scope string) string {
+	subtitle := inTimezone(stats.From).Format("02.01.2006") + " — " + inTimezone(stats.To.Add(-time.Second)).Format("02.01.2006")
+	if scope != "" {
+		subtitle += " · " + scope
+	}
+	return subtitle
+}
+
+// Render рисует график kind по статистике периода; scope - название отделения для подзаголовка
+func (r *ChartRenderer) Render(w io.Writer, kind, lang string, stats *PeriodStats, scope string) error {
+	switch kind {
+	case ChartTrend:
+		return r.renderTrend(w, lang, stats, scope)
+	case ChartStatus:
+		return r.renderStatus(w, lang, stats, scope)
+	case ChartDepartment:
+		return r.renderDepartments(w, lang, stats, scope)
+	}
+	return fmt.Errorf("unknown chart %q", kind)
+}
+
+// renderTrend - линии жалоб и отзывов по дням периода
+func (r *ChartRenderer) renderTrend(w io.Writer, lang string, stats *PeriodStats, scope string) error {
+	const height = 560
+	const left, right, top, bottom = 70, 30, 120, 70
+
+	c, err := r.newCanvas(chartWidth, height)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	c.text(c.title, Translate(lang, "chart.trend"), 30, 42, -1, chartText)
+	c.text(c.small, chartSubtitle(stats, scope), 30, 68, -1, chartMuted)
+	c.legend(30, 100, []string{getTypeDisplayName(lang, "complaint"), getTypeDisplayName(lang, "review")},
+		[]color.RGBA{chartComplaint, chartReview})
+
+	// Заполняем дни без обращений нулями
+	byDay := make(map[string]*DailyStats)
+	for _, day := range stats.ByDay {
+		byDay[day.Day] = day
+	}
+	var days []time.Time
+	var complaints, reviews []int
+	peak := 0
+	for day := inTimezone(stats.From); day.Before(stats.To); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+		counts := byDay[day.Format("2006-01-02")]
+		if counts == nil {
+			counts = &DailyStats{}
+		}
+		complaints = append(complaints, counts.Complaints)
+		reviews = append(reviews, counts.Reviews)
+		if counts.Complaints > peak {
+			peak = counts.Complaints
+		}
+		if counts.Reviews > peak {
+			peak = counts.Reviews
+		}
+	}
+
+	plotW, plotH := chartWidth-left-right, height-top-bottom
+	scale := niceMax(peak)
+	for i := 0; i <= 5; i++ {
+		y := top + plotH - plotH*i/5
+		c.rect(left, y, left+plotW, y+1, chartGrid)
+		c.text(c.small, fmt.Sprint(scale*i/5), left-10, y+5, 1, chartMuted)
+	}
+
+	x := func(i int) int {
+		if len(days) < 2 {
+			return left + plotW/2
+		}
+		return left + plotW*i/(len(days)-1)
+	}
+	y := func(value int) int {
+		return top + plotH - plotH*value/scale
+	}
+
+	// Подписываем не больше 10 дат; последнюю - если она не налезает на предыдущую подпись
+	labelEvery := (len(days) + 9) / 10
+	last := len(days) - 1
+	for i, day := range days {
+		if i%labelEvery == 0 && (i == last || last-i >= (labelEvery+1)/2) || i == last && last%labelEvery >= (labelEvery+1)/2 {
+			c.text(c.small, day.Format("02.01"), x(i), top+plotH+26, 0, chartMuted)
+		}
+	}
+
+	for _, series := range []struct {
+		values []int
+		color  color.RGBA
+	}{{complaints, chartComplaint}, {reviews, chartReview}} {
+		for i, value := range series.values {
+			if i > 0 {
+				c.line(x(i-1), y(series.values[i-1]), x(i), y(value), 3, series.color)
+			}
+			c.rect(x(i)-4, y(value)-4, x(i)+5, y(value)+5, series.color)
+		}
+	}
+
+	if peak == 0 {
+		c.text(c.label, Translate(lang, "chart.no_data"), left+plotW/2, top+plotH/2, 0, chartMuted)
+	}
+	return c.encode(w)
+}
+
+// chartBar - строка горизонтальной диаграммы; сегменты складываются в один столбец
+type chartBar struct {
+	Label    string
+	Segments []int
+	Colors   []color.RGBA
+}
+
+func (b chartBar) total() int {
+	total := 0
+	for _, value := range b.Segments {
+		total += value
+	}
+	return total
+}
+
+// renderBars рисует горизонтальную диаграмму с подписями слева и значениями справа от столбцов
+func (r *ChartRenderer) renderBars(w io.Writer, lang, title, subtitle string, bars []chartBar, legend []string, legendColors []color.RGBA) error {
+	const rowHeight, labelWidth, left, right = 44, 280, 30, 80
+	top := 100
+	if len(legend) > 0 {
+		top = 130
+	}
+	rows := len(bars)
+	if rows == 0 {
+		rows = 1
+	}
+	height := top + rows*rowHeight + 30
+
+	c, err := r.newCanvas(chartWidth, height)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	c.text(c.title, title, 30, 42, -1, chartText)
+	c.text(c.small, subtitle, 30, 68, -1, chartMuted)
+	if len(legend) > 0 {
+		c.legend(30, 100, legend, legendColors)
+	}
+
+	if len(bars) == 0 {
+		c.text(c.label, Translate(lang, "chart.no_data"), chartWidth/2, top+rowHeight/2, 0, chartMuted)
+		return c.encode(w)
+	}
+
+	peak := 0
+	for _, bar := range bars {
+		if total := bar.total(); total > peak {
+			peak = total
+		}
+	}
+	barLeft := left + labelWidth
+	barWidth := chartWidth - barLeft - right
+
+	for i, bar := range bars {
+		rowTop := top + i*rowHeight
+		c.text(c.label, c.fit(c.label, bar.Label, labelWidth-15), barLeft-15, rowTop+rowHeight/2+6, 1, chartText)
+
+		x := barLeft
+		for j, value := range bar.Segments {
+			width := barWidth * value / niceMax(peak)
+			c.rect(x, rowTop+8, x+width, rowTop+rowHeight-8, bar.Colors[j])
+			x += width
+		}
+		c.text(c.label, fmt.Sprint(bar.total()), x+10, rowTop+rowHeight/2+6, -1, chartText)
+	}
+	return c.encode(w)
+}
+
+// renderStatus - число обращений периода по статусам
+func (r *ChartRenderer) renderStatus(w io.Writer, lang string, stats *PeriodStats, scope string) error {
+	var bars []chartBar
+	for _, status := range append(append([]string{}, openStatuses...), StatusResolved, StatusRejected) {
+		if count := stats.ByStatus[status]; count > 0 {
+			bars = append(bars, chartBar{
+				Label:    statusDisplayName(lang, status),
+				Segments: []int{count},
+				Colors:   []color.RGBA{chartStatusColors[status]},
+			})
+		}
+	}
+	return r.renderBars(w, lang, Translate(lang, "chart.status"), chartSubtitle(stats, scope), bars, nil, nil)
+}
+
+// renderDepartments - жалобы и отзывы периода по отделениям
+func (r *ChartRenderer) renderDepartments(w io.Writer, lang string, stats *PeriodStats, scope string) error {
+	colors := []color.RGBA{chartComplaint, chartReview}
+	var bars []chartBar
+	for _, department := range stats.ByDepartment {
+		name := department.Name
+		if name == "" {
+			name = Translate(lang, "stats.not_specified")
+		}
+		bars = append(bars, chartBar{
+			Label:    name,
+			Segments: []int{department.Complaints, department.Reviews},
+			Colors:   colors,
+		})
+	}
+	return r.renderBars(w, lang, Translate(lang, "chart.department"), chartSubtitle(stats, scope), bars,
+		[]string{getTypeDisplayName(lang, "complaint"), getTypeDisplayName(lang, "review")}, colors)
+}
+
+// chartHandler отдает PNG-график для дашборда (Authorization: Bearer API_TOKEN):
+// /stats/chart?type=trend|status|department&period=today|7d|30d или &from=2026-01-01&to=2026-01-31,
+// необязательно &dept=<код отделения>&lang=kk|ru|en
+func (a *App) chartHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !a.authorizeAPI(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	kind := query.Get("type")
+	if kind == "" {
+		kind = ChartTrend
+	}
+
+	period, ok := newStatsPeriod(statsWeek)
+	if name := query.Get("period"); name != "" {
+		period, ok = newStatsPeriod(name)
+	}
+	if from := query.Get("from"); from != "" {
+		to := query.Get("to")
+		if to == "" {
+			to = from
+		}
+		period, ok = customStatsPeriod(from, to)
+	}
+	if !ok {
+		http.Error(w, "invalid period", http.StatusBadRequest)
+		return
+	}
+
+	var departmentID int64
+	var scope string
+	if code := query.Get("dept"); code != "" {
+		department, err := a.database.GetDepartmentByCode(code)
+		if err != nil {
+			a.logger.Error("Failed to get department: ", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		if department == nil {
+			http.Error(w, "department not found", http.StatusNotFound)
+			return
+		}
+		departmentID, scope = department.ID, department.Name
+	}
+
+	lang := query.Get("lang")
+	if lang = normalizeLanguage(lang); lang == "" {
+		lang = staffLanguage()
+	}
+
+	stats, err := a.database.GetPeriodStats(period.From, period.To, departmentID, 0)
+	if err != nil {
+		a.logger.Error("Failed to get period stats: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := a.charts.Render(&buf, kind, lang, stats, scope); err != nil {
+		http.Error(w, "unknown chart type", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const cliUsage = `Usage:
+  hospital-feedback-bot status <ticket|#id> <status> [comment]
+  hospital-feedback-bot history <ticket|#id>
+  hospital-feedback-bot digest [daily|weekly] [email...]
+  hospital-feedback-bot export [csv|xlsx] [complaint|review] [open|status] [from] [to] [department code] [-assignee id] [-o file]
+
+Statuses: new, acknowledged, in_progress, awaiting_patient, resolved, rejected, reopened`
+
+// runCLI выполняет служебную команду вместо запуска бота, например
+// "hospital-feedback-bot status K7QM-3XPA resolved Проведена беседа с персоналом"
+func runCLI(args []string) error {
+	if len(args) > 0 && args[0] == "digest" {
+		return runDigestCLI(args[1:])
+	}
+	if len(args) > 0 && args[0] == "export" {
+		return runExportCLI(args[1:])
+	}
+	if len(args) < 2 {
+		return errors.New(cliUsage)
+	}
+
+	db, err := NewDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	feedback, err := db.FindFeedback(args[1])
+	if err != nil {
+		return err
+	}
+	if feedback == nil {
+		return fmt.Errorf("feedback %s not found", args[1])
+	}
+
+	switch args[0] {
+	case "status":
+		if len(args) < 3 || !isSupportedStatus(args[2]) {
+			return errors.New(cliUsage)
+		}
+
+		change := &StatusChange{
+			FeedbackID: feedback.ID,
+			ToStatus:   args[2],
+			ActorName:  getEnv("USER", ""),
+			Source:     StatusSourceCLI,
+			Comment:    strings.Join(args[3:], " "),
+		}
+		if err := db.ChangeFeedbackStatus(change); err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s -> %s\n", ticketLabel(feedback), change.FromStatus, change.ToStatus)
+
+		// Статус уже сохранен; недоступный Telegram не делает команду неуспешной
+		feedback.Status = change.ToStatus
+		if err := refreshFeedbackCardCLI(db, feedback); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: card in staff chat not updated: %v\n", err)
+		}
+	case "history":
+		history, err := db.GetStatusHistory(feedback.ID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s\n", ticketLabel(feedback), feedback.Status)
+		for _, change := range history {
+			actor := change.ActorName
+			if actor == "" {
+				actor = "-"
+			}
+			fmt.Printf("%s  %s -> %s  %s (%s)  %s\n",
+				inTimezone(change.CreatedAt).Format("2006-01-02 15:04"),
+				change.FromStatus, change.ToStatus, actor, change.Source, change.Comment)
+		}
+	default:
+		return errors.New(cliUsage)
+	}
+
+	return nil
+}
+
+// runDigestCLI отправляет сводку немедленно: за сутки (по умолчанию) или за неделю,
+// на указанные адреса или на DIGEST_RECIPIENTS
+func runDigestCLI(args []string) error {
+	kind := DigestDaily
+	if len(args) > 0 && (args[0] == DigestDaily || args[0] == DigestWeekly) {
+		kind = args[0]
+		args = args[1:]
+	}
+
+	recipients := args
+	if len(recipients) == 0 {
+		recipients = digestRecipients()
+	}
+	if len(recipients) == 0 {
+		return errors.New("no digest recipients: set DIGEST_RECIPIENTS or EMAIL_TO")
+	}
+
+	db, err := NewDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	if err := SendDigest(db, NewEmailService(nil), kind, time.Now(), recipients); err != nil {
+		return err
+	}
+	fmt.Printf("%s digest sent to %s\n", kind, strings.Join(recipients, ", "))
+	return nil
+}
+
+// isCLI - запуск с аргументами означает служебную команду, а не бота
+func isCLI() bool {
+	return len(os.Args) > 1
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// askContact показывает клавиатуру с запросом номера телефона
+func (t *TelegramBot) askContact(chatID int64, state *UserState) bool {
+	// Номер телефона раскрыл бы автора анонимного обращения
+	if getEnv("CONTACT_STEP_ENABLED", "true") != "true" || state.Data["anonymous"] == "1" {
+		return false
+	}
+
+	keyboard := tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButtonContact(t.tr(chatID, "contact.share")),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(t.tr(chatID, "button.skip")),
+		),
+	)
+	keyboard.OneTimeKeyboard = true
+
+	msg := tgbotapi.NewMessage(chatID, t.tr(chatID, "contact.ask", t.tr(chatID, "button.skip")))
+	msg.ReplyMarkup = keyboard
+	t.bot.Send(msg)
+	return true
+}
+
+// handleContactInput принимает номер телефона, которым поделился пользователь, или пропуск шага
+func (t *TelegramBot) handleContactInput(message *tgbotapi.Message, state *UserState) {
+	chatID := message.Chat.ID
+
+	switch {
+	case message.Contact != nil:
+		// Принимаем только собственный контакт отправителя, а не пересланную карточку
+		if message.Contact.UserID != message.From.ID {
+			t.sendMessage(chatID, t.tr(chatID, "contact.own_only"))
+			return
+		}
+		state.Data["phone"] = normalizePhone(message.Contact.PhoneNumber)
+		t.removeReplyKeyboard(chatID, t.tr(chatID, "contact.saved"))
+	case matchesTranslation(message.Text, "button.skip"):
+		delete(state.Data, "phone")
+		t.removeReplyKeyboard(chatID, t.tr(chatID, "contact.skipped"))
+	default:
+		t.askContact(chatID, state)
+		return
+	}
+
+	t.advanceForm(chatID, message.From, state)
+}
+
+func (t *TelegramBot) removeReplyKeyboard(chatID int64, text string) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	t.bot.Send(msg)
+}
+
+// normalizePhone приводит номер к виду +<цифры>
+func normalizePhone(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	if digits == "" {
+		return ""
+	}
+	return "+" + digits
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
 type Feedback struct {
@@ -17,7 +19,54 @@ type Feedback struct {
 	Message   string    `json:"message"`
 	Type      string    `json:"type"` // "complaint" или "review"
 	CreatedAt time.Time `json:"created_at"`
-	Status    string    `json:"status"` // "new", "processed", "sent"
+	Status    string    `json:"status"` // см. statusTransitions
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// TicketCode - код обращения, который видит пациент, например "K7QM-3XPA"
+	TicketCode string `json:"ticket_code"`
+
+	DepartmentID   int64  `json:"department_id,omitempty"`
+	DepartmentName string `json:"department_name,omitempty"`
+	Location       string `json:"location,omitempty"` // например "ward:12"
+	Phone          string `json:"phone,omitempty"`    // номер для обратного звонка
+	Rating         int    `json:"rating,omitempty"`   // оценка отзыва от 1 до 5, 0 - нет оценки
+
+	// Anonymous - автор скрыт; связь с ним хранится в feedback_identities по ReplyToken
+	Anonymous  bool   `json:"anonymous"`
+	ReplyToken string `json:"-"`
+
+	// AssigneeID - сотрудник, взявший обращение в работу
+	AssigneeID   int64  `json:"assignee_id,omitempty"`
+	AssigneeName string `json:"assignee_name,omitempty"`
+
+	// Карточка обращения в чате сотрудников, см. ADMIN_CHAT_ID
+	CardChatID    int64 `json:"-"`
+	CardMessageID int   `json:"-"`
+
+	// DueAt - срок ответа по политике SLA, нулевое значение - срок не контролируется
+	DueAt time.Time `json:"due_at,omitempty"`
+
+	Attachments []*Attachment     `json:"attachments,omitempty"`
+	Answers     []*FeedbackAnswer `json:"answers,omitempty"`
+}
+
+// FeedbackStats - сводная статистика обращений
+type FeedbackStats struct {
+	ByType       map[string]int     `json:"by_type"`
+	ByDepartment []*DepartmentStats `json:"by_department"`
+	Ratings      *RatingStats       `json:"ratings"`
+}
+
+type DepartmentStats struct {
+	DepartmentID int64        `json:"department_id"`
+	Name         string       `json:"name"`
+	Complaints   int          `json:"complaints"`
+	Reviews      int          `json:"reviews"`
+	Ratings      *RatingStats `json:"ratings,omitempty"`
+}
+
+func (s *DepartmentStats) Total() int {
+	return s.Complaints + s.Reviews
 }
 
 type Database struct {
@@ -68,7 +117,7 @@ func createTables(db *sql.DB) error {
 		message TEXT NOT NULL,
 		type ENUM('complaint', 'review') NOT NULL DEFAULT 'complaint',
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		status ENUM('new', 'processed', 'sent') DEFAULT 'new',
+		status ENUM('new', 'acknowledged', 'in_progress', 'awaiting_patient', 'resolved', 'rejected', 'reopened') DEFAULT 'new',
 		INDEX idx_user_id (user_id),
 		INDEX idx_type (type),
 		INDEX idx_status (status),
@@ -80,16 +129,485 @@ func createTables(db *sql.DB) error {
 		return fmt.Errorf("failed to create feedback table: %w", err)
 	}
 
+	// Создаем таблицу состояний диалога
+	userStatesQuery := `
+	CREATE TABLE IF NOT EXISTS user_states (
+		user_id BIGINT PRIMARY KEY,
+		state VARCHAR(64) NOT NULL,
+		data TEXT,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		expires_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_expires_at (expires_at)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(userStatesQuery); err != nil {
+		return fmt.Errorf("failed to create user_states table: %w", err)
+	}
+
+	// Создаем таблицу отделений
+	departmentsQuery := `
+	CREATE TABLE IF NOT EXISTS departments (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		code VARCHAR(64) NOT NULL UNIQUE,
+		name VARCHAR(255) NOT NULL,
+		active BOOLEAN NOT NULL DEFAULT TRUE,
+		sort_order INT NOT NULL DEFAULT 0,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(departmentsQuery); err != nil {
+		return fmt.Errorf("failed to create departments table: %w", err)
+	}
+
+	// Создаем таблицу вложений к обращениям
+	attachmentsQuery := `
+	CREATE TABLE IF NOT EXISTS feedback_attachments (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		feedback_id BIGINT NOT NULL,
+		kind VARCHAR(32) NOT NULL,
+		file_id VARCHAR(255) NOT NULL,
+		file_unique_id VARCHAR(255) NOT NULL,
+		file_name VARCHAR(255),
+		mime_type VARCHAR(255),
+		size BIGINT NOT NULL DEFAULT 0,
+		storage_path VARCHAR(512) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_feedback_id (feedback_id),
+		INDEX idx_storage_path (storage_path(191)),
+		FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(attachmentsQuery); err != nil {
+		return fmt.Errorf("failed to create feedback_attachments table: %w", err)
+	}
+
+	// Создаем таблицу связи анонимных обращений с авторами.
+	// Ее читает только бот для доставки ответов; в письма, статистику и выгрузки она не попадает.
+	identitiesQuery := `
+	CREATE TABLE IF NOT EXISTS feedback_identities (
+		feedback_id BIGINT PRIMARY KEY,
+		reply_token VARCHAR(64) NOT NULL UNIQUE,
+		user_hash CHAR(64) NOT NULL,
+		user_id BIGINT NULL,
+		chat_id BIGINT NULL,
+		chat_id_sealed VARBINARY(64) NULL,
+		username VARCHAR(255) NULL,
+		first_name VARCHAR(255) NULL,
+		last_name VARCHAR(255) NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_user_hash (user_hash),
+		FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(identitiesQuery); err != nil {
+		return fmt.Errorf("failed to create feedback_identities table: %w", err)
+	}
+
+	// Создаем таблицу выбранных пользователями языков интерфейса
+	languagesQuery := `
+	CREATE TABLE IF NOT EXISTS user_languages (
+		user_id BIGINT PRIMARY KEY,
+		language VARCHAR(8) NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(languagesQuery); err != nil {
+		return fmt.Errorf("failed to create user_languages table: %w", err)
+	}
+
+	// Создаем таблицу ответов на дополнительные вопросы анкеты
+	answersQuery := `
+	CREATE TABLE IF NOT EXISTS feedback_answers (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		feedback_id BIGINT NOT NULL,
+		step_id VARCHAR(64) NOT NULL,
+		question TEXT NOT NULL,
+		value TEXT NOT NULL,
+		label TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_feedback_id (feedback_id),
+		INDEX idx_step_id (step_id),
+		FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(answersQuery); err != nil {
+		return fmt.Errorf("failed to create feedback_answers table: %w", err)
+	}
+
+	// Создаем таблицу версий анкеты; используется последняя активная
+	formsQuery := `
+	CREATE TABLE IF NOT EXISTS form_definitions (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		definition MEDIUMTEXT NOT NULL,
+		active BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_active (active)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(formsQuery); err != nil {
+		return fmt.Errorf("failed to create form_definitions table: %w", err)
+	}
+
+	// Создаем таблицу сотрудников с ролями; ADMIN_USER_ID из окружения в ней не хранится
+	adminsQuery := `
+	CREATE TABLE IF NOT EXISTS admins (
+		user_id BIGINT PRIMARY KEY,
+		role ENUM('super_admin', 'handler', 'viewer', 'department_head') NOT NULL,
+		department_id BIGINT NULL,
+		name VARCHAR(255) NULL,
+		email VARCHAR(255) NULL,
+		added_by BIGINT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_role (role),
+		INDEX idx_department_id (department_id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(adminsQuery); err != nil {
+		return fmt.Errorf("failed to create admins table: %w", err)
+	}
+
+	// Создаем таблицу переписки сотрудников с авторами обращений
+	messagesQuery := `
+	CREATE TABLE IF NOT EXISTS feedback_messages (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		feedback_id BIGINT NOT NULL,
+		direction ENUM('staff', 'patient') NOT NULL,
+		author_id BIGINT NULL,
+		text TEXT NOT NULL,
+		recipient_hash CHAR(64) NULL,
+		telegram_message_id BIGINT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_feedback_id (feedback_id),
+		INDEX idx_recipient_message (recipient_hash, telegram_message_id),
+		FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(messagesQuery); err != nil {
+		return fmt.Errorf("failed to create feedback_messages table: %w", err)
+	}
+
+	// Создаем таблицу истории статусов: кто, когда и с каким комментарием менял статус
+	statusHistoryQuery := `
+	CREATE TABLE IF NOT EXISTS feedback_status_history (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		feedback_id BIGINT NOT NULL,
+		from_status VARCHAR(32) NOT NULL,
+		to_status VARCHAR(32) NOT NULL,
+		actor_id BIGINT NULL,
+		actor_name VARCHAR(255) NULL,
+		source VARCHAR(16) NOT NULL,
+		comment TEXT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_feedback_id (feedback_id),
+		INDEX idx_created_at (created_at),
+		FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(statusHistoryQuery); err != nil {
+		return fmt.Errorf("failed to create feedback_status_history table: %w", err)
+	}
+
+	// Создаем таблицу очереди автоназначения: последний назначенный сотрудник по отделению
+	rotationQuery := `
+	CREATE TABLE IF NOT EXISTS assignment_rotation (
+		department_id BIGINT PRIMARY KEY,
+		last_user_id BIGINT NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(rotationQuery); err != nil {
+		return fmt.Errorf("failed to create assignment_rotation table: %w", err)
+	}
+
+	// Создаем таблицу политик SLA: срок ответа в рабочих часах по типу и отделению
+	slaPoliciesQuery := `
+	CREATE TABLE IF NOT EXISTS sla_policies (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		feedback_type VARCHAR(16) NOT NULL DEFAULT '',
+		department_id BIGINT NOT NULL DEFAULT 0,
+		hours INT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE INDEX idx_scope (feedback_type, department_id)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(slaPoliciesQuery); err != nil {
+		return fmt.Errorf("failed to create sla_policies table: %w", err)
+	}
+
+	// Создаем календарь праздничных (нерабочих) дней
+	holidaysQuery := `
+	CREATE TABLE IF NOT EXISTS holidays (
+		day DATE PRIMARY KEY,
+		name VARCHAR(255) NULL
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(holidaysQuery); err != nil {
+		return fmt.Errorf("failed to create holidays table: %w", err)
+	}
+
+	// Создаем журнал эскалаций: одна запись на ступень цепочки для обращения
+	escalationsQuery := `
+	CREATE TABLE IF NOT EXISTS feedback_escalations (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		feedback_id BIGINT NOT NULL,
+		level INT NOT NULL,
+		role VARCHAR(32) NOT NULL,
+		recipients TEXT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE INDEX idx_feedback_level (feedback_id, level),
+		FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE CASCADE
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(escalationsQuery); err != nil {
+		return fmt.Errorf("failed to create feedback_escalations table: %w", err)
+	}
+
+	// Создаем журнал отправленных сводок, чтобы не отправлять одну сводку дважды
+	digestLogQuery := `
+	CREATE TABLE IF NOT EXISTS digest_log (
+		kind VARCHAR(16) NOT NULL,
+		day DATE NOT NULL,
+		recipients TEXT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (kind, day)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(digestLogQuery); err != nil {
+		return fmt.Errorf("failed to create digest_log table: %w", err)
+	}
+
+	// Создаем журнал отправленных обращений для ограничения частоты и поиска повторов.
+	// Пользователь хранится необратимым хешем, текст - только отпечатком simhash.
+	submissionsQuery := `
+	CREATE TABLE IF NOT EXISTS feedback_submissions (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY,
+		user_hash CHAR(64) NOT NULL,
+		fingerprint BIGINT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		INDEX idx_user_created (user_hash, created_at)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(submissionsQuery); err != nil {
+		return fmt.Errorf("failed to create feedback_submissions table: %w", err)
+	}
+
+	// Создаем счетчики отклоненных антиспамом обращений по дням и причинам
+	spamRejectionsQuery := `
+	CREATE TABLE IF NOT EXISTS spam_rejections (
+		day DATE NOT NULL,
+		reason VARCHAR(32) NOT NULL,
+		count INT NOT NULL DEFAULT 0,
+		PRIMARY KEY (day, reason)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+	`
+
+	if _, err := db.Exec(spamRejectionsQuery); err != nil {
+		return fmt.Errorf("failed to create spam_rejections table: %w", err)
+	}
+
+	// Миграции выполняются после создания всех таблиц: они могут затрагивать любую из них
+	return migrateTables(db)
+}
+
+// migrateTables добавляет в существующие таблицы колонки, появившиеся в новых версиях
+func migrateTables(db *sql.DB) error {
+	migrations := []struct {
+		table      string
+		column     string
+		definition string
+	}{
+		{"feedback", "department_id", "BIGINT NULL, ADD INDEX idx_department_id (department_id)"},
+		{"feedback", "location", "VARCHAR(64) NULL"},
+		{"feedback", "is_anonymous", "BOOLEAN NOT NULL DEFAULT FALSE"},
+		{"feedback", "reply_token", "VARCHAR(64) NULL, ADD UNIQUE INDEX idx_reply_token (reply_token)"},
+		{"feedback", "phone", "VARCHAR(32) NULL"},
+		{"feedback", "rating", "TINYINT NULL"},
+		{"feedback", "ticket_code", "VARCHAR(16) NULL, ADD UNIQUE INDEX idx_ticket_code (ticket_code)"},
+		{"feedback", "updated_at", "TIMESTAMP NULL DEFAULT NULL ON UPDATE CURRENT_TIMESTAMP"},
+		{"feedback", "assignee_id", "BIGINT NULL, ADD INDEX idx_assignee_id (assignee_id)"},
+		{"feedback", "assignee_name", "VARCHAR(255) NULL"},
+		{"feedback", "card_chat_id", "BIGINT NULL"},
+		{"feedback", "card_message_id", "BIGINT NULL"},
+		{"feedback", "due_at", "TIMESTAMP NULL DEFAULT NULL, ADD INDEX idx_due_at (due_at)"},
+		{"admins", "email", "VARCHAR(255) NULL"},
+	}
+
+	for _, m := range migrations {
+		if err := addColumnIfNotExists(db, m.table, m.column, m.definition); err != nil {
+			return err
+		}
+	}
+
+	// Индексы, появившиеся в новых версиях
+	indexes := []struct {
+		table   string
+		index   string
+		columns string
+	}{
+		{"feedback_attachments", "idx_storage_path", "storage_path(191)"},
+	}
+
+	for _, i := range indexes {
+		if err := addIndexIfNotExists(db, i.table, i.index, i.columns); err != nil {
+			return err
+		}
+	}
+
+	if err := migrateFeedbackStatuses(db); err != nil {
+		return err
+	}
+
+	// Колонки, тип которых расширился в новых версиях
+	changes := []struct {
+		table      string
+		column     string
+		columnType string
+		definition string
+	}{
+		{"feedback", "status", "enum('new','acknowledged','in_progress','awaiting_patient','resolved','rejected','reopened')", "ENUM('new', 'acknowledged', 'in_progress', 'awaiting_patient', 'resolved', 'rejected', 'reopened') DEFAULT 'new'"},
+	}
+
+	for _, c := range changes {
+		if err := modifyColumnIfDifferent(db, c.table, c.column, c.columnType, c.definition); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (d *Database) SaveFeedback(feedback *Feedback) error {
+// migrateFeedbackStatuses переводит обращения со старых статусов на новый жизненный цикл:
+// processed становится resolved, а никогда не выставлявшийся sent - new
+func migrateFeedbackStatuses(db *sql.DB) error {
 	query := `
-	INSERT INTO feedback (user_id, username, first_name, last_name, message, type, status)
-	VALUES (?, ?, ?, ?, ?, ?, ?)
+	SELECT COLUMN_TYPE
+	FROM information_schema.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'feedback' AND COLUMN_NAME = 'status'
 	`
 
-	result, err := d.db.Exec(query,
+	var current string
+	if err := db.QueryRow(query).Scan(&current); err != nil {
+		return fmt.Errorf("failed to check column feedback.status: %w", err)
+	}
+	if !strings.Contains(current, "'processed'") {
+		return nil
+	}
+
+	// Сначала разрешаем и старые, и новые значения, чтобы перенести данные
+	transitional := "ENUM('new', 'in_progress', 'processed', 'sent', 'acknowledged', 'awaiting_patient', 'resolved', 'rejected', 'reopened') DEFAULT 'new'"
+	if _, err := db.Exec("ALTER TABLE feedback MODIFY COLUMN status " + transitional); err != nil {
+		return fmt.Errorf("failed to extend feedback statuses: %w", err)
+	}
+	if _, err := db.Exec(`UPDATE feedback SET status = 'resolved', updated_at = updated_at WHERE status = 'processed'`); err != nil {
+		return fmt.Errorf("failed to migrate processed feedback: %w", err)
+	}
+	if _, err := db.Exec(`UPDATE feedback SET status = 'new', updated_at = updated_at WHERE status = 'sent'`); err != nil {
+		return fmt.Errorf("failed to migrate sent feedback: %w", err)
+	}
+
+	return nil
+}
+
+func addIndexIfNotExists(db *sql.DB, table, index, columns string) error {
+	query := `
+	SELECT COUNT(*)
+	FROM information_schema.STATISTICS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ?
+	`
+
+	var count int
+	if err := db.QueryRow(query, table, index).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check index %s.%s: %w", table, index, err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD INDEX %s (%s)", table, index, columns)); err != nil {
+		return fmt.Errorf("failed to add index %s.%s: %w", table, index, err)
+	}
+
+	return nil
+}
+
+func addColumnIfNotExists(db *sql.DB, table, column, definition string) error {
+	query := `
+	SELECT COUNT(*)
+	FROM information_schema.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?
+	`
+
+	var count int
+	if err := db.QueryRow(query, table, column).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check column %s.%s: %w", table, column, err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+
+	return nil
+}
+
+// modifyColumnIfDifferent меняет определение колонки, если ее тип отличается от columnType
+func modifyColumnIfDifferent(db *sql.DB, table, column, columnType, definition string) error {
+	query := `
+	SELECT COLUMN_TYPE
+	FROM information_schema.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?
+	`
+
+	var current string
+	if err := db.QueryRow(query, table, column).Scan(&current); err != nil {
+		return fmt.Errorf("failed to check column %s.%s: %w", table, column, err)
+	}
+	if current == columnType {
+		return nil
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to modify column %s.%s: %w", table, column, err)
+	}
+
+	return nil
+}
+
+// SaveFeedback сохраняет обращение. У анонимного обращения identity сохраняется в той же транзакции:
+// обращение без связи с автором было бы недоступно в /mystatus и в переписке.
+func (d *Database) SaveFeedback(feedback *Feedback, identity *FeedbackIdentity) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+	INSERT INTO feedback (user_id, username, first_name, last_name, message, type, status, department_id, location,
+		is_anonymous, reply_token, phone, rating, ticket_code, due_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := tx.Exec(query,
 		feedback.UserID,
 		feedback.Username,
 		feedback.FirstName,
@@ -97,7 +615,18 @@ func (d *Database) SaveFeedback(feedback *Feedback) error {
 		feedback.Message,
 		feedback.Type,
 		feedback.Status,
+		nullInt64(feedback.DepartmentID),
+		nullString(feedback.Location),
+		feedback.Anonymous,
+		nullString(feedback.ReplyToken),
+		nullString(feedback.Phone),
+		nullInt64(int64(feedback.Rating)),
+		nullString(feedback.TicketCode),
+		nullTime(feedback.DueAt),
 	)
+	if isDuplicateKey(err, "idx_ticket_code") {
+		return fmt.Errorf("%w: %s", errDuplicateTicketCode, feedback.TicketCode)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to save feedback: %w", err)
 	}
@@ -107,73 +636,162 @@ func (d *Database) SaveFeedback(feedback *Feedback) error {
 		return fmt.Errorf("failed to get last insert id: %w", err)
 	}
 
+	if identity != nil {
+		identity.FeedbackID = id
+		if err := saveFeedbackIdentity(tx, identity); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit feedback: %w", err)
+	}
+
 	feedback.ID = id
 	return nil
 }
 
-func (d *Database) GetNewFeedbacks() ([]*Feedback, error) {
+func (d *Database) SaveAttachment(attachment *Attachment) error {
 	query := `
-	SELECT id, user_id, username, first_name, last_name, message, type, created_at, status
-	FROM feedback
-	WHERE status = 'new'
-	ORDER BY created_at ASC
+	INSERT INTO feedback_attachments (feedback_id, kind, file_id, file_unique_id, file_name, mime_type, size, storage_path)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := d.db.Exec(query,
+		attachment.FeedbackID,
+		attachment.Kind,
+		attachment.FileID,
+		attachment.FileUniqueID,
+		attachment.FileName,
+		attachment.MimeType,
+		attachment.Size,
+		attachment.StoragePath,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save attachment: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	attachment.ID = id
+	return nil
+}
+
+func (d *Database) GetFeedbackAttachments(feedbackID int64) ([]*Attachment, error) {
+	query := `
+	SELECT id, feedback_id, kind, file_id, file_unique_id, file_name, mime_type, size, storage_path, created_at
+	FROM feedback_attachments
+	WHERE feedback_id = ?
+	ORDER BY id ASC
 	`
 
-	rows, err := d.db.Query(query)
+	rows, err := d.db.Query(query, feedbackID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query new feedbacks: %w", err)
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
 	}
 	defer rows.Close()
 
-	var feedbacks []*Feedback
+	var attachments []*Attachment
 	for rows.Next() {
-		feedback := &Feedback{}
+		attachment := &Attachment{}
 		err := rows.Scan(
-			&feedback.ID,
-			&feedback.UserID,
-			&feedback.Username,
-			&feedback.FirstName,
-			&feedback.LastName,
-			&feedback.Message,
-			&feedback.Type,
-			&feedback.CreatedAt,
-			&feedback.Status,
+			&attachment.ID,
+			&attachment.FeedbackID,
+			&attachment.Kind,
+			&attachment.FileID,
+			&attachment.FileUniqueID,
+			&attachment.FileName,
+			&attachment.MimeType,
+			&attachment.Size,
+			&attachment.StoragePath,
+			&attachment.CreatedAt,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
 		}
 
-		feedbacks = append(feedbacks, feedback)
+		attachments = append(attachments, attachment)
 	}
 
-	return feedbacks, nil
+	return attachments, nil
 }
 
-func (d *Database) UpdateFeedbackStatus(id int64, status string) error {
-	query := `UPDATE feedback SET status = ? WHERE id = ?`
-	_, err := d.db.Exec(query, status, id)
+// feedbackSelect - общий список колонок для выборки обращений, см. scanFeedback
+const feedbackSelect = `
+	SELECT f.id, f.user_id, f.username, f.first_name, f.last_name, f.message, f.type, f.created_at, f.status,
+		f.department_id, COALESCE(dep.name, ''), COALESCE(f.location, ''),
+		f.is_anonymous, COALESCE(f.reply_token, ''), COALESCE(f.phone, ''), COALESCE(f.rating, 0),
+		COALESCE(f.ticket_code, ''), COALESCE(f.updated_at, f.created_at),
+		COALESCE(f.assignee_id, 0), COALESCE(f.assignee_name, ''), COALESCE(f.card_chat_id, 0), COALESCE(f.card_message_id, 0),
+		f.due_at
+	FROM feedback f
+	LEFT JOIN departments dep ON dep.id = f.department_id
+	`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanFeedback(row rowScanner) (*Feedback, error) {
+	feedback := &Feedback{}
+	var departmentID sql.NullInt64
+	var dueAt sql.NullTime
+	err := row.Scan(
+		&feedback.ID,
+		&feedback.UserID,
+		&feedback.Username,
+		&feedback.FirstName,
+		&feedback.LastName,
+		&feedback.Message,
+		&feedback.Type,
+		&feedback.CreatedAt,
+		&feedback.Status,
+		&departmentID,
+		&feedback.DepartmentName,
+		&feedback.Location,
+		&feedback.Anonymous,
+		&feedback.ReplyToken,
+		&feedback.Phone,
+		&feedback.Rating,
+		&feedback.TicketCode,
+		&feedback.UpdatedAt,
+		&feedback.AssigneeID,
+		&feedback.AssigneeName,
+		&feedback.CardChatID,
+		&feedback.CardMessageID,
+		&dueAt,
+	)
 	if err != nil {
-		return fmt.Errorf("failed to update feedback status: %w", err)
+		return nil, err
 	}
-	return nil
+
+	feedback.DepartmentID = departmentID.Int64
+	feedback.DueAt = dueAt.Time
+	return feedback, nil
 }
 
-func (d *Database) GetFeedbackStats() (map[string]int, error) {
+// GetFeedbackStats собирает статистику обращений; departmentID != 0 ограничивает ее одним отделением,
+// assigneeID != 0 - обращениями одного ответственного
+func (d *Database) GetFeedbackStats(departmentID, assigneeID int64) (*FeedbackStats, error) {
 	query := `
 	SELECT 
 		type,
 		COUNT(*) as count
 	FROM feedback
+	WHERE (? = 0 OR department_id = ?) AND (? = 0 OR assignee_id = ?)
 	GROUP BY type
 	`
 
-	rows, err := d.db.Query(query)
+	rows, err := d.db.Query(query, departmentID, departmentID, assigneeID, assigneeID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get feedback stats: %w", err)
 	}
 	defer rows.Close()
 
-	stats := make(map[string]int)
+	stats := &FeedbackStats{ByType: make(map[string]int)}
 	for rows.Next() {
 		var feedbackType string
 		var count int
@@ -181,7 +799,49 @@ func (d *Database) GetFeedbackStats() (map[string]int, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan stats: %w", err)
 		}
-		stats[feedbackType] = count
+		stats.ByType[feedbackType] = count
+	}
+
+	departmentQuery := `
+	SELECT
+		COALESCE(f.department_id, 0),
+		COALESCE(dep.name, ''),
+		SUM(f.type = 'complaint'),
+		SUM(f.type = 'review')
+	FROM feedback f
+	LEFT JOIN departments dep ON dep.id = f.department_id
+	WHERE (? = 0 OR f.department_id = ?) AND (? = 0 OR f.assignee_id = ?)
+	GROUP BY f.department_id, dep.name
+	ORDER BY COUNT(*) DESC
+	`
+
+	depRows, err := d.db.Query(departmentQuery, departmentID, departmentID, assigneeID, assigneeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get department stats: %w", err)
+	}
+	defer depRows.Close()
+
+	for depRows.Next() {
+		departmentStats := &DepartmentStats{}
+		err := depRows.Scan(
+			&departmentStats.DepartmentID,
+			&departmentStats.Name,
+			&departmentStats.Complaints,
+			&departmentStats.Reviews,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan department stats: %w", err)
+		}
+		stats.ByDepartment = append(stats.ByDepartment, departmentStats)
+	}
+
+	overallRatings, departmentRatings, err := d.GetRatingStats(departmentID, assigneeID)
+	if err != nil {
+		return nil, err
+	}
+	stats.Ratings = overallRatings
+	for _, departmentStats := range stats.ByDepartment {
+		departmentStats.Ratings = departmentRatings[departmentStats.DepartmentID]
 	}
 
 	return stats, nil
@@ -190,3 +850,24 @@ func (d *Database) GetFeedbackStats() (map[string]int, error) {
 func (d *Database) Close() error {
 	return d.db.Close()
 }
+
+// nullInt64 сохраняет нулевой идентификатор как NULL
+func nullInt64(value int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: value, Valid: value != 0}
+}
+
+// isDuplicateKey - ошибка MySQL 1062: значение уже есть в уникальном индексе index
+func isDuplicateKey(err error, index string) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 && strings.Contains(mysqlErr.Message, index)
+}
+
+// nullTime сохраняет нулевое время как NULL
+func nullTime(value time.Time) sql.NullTime {
+	return sql.NullTime{Time: value.UTC(), Valid: !value.IsZero()}
+}
+
+// nullString сохраняет пустую строку как NULL
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
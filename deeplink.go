@@ -0,0 +1,108 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Параметр /start (deep link) состоит из частей, разделенных "-":
+// dept_<код отделения> и ward_<номер палаты>, например "dept_cardio-ward_12".
+// Telegram допускает в нем только A-Z, a-z, 0-9, _ и - длиной до 64 символов.
+
+var (
+	departmentCodePattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+	wardPattern           = regexp.MustCompile(`^[A-Za-z0-9]{1,16}$`)
+)
+
+type StartPayload struct {
+	DepartmentCode string
+	Ward           string
+}
+
+func parseStartPayload(payload string) StartPayload {
+	var result StartPayload
+	for _, part := range strings.Split(strings.TrimSpace(payload), "-") {
+		if code, ok := strings.CutPrefix(part, "dept_"); ok && departmentCodePattern.MatchString(strings.ToLower(code)) {
+			result.DepartmentCode = strings.ToLower(code)
+		} else if ward, ok := strings.CutPrefix(part, "ward_"); ok && wardPattern.MatchString(ward) {
+			result.Ward = ward
+		}
+	}
+	return result
+}
+
+func (p StartPayload) String() string {
+	var parts []string
+	if p.DepartmentCode != "" {
+		parts = append(parts, "dept_"+p.DepartmentCode)
+	}
+	if p.Ward != "" {
+		parts = append(parts, "ward_"+p.Ward)
+	}
+	return strings.Join(parts, "-")
+}
+
+// wardLocation формирует значение колонки feedback.location для палаты
+func wardLocation(ward string) string {
+	return "ward:" + ward
+}
+
+// locationDisplayName показывает место обращения в понятном виде на языке lang
+func locationDisplayName(lang, location string) string {
+	if ward, ok := strings.CutPrefix(location, "ward:"); ok {
+		return Translate(lang, "location.ward", ward)
+	}
+	return location
+}
+
+// applyStartPayload заполняет состояние отделением и местом из параметра /start
+// и возвращает подпись для приветствия
+func (t *TelegramBot) applyStartPayload(chatID int64, state *UserState, payload string) string {
+	start := parseStartPayload(payload)
+	var labels []string
+
+	if start.DepartmentCode != "" {
+		department, err := t.database.GetDepartmentByCode(start.DepartmentCode)
+		if err != nil {
+			t.logger.Error("Failed to get department by code: ", err)
+		}
+		if department != nil && department.Active {
+			state.Data["start_department_id"] = strconv.FormatInt(department.ID, 10)
+			labels = append(labels, department.Name)
+		}
+	}
+	if start.Ward != "" {
+		state.Data["location"] = wardLocation(start.Ward)
+		labels = append(labels, locationDisplayName(t.lang(chatID), state.Data["location"]))
+	}
+
+	return strings.Join(labels, ", ")
+}
+
+// botLink возвращает ссылку на бота с параметром /start
+func botLink(botUsername string, payload StartPayload) string {
+	link := "https://t.me/" + botUsername
+	if p := payload.String(); p != "" {
+		link += "?start=" + p
+	}
+	return link
+}
+
+func (t *TelegramBot) Username() string {
+	return t.bot.Self.UserName
+}
+
+// sendStartGreeting приветствует пользователя, пришедшего по QR-коду отделения или палаты
+func (t *TelegramBot) sendStartGreeting(message *tgbotapi.Message, state *UserState) {
+	label := t.applyStartPayload(message.Chat.ID, state, message.CommandArguments())
+	if label == "" {
+		t.sendMainMenu(message.Chat.ID, message.From.ID)
+		return
+	}
+
+	t.sendMessage(message.Chat.ID, "📍 "+label)
+	t.sendMainMenu(message.Chat.ID, message.From.ID)
+}
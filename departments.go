@@ -0,0 +1,261 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+type Department struct {
+	ID        int64     `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Active    bool      `json:"active"`
+	SortOrder int       `json:"sort_order"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (d *Database) ListDepartments(activeOnly bool) ([]*Department, error) {
+	query := `
+	SELECT id, code, name, active, sort_order, created_at
+	FROM departments
+	`
+	if activeOnly {
+		query += ` WHERE active = TRUE`
+	}
+	query += ` ORDER BY sort_order ASC, name ASC`
+
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query departments: %w", err)
+	}
+	defer rows.Close()
+
+	var departments []*Department
+	for rows.Next() {
+		department := &Department{}
+		err := rows.Scan(
+			&department.ID,
+			&department.Code,
+			&department.Name,
+			&department.Active,
+			&department.SortOrder,
+			&department.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan department: %w", err)
+		}
+
+		departments = append(departments, department)
+	}
+
+	return departments, nil
+}
+
+func (d *Database) GetDepartment(id int64) (*Department, error) {
+	return d.getDepartment(`WHERE id = ?`, id)
+}
+
+func (d *Database) GetDepartmentByCode(code string) (*Department, error) {
+	return d.getDepartment(`WHERE code = ?`, strings.ToLower(code))
+}
+
+func (d *Database) getDepartment(where string, arg interface{}) (*Department, error) {
+	query := `
+	SELECT id, code, name, active, sort_order, created_at
+	FROM departments
+	` + where
+
+	department := &Department{}
+	err := d.db.QueryRow(query, arg).Scan(
+		&department.ID,
+		&department.Code,
+		&department.Name,
+		&department.Active,
+		&department.SortOrder,
+		&department.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get department: %w", err)
+	}
+
+	return department, nil
+}
+
+// SaveDepartment создает отделение или обновляет название существующего с тем же кодом
+func (d *Database) SaveDepartment(department *Department) error {
+	query := `
+	INSERT INTO departments (code, name, active, sort_order)
+	VALUES (?, ?, TRUE, ?)
+	ON DUPLICATE KEY UPDATE name = VALUES(name), active = TRUE, id = LAST_INSERT_ID(id)
+	`
+
+	department.Code = strings.ToLower(department.Code)
+	result, err := d.db.Exec(query, department.Code, department.Name, department.SortOrder)
+	if err != nil {
+		return fmt.Errorf("failed to save department: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	department.ID = id
+	department.Active = true
+	return nil
+}
+
+// DeactivateDepartment скрывает отделение из выбора, сохраняя связь со старыми обращениями.
+// false - отделения нет. Уже скрытое отделение MySQL не считает измененным,
+// поэтому наличие проверяется отдельным запросом, а не по RowsAffected.
+func (d *Database) DeactivateDepartment(code string) (bool, error) {
+	department, err := d.GetDepartmentByCode(code)
+	if err != nil {
+		return false, err
+	}
+	if department == nil {
+		return false, nil
+	}
+
+	if _, err := d.db.Exec(`UPDATE departments SET active = FALSE WHERE id = ?`, department.ID); err != nil {
+		return false, fmt.Errorf("failed to deactivate department: %w", err)
+	}
+	return true, nil
+}
+
+// askDepartment предлагает выбрать отделение; шаг пропускается, если отделений нет
+func (t *TelegramBot) askDepartment(chatID int64, state *UserState) bool {
+	// Отделение уже известно из QR-кода
+	if state.Data["department_id"] != "" {
+		return false
+	}
+
+	departments, err := t.database.ListDepartments(true)
+	if err != nil {
+		t.logger.Error("Failed to list departments: ", err)
+	}
+	if len(departments) == 0 {
+		return false
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, department := range departments {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(department.Name, fmt.Sprintf("dept:%d", department.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "department.skip"), "dept:skip"),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, t.tr(chatID, "department.ask"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	t.bot.Send(msg)
+	return true
+}
+
+// handleDepartmentSelection обрабатывает нажатие кнопки отделения (dept:<id> или dept:skip)
+func (t *TelegramBot) handleDepartmentSelection(chatID int64, from *tgbotapi.User, state *UserState, value string) {
+	if state.State != StateWaitingForDepartment {
+		t.sendMainMenu(chatID, from.ID)
+		return
+	}
+
+	if value != "skip" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			t.askDepartment(chatID, state)
+			return
+		}
+
+		department, err := t.database.GetDepartment(id)
+		if err != nil {
+			t.logger.Error("Failed to get department: ", err)
+		}
+		if department == nil || !department.Active {
+			t.askDepartment(chatID, state)
+			return
+		}
+		state.Data["department_id"] = strconv.FormatInt(department.ID, 10)
+	}
+
+	t.advanceForm(chatID, from, state)
+}
+
+// handleDepartmentCommand обрабатывает команды администратора для управления отделениями
+func (t *TelegramBot) handleDepartmentCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+
+	switch message.Command() {
+	case "departments":
+		departments, err := t.database.ListDepartments(false)
+		if err != nil {
+			t.logger.Error("Failed to list departments: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "departments.error"))
+			return
+		}
+		if len(departments) == 0 {
+			t.sendMessage(chatID, t.tr(chatID, "departments.empty"))
+			return
+		}
+
+		var sb strings.Builder
+		sb.WriteString(t.tr(chatID, "departments.title") + "\n\n")
+		for _, department := range departments {
+			mark := "✅"
+			if !department.Active {
+				mark = "🚫"
+			}
+			sb.WriteString(fmt.Sprintf("%s %s — %s\n", mark, department.Code, department.Name))
+		}
+		sb.WriteString("\n" + t.tr(chatID, "departments.hint"))
+		t.sendMessage(chatID, sb.String())
+	case "dept_add":
+		if len(args) < 2 {
+			t.sendMessage(chatID, t.tr(chatID, "departments.add_usage"))
+			return
+		}
+
+		if !departmentCodePattern.MatchString(strings.ToLower(args[0])) {
+			t.sendMessage(chatID, t.tr(chatID, "departments.bad_code"))
+			return
+		}
+
+		department := &Department{
+			Code: args[0],
+			Name: strings.Join(args[1:], " "),
+		}
+		if err := t.database.SaveDepartment(department); err != nil {
+			t.logger.Error("Failed to save department: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "departments.save_error"))
+			return
+		}
+		t.sendMessage(chatID, t.tr(chatID, "departments.saved", department.Code, department.Name))
+	case "dept_remove":
+		if len(args) != 1 {
+			t.sendMessage(chatID, t.tr(chatID, "departments.remove_usage"))
+			return
+		}
+
+		found, err := t.database.DeactivateDepartment(args[0])
+		if err != nil {
+			t.logger.Error("Failed to deactivate department: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "departments.remove_error"))
+			return
+		}
+		if !found {
+			t.sendMessage(chatID, t.tr(chatID, "departments.not_found"))
+			return
+		}
+		t.sendMessage(chatID, t.tr(chatID, "departments.removed"))
+	}
+}
@@ -0,0 +1,413 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// Виды сводок
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// digestListLimit - сколько обращений перечислять в каждом разделе сводки, остальные только считаются
+const digestListLimit = 30
+
+// Digest - сводка обращений за период для руководства
+type Digest struct {
+	Kind string
+	From time.Time
+	To   time.Time
+
+	New             []*Feedback
+	NewTotal        int
+	Unresolved      []*Feedback
+	UnresolvedTotal int
+	Overdue         []*Feedback
+	OverdueTotal    int
+
+	Departments []*DigestDepartment
+}
+
+// DigestDepartment - счетчики сводки по отделению
+type DigestDepartment struct {
+	Name    string
+	New     int
+	Open    int
+	Overdue int
+}
+
+// GetDigestDepartments считает по отделениям новые за период, открытые и просроченные обращения
+func (d *Database) GetDigestDepartments(from, to, now time.Time) ([]*DigestDepartment, error) {
+	open := "?" + strings.Repeat(", ?", len(openStatuses)-1)
+	watched := "?" + strings.Repeat(", ?", len(slaWatchedStatuses)-1)
+	query := `
+	SELECT COALESCE(dep.name, ''),
+		SUM(f.created_at >= ? AND f.created_at < ?),
+		SUM(f.status IN (` + open + `)),
+		SUM(f.due_at IS NOT NULL AND f.due_at < ? AND f.status IN (` + watched + `))
+	FROM feedback f
+	LEFT JOIN departments dep ON dep.id = f.department_id
+	WHERE (f.created_at >= ? AND f.created_at < ?) OR f.status IN (` + open + `)
+	GROUP BY f.department_id, dep.name
+	ORDER BY dep.name ASC
+	`
+
+	args := []interface{}{from.UTC(), to.UTC()}
+	for _, status := range openStatuses {
+		args = append(args, status)
+	}
+	args = append(args, now.UTC())
+	for _, status := range slaWatchedStatuses {
+		args = append(args, status)
+	}
+	args = append(args, from.UTC(), to.UTC())
+	for _, status := range openStatuses {
+		args = append(args, status)
+	}
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest departments: %w", err)
+	}
+	defer rows.Close()
+
+	var departments []*DigestDepartment
+	for rows.Next() {
+		department := &DigestDepartment{}
+		if err := rows.Scan(&department.Name, &department.New, &department.Open, &department.Overdue); err != nil {
+			return nil, fmt.Errorf("failed to scan digest department: %w", err)
+		}
+		departments = append(departments, department)
+	}
+
+	return departments, nil
+}
+
+// ClaimDigest отмечает, что сводка kind за день day отправляется; false - она уже отправлена
+func (d *Database) ClaimDigest(kind string, day time.Time, recipients []string) (bool, error) {
+	query := `
+	INSERT IGNORE INTO digest_log (kind, day, recipients)
+	VALUES (?, ?, ?)
+	`
+
+	result, err := d.db.Exec(query, kind, day.Format(holidayLayout), strings.Join(recipients, ", "))
+	if err != nil {
+		return false, fmt.Errorf("failed to claim digest: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ReleaseDigest снимает отметку, чтобы неотправленная сводка ушла при следующей проверке
+func (d *Database) ReleaseDigest(kind string, day time.Time) error {
+	if _, err := d.db.Exec(`DELETE FROM digest_log WHERE kind = ? AND day = ?`, kind, day.Format(holidayLayout)); err != nil {
+		return fmt.Errorf("failed to release digest: %w", err)
+	}
+	return nil
+}
+
+// BuildDigest собирает сводку: новые обращения за [from, to), все открытые и просроченные на момент to
+func (d *Database) BuildDigest(kind string, from, to time.Time) (*Digest, error) {
+	digest := &Digest{Kind: kind, From: from, To: to}
+
+	var err error
+	digest.New, digest.NewTotal, err = d.ListFeedbacks(FeedbackFilter{From: from, To: to}, digestListLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	digest.Unresolved, digest.UnresolvedTotal, err = d.ListFeedbacks(FeedbackFilter{Statuses: openStatuses}, digestListLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	overdue, err := d.ListDueFeedbacks(to)
+	if err != nil {
+		return nil, err
+	}
+	digest.OverdueTotal = len(overdue)
+	if len(overdue) > digestListLimit {
+		overdue = overdue[:digestListLimit]
+	}
+	digest.Overdue = overdue
+
+	digest.Departments, err = d.GetDigestDepartments(from, to, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return digest, nil
+}
+
+// digestPeriod - период сводки, заканчивающийся в to: сутки или неделя
+func digestPeriod(kind string, to time.Time) time.Time {
+	if kind == DigestWeekly {
+		return to.AddDate(0, 0, -7)
+	}
+	return to.AddDate(0, 0, -1)
+}
+
+// digestRecipients - адреса из DIGEST_RECIPIENTS через запятую, по умолчанию EMAIL_TO
+func digestRecipients() []string {
+	var recipients []string
+	for _, address := range strings.Split(getEnv("DIGEST_RECIPIENTS", getEnv("EMAIL_TO", "")), ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			recipients = append(recipients, address)
+		}
+	}
+	return recipients
+}
+
+// digestSchedule читает DIGEST_SCHEDULE ("daily", "weekly" или "daily,weekly"); пусто - сводки выключены
+func digestSchedule() []string {
+	var kinds []string
+	for _, kind := range strings.Split(getEnv("DIGEST_SCHEDULE", ""), ",") {
+		switch kind = strings.TrimSpace(kind); kind {
+		case DigestDaily, DigestWeekly:
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// digestDue возвращает момент, за который пора отправить сводку kind в день now:
+// DIGEST_TIME (по умолчанию 08:00), для недельной - только в DIGEST_WEEKDAY (1 - понедельник)
+func digestDue(kind string, now time.Time) (time.Time, bool) {
+	clock, err := parseClock(getEnv("DIGEST_TIME", "08:00"))
+	if err != nil {
+		clock = 8 * time.Hour
+	}
+	if kind == DigestWeekly && now.Weekday() != time.Weekday(getEnvAsInt("DIGEST_WEEKDAY", 1)%7) {
+		return time.Time{}, false
+	}
+
+	due := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).Add(clock)
+	return due, !now.Before(due)
+}
+
+// digestItem - строка обращения в сводке
+type digestItem struct {
+	Ticket     string
+	Type       string
+	Status     string
+	Department string
+	Date       string
+	Due        string
+	Message    string
+}
+
+func digestItems(lang string, feedbacks []*Feedback) []digestItem {
+	items := make([]digestItem, 0, len(feedbacks))
+	for _, feedback := range feedbacks {
+		item := digestItem{
+			Ticket:     ticketLabel(feedback),
+			Type:       getTypeDisplayName(lang, feedback.Type),
+			Status:     statusDisplayName(lang, feedback.Status),
+			Department: feedback.DepartmentName,
+			Date:       inTimezone(feedback.CreatedAt).Format("02.01.2006 15:04"),
+			Message:    truncateRunes(strings.Join(strings.Fields(RedactPII(lang, feedback.Message)), " "), 160),
+		}
+		if item.Department == "" {
+			item.Department = Translate(lang, "email.not_specified")
+		}
+		if !feedback.DueAt.IsZero() {
+			item.Due = inTimezone(feedback.DueAt).Format("02.01.2006 15:04")
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// digestSection - раздел сводки со списком и общим числом обращений
+type digestSection struct {
+	Title string
+	Items []digestItem
+	More  string
+	Empty string
+}
+
+func newDigestSection(lang, key string, feedbacks []*Feedback, total int) digestSection {
+	section := digestSection{
+		Title: Translate(lang, key, total),
+		Items: digestItems(lang, feedbacks),
+	}
+	if total > len(feedbacks) {
+		section.More = Translate(lang, "digest.more", total-len(feedbacks))
+	}
+	if total == 0 {
+		section.Empty = Translate(lang, "digest.none")
+	}
+	return section
+}
+
+// digestView - данные для шаблонов письма на языке EMAIL_LANGUAGE
+type digestView struct {
+	Title       string
+	Period      string
+	Sections    []digestSection
+	ByDept      string
+	DeptHeaders []string
+	Departments []*DigestDepartment
+	DueLabel    string
+	Footer      string
+}
+
+func newDigestView(lang string, digest *Digest) digestView {
+	return digestView{
+		Title: Translate(lang, "digest.title."+digest.Kind),
+		Period: Translate(lang, "digest.period",
+			inTimezone(digest.From).Format("02.01.2006 15:04"), inTimezone(digest.To).Format("02.01.2006 15:04")),
+		Sections: []digestSection{
+			newDigestSection(lang, "digest.new", digest.New, digest.NewTotal),
+			newDigestSection(lang, "digest.unresolved", digest.Unresolved, digest.UnresolvedTotal),
+			newDigestSection(lang, "digest.overdue", digest.Overdue, digest.OverdueTotal),
+		},
+		ByDept: Translate(lang, "digest.by_department"),
+		DeptHeaders: []string{
+			Translate(lang, "digest.column.department"),
+			Translate(lang, "digest.column.new"),
+			Translate(lang, "digest.column.open"),
+			Translate(lang, "digest.column.overdue"),
+		},
+		Departments: digest.Departments,
+		DueLabel:    Translate(lang, "digest.due"),
+		Footer:      Translate(lang, "digest.footer"),
+	}
+}
+
+var digestHTMLTemplate = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #222;">
+<h2>{{.Title}}</h2>
+<p style="color: #666;">{{.Period}}</p>
+{{range .Sections}}
+<h3>{{.Title}}</h3>
+{{if .Empty}}<p style="color: #666;">{{.Empty}}</p>{{end}}
+{{if .Items}}<table cellpadding="4" cellspacing="0" border="1" style="border-collapse: collapse; font-size: 13px;">
+{{range .Items}}<tr>
+<td><b>{{.Ticket}}</b></td>
+<td>{{.Type}}</td>
+<td>{{.Status}}</td>
+<td>{{.Department}}</td>
+<td>{{.Date}}{{if .Due}}<br><span style="color: #c00;">{{$.DueLabel}} {{.Due}}</span>{{end}}</td>
+<td>{{.Message}}</td>
+</tr>
+{{end}}</table>{{end}}
+{{if .More}}<p style="color: #666;">{{.More}}</p>{{end}}
+{{end}}
+{{if .Departments}}<h3>{{.ByDept}}</h3>
+<table cellpadding="4" cellspacing="0" border="1" style="border-collapse: collapse; font-size: 13px;">
+<tr>{{range .DeptHeaders}}<th>{{.}}</th>{{end}}</tr>
+{{range .Departments}}<tr><td>{{if .Name}}{{.Name}}{{else}}—{{end}}</td><td>{{.New}}</td><td>{{.Open}}</td><td>{{.Overdue}}</td></tr>
+{{end}}</table>{{end}}
+<p style="color: #999; font-size: 12px;">{{.Footer}}</p>
+</body>
+</html>
+`))
+
+// renderDigest возвращает тему, текстовую и HTML-версии письма со сводкой
+func renderDigest(lang string, digest *Digest) (string, string, string, error) {
+	view := newDigestView(lang, digest)
+
+	var text strings.Builder
+	text.WriteString(view.Title + "\n" + view.Period + "\n")
+	for _, section := range view.Sections {
+		text.WriteString("\n" + section.Title + "\n")
+		if section.Empty != "" {
+			text.WriteString(section.Empty + "\n")
+		}
+		for _, item := range section.Items {
+			text.WriteString(fmt.Sprintf("• %s · %s · %s · %s · %s", item.Ticket, item.Type, item.Status, item.Department, item.Date))
+			if item.Due != "" {
+				text.WriteString(" · " + view.DueLabel + " " + item.Due)
+			}
+			text.WriteString("\n  " + item.Message + "\n")
+		}
+		if section.More != "" {
+			text.WriteString(section.More + "\n")
+		}
+	}
+	if len(view.Departments) > 0 {
+		text.WriteString("\n" + view.ByDept + "\n" + strings.Join(view.DeptHeaders, " / ") + "\n")
+		for _, department := range view.Departments {
+			name := department.Name
+			if name == "" {
+				name = "—"
+			}
+			text.WriteString(fmt.Sprintf("• %s: %d / %d / %d\n", name, department.New, department.Open, department.Overdue))
+		}
+	}
+	text.WriteString("\n---\n" + view.Footer)
+
+	var html bytes.Buffer
+	if err := digestHTMLTemplate.Execute(&html, view); err != nil {
+		return "", "", "", fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	subject := Translate(lang, "digest.subject."+digest.Kind, inTimezone(digest.To).Format("02.01.2006"))
+	return subject, text.String(), html.String(), nil
+}
+
+// SendDigest собирает сводку kind за период, заканчивающийся в to, и отправляет ее на recipients
+func SendDigest(db *Database, email *EmailService, kind string, to time.Time, recipients []string) error {
+	digest, err := db.BuildDigest(kind, digestPeriod(kind, to), to)
+	if err != nil {
+		return err
+	}
+
+	subject, text, html, err := renderDigest(email.lang, digest)
+	if err != nil {
+		return err
+	}
+	return email.SendAlternative(recipients, subject, text, html)
+}
+
+// runDigests раз в минуту проверяет расписание DIGEST_SCHEDULE и отправляет сводки.
+// Отправка отмечается в digest_log, поэтому перезапуск или второй экземпляр не дублируют письма.
+func (a *App) runDigests() {
+	kinds := digestSchedule()
+	recipients := digestRecipients()
+	if len(kinds) == 0 || len(recipients) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		now := nowInTimezone()
+		for _, kind := range kinds {
+			due, ok := digestDue(kind, now)
+			if !ok {
+				continue
+			}
+
+			claimed, err := a.database.ClaimDigest(kind, due, recipients)
+			if err != nil {
+				a.logger.Error("Failed to claim digest: ", err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+
+			if err := SendDigest(a.database, a.email, kind, due, recipients); err != nil {
+				a.logger.Error("Failed to send digest: ", err)
+				if err := a.database.ReleaseDigest(kind, due); err != nil {
+					a.logger.Error("Failed to release digest: ", err)
+				}
+				continue
+			}
+			a.logger.Infof("Sent %s digest to %s", kind, strings.Join(recipients, ", "))
+		}
+	}
+}
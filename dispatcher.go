@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// UpdateDispatcher раздает обновления пулу воркеров.
+// Обновления одного пользователя всегда попадают в один и тот же воркер,
+// поэтому обрабатываются строго по порядку, а разные пользователи - параллельно.
+type UpdateDispatcher struct {
+	queues  []chan tgbotapi.Update
+	handler func(tgbotapi.Update)
+	logger  *logrus.Logger
+	wg      sync.WaitGroup
+
+	mu      sync.RWMutex
+	stopped bool
+}
+
+func NewUpdateDispatcher(workers, queueSize int, handler func(tgbotapi.Update), logger *logrus.Logger) *UpdateDispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	d := &UpdateDispatcher{
+		queues:  make([]chan tgbotapi.Update, workers),
+		handler: handler,
+		logger:  logger,
+	}
+
+	for i := range d.queues {
+		d.queues[i] = make(chan tgbotapi.Update, queueSize)
+		d.wg.Add(1)
+		go d.worker(i, d.queues[i])
+	}
+
+	return d
+}
+
+// Dispatch ставит обновление в очередь воркера пользователя.
+// Если очередь заполнена, вызов блокируется до освобождения места,
+// тем самым притормаживая чтение новых обновлений из Telegram.
+func (d *UpdateDispatcher) Dispatch(update tgbotapi.Update) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.stopped {
+		return fmt.Errorf("dispatcher is stopped")
+	}
+
+	userID := updateUserID(update)
+	queue := d.queues[int(uint64(userID)%uint64(len(d.queues)))]
+
+	select {
+	case queue <- update:
+	default:
+		d.logger.Warnf("Update queue is full for user %d, waiting for free slot", userID)
+		queue <- update
+	}
+
+	return nil
+}
+
+// Stop прекращает прием обновлений и дожидается обработки уже поставленных в очередь
+func (d *UpdateDispatcher) Stop() {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	d.stopped = true
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.mu.Unlock()
+
+	d.wg.Wait()
+}
+
+func (d *UpdateDispatcher) worker(id int, queue <-chan tgbotapi.Update) {
+	defer d.wg.Done()
+
+	for update := range queue {
+		d.handle(id, update)
+	}
+}
+
+func (d *UpdateDispatcher) handle(workerID int, update tgbotapi.Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			d.logger.Errorf("Worker %d recovered from panic while handling update %d: %v", workerID, update.UpdateID, r)
+		}
+	}()
+
+	d.handler(update)
+}
+
+// updateUserID возвращает ID пользователя, от которого пришло обновление
+func updateUserID(update tgbotapi.Update) int64 {
+	if user := update.SentFrom(); user != nil {
+		return user.ID
+	}
+	if chat := update.FromChat(); chat != nil {
+		return chat.ID
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+func messageUpdate(updateID int, userID int64) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: updateID,
+		Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: userID},
+			Chat: &tgbotapi.Chat{ID: userID},
+		},
+	}
+}
+
+func TestUpdateDispatcherPerUserOrder(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	tests := []struct {
+		name      string
+		workers   int
+		queueSize int
+		users     int
+		perUser   int
+	}{
+		{"single worker", 1, 1, 3, 20},
+		{"more users than workers", 4, 2, 10, 30},
+		{"more workers than users", 8, 16, 3, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			handled := make(map[int64][]int)
+			handler := func(update tgbotapi.Update) {
+				// Небольшая задержка перемешивает обработку разных воркеров
+				if update.UpdateID%7 == 0 {
+					time.Sleep(time.Millisecond)
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				userID := update.Message.From.ID
+				handled[userID] = append(handled[userID], update.UpdateID)
+			}
+
+			dispatcher := NewUpdateDispatcher(tt.workers, tt.queueSize, handler, logger)
+			updateID := 0
+			for i := 0; i < tt.perUser; i++ {
+				for user := 1; user <= tt.users; user++ {
+					updateID++
+					if err := dispatcher.Dispatch(messageUpdate(updateID, int64(user))); err != nil {
+						t.Fatalf("Dispatch: %v", err)
+					}
+				}
+			}
+			dispatcher.Stop()
+
+			for user := int64(1); user <= int64(tt.users); user++ {
+				ids := handled[user]
+				if len(ids) != tt.perUser {
+					t.Fatalf("user %d: handled %d updates, want %d", user, len(ids), tt.perUser)
+				}
+				for i := 1; i < len(ids); i++ {
+					if ids[i] <= ids[i-1] {
+						t.Fatalf("user %d: update %d handled after %d", user, ids[i], ids[i-1])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateDispatcherRecoversAndStops(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	var mu sync.Mutex
+	var handled []int
+	dispatcher := NewUpdateDispatcher(2, 4, func(update tgbotapi.Update) {
+		if update.UpdateID == 1 {
+			panic("handler failure")
+		}
+		mu.Lock()
+		handled = append(handled, update.UpdateID)
+		mu.Unlock()
+	}, logger)
+
+	for id := 1; id <= 3; id++ {
+		if err := dispatcher.Dispatch(messageUpdate(id, 42)); err != nil {
+			t.Fatalf("Dispatch: %v", err)
+		}
+	}
+	dispatcher.Stop()
+
+	if len(handled) != 2 || handled[0] != 2 || handled[1] != 3 {
+		t.Fatalf("handled %v after panic, want [2 3]", handled)
+	}
+	if err := dispatcher.Dispatch(messageUpdate(4, 42)); err == nil {
+		t.Fatal("Dispatch after Stop succeeded, want error")
+	}
+}
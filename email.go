@@ -2,26 +2,33 @@ package main
 
 import (
 	"fmt"
-	"time"
+	"io"
+	"strings"
 
 	"gopkg.in/gomail.v2"
 )
 
 type EmailService struct {
-	fromEmail    string
-	fromPassword string
-	toEmail      string
-	smtpHost     string
-	smtpPort     int
+	fromEmail     string
+	fromPassword  string
+	toEmail       string
+	smtpHost      string
+	smtpPort      int
+	attachments   AttachmentStorage
+	maxAttachSize int64
+	lang          string
 }
 
-func NewEmailService() *EmailService {
+func NewEmailService(attachments AttachmentStorage) *EmailService {
 	return &EmailService{
-		fromEmail:    getEnv("EMAIL_FROM", ""),
-		fromPassword: getEnv("EMAIL_PASSWORD", ""),
-		toEmail:      getEnv("EMAIL_TO", ""),
-		smtpHost:     getEnv("SMTP_HOST", "smtp.gmail.com"),
-		smtpPort:     getEnvAsInt("SMTP_PORT", 587),
+		fromEmail:     getEnv("EMAIL_FROM", ""),
+		fromPassword:  getEnv("EMAIL_PASSWORD", ""),
+		toEmail:       getEnv("EMAIL_TO", ""),
+		smtpHost:      getEnv("SMTP_HOST", "smtp.gmail.com"),
+		smtpPort:      getEnvAsInt("SMTP_PORT", 587),
+		attachments:   attachments,
+		maxAttachSize: int64(getEnvAsInt("EMAIL_ATTACHMENTS_MAX_SIZE_MB", 20)) << 20,
+		lang:          staffLanguage(),
 	}
 }
 
@@ -31,57 +38,62 @@ func (e *EmailService) SendFeedbackEmail(feedback *Feedback) error {
 	}
 
 	// Используем текущее время в правильном часовом поясе
-	timezone := getEnv("TIMEZONE", "Asia/Almaty")
-
-	// Используем фиксированное смещение для Asia/Almaty (UTC+5)
-	var currentTime time.Time
-	if timezone == "Asia/Almaty" {
-		// Создаем фиксированное смещение UTC+5
-		loc := time.FixedZone("Asia/Almaty", 5*60*60) // +5 часов в секундах
-		currentTime = time.Now().In(loc)
-	} else {
-		// Пытаемся загрузить часовой пояс
-		loc, err := time.LoadLocation(timezone)
-		if err != nil {
-			// Если не удалось загрузить часовой пояс, используем UTC
-			loc = time.UTC
-		}
-		currentTime = time.Now().In(loc)
-	}
+	currentTime := nowInTimezone()
+
+	// Письма уходят открытым текстом, поэтому ИИН, карты, телефоны и email из текста пациента скрываем
+	redacted := redactFeedback(e.lang, feedback)
 
 	// Формируем тему письма
-	subject := fmt.Sprintf("Новое обращение: %s", getTypeDisplayName(feedback.Type))
+	subject := Translate(e.lang, "email.subject", getTypeDisplayName(e.lang, feedback.Type), feedback.TicketCode)
+
+	// Формируем тело письма на языке EMAIL_LANGUAGE
+	body := Translate(e.lang, "email.body",
+		e.senderSummary(feedback),
+		getTypeDisplayName(e.lang, feedback.Type),
+		e.ratingLine(feedback.Rating),
+		e.orNotSpecified(feedback.DepartmentName),
+		e.orNotSpecified(locationDisplayName(e.lang, feedback.Location)),
+		currentTime.Format("02.01.2006 15:04:05"),
+		redacted.Message,
+		e.answersSummary(redacted.Answers),
+		e.attachmentsSummary(feedback.Attachments),
+	)
 
-	// Формируем тело письма
-	body := fmt.Sprintf(`🏥 Новое обращение в системе обратной связи
+	m := gomail.NewMessage()
+	m.SetHeader("From", e.fromEmail)
+	m.SetHeader("To", e.toEmail)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/plain", body)
+	e.attachFiles(m, feedback.Attachments)
+
+	d := gomail.NewDialer(e.smtpHost, e.smtpPort, e.fromEmail, e.fromPassword)
 
-👤 Отправитель:
-• Имя: %s %s
-• Username: @%s
-• ID: %d
+	if err := d.DialAndSend(m); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
 
-📝 Тип обращения: %s
-📅 Дата: %s
+	return nil
+}
 
-💬 Сообщение:
-%s
+// Send отправляет служебное текстовое письмо (например, эскалацию) на указанные адреса
+func (e *EmailService) Send(to []string, subject, body string) error {
+	return e.SendAlternative(to, subject, body, "")
+}
 
----
-Это автоматическое уведомление от системы обратной связи больницы.`,
-		feedback.FirstName,
-		feedback.LastName,
-		feedback.Username,
-		feedback.UserID,
-		getTypeDisplayName(feedback.Type),
-		currentTime.Format("02.01.2006 15:04:05"),
-		feedback.Message,
-	)
+// SendAlternative отправляет письмо с текстовой и, если html не пуст, HTML-версией
+func (e *EmailService) SendAlternative(to []string, subject, text, html string) error {
+	if e.fromEmail == "" || e.fromPassword == "" || len(to) == 0 {
+		return fmt.Errorf("email configuration is incomplete")
+	}
 
 	m := gomail.NewMessage()
 	m.SetHeader("From", e.fromEmail)
-	m.SetHeader("To", e.toEmail)
+	m.SetHeader("To", to...)
 	m.SetHeader("Subject", subject)
-	m.SetBody("text/plain", body)
+	m.SetBody("text/plain", text)
+	if html != "" {
+		m.AddAlternative("text/html", html)
+	}
 
 	d := gomail.NewDialer(e.smtpHost, e.smtpPort, e.fromEmail, e.fromPassword)
 
@@ -91,3 +103,87 @@ func (e *EmailService) SendFeedbackEmail(feedback *Feedback) error {
 
 	return nil
 }
+
+// answersSummary перечисляет ответы на дополнительные вопросы анкеты
+func (e *EmailService) answersSummary(answers []*FeedbackAnswer) string {
+	if len(answers) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n" + Translate(e.lang, "email.answers") + "\n")
+	for _, answer := range answers {
+		sb.WriteString(fmt.Sprintf("• %s: %s\n", answer.Question, answer.Label))
+	}
+	return sb.String()
+}
+
+// attachmentsSummary перечисляет вложения обращения в теле письма
+func (e *EmailService) attachmentsSummary(attachments []*Attachment) string {
+	if len(attachments) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n" + Translate(e.lang, "email.attachments") + "\n")
+	for _, attachment := range attachments {
+		sb.WriteString(Translate(e.lang, "email.attachment", attachment.FileName, attachment.Kind, attachment.Size/1024) + "\n")
+	}
+	return sb.String()
+}
+
+// attachFiles прикрепляет вложения к письму, пока не превышен общий лимит размера
+func (e *EmailService) attachFiles(m *gomail.Message, attachments []*Attachment) {
+	if e.attachments == nil {
+		return
+	}
+
+	var total int64
+	for _, attachment := range attachments {
+		if total+attachment.Size > e.maxAttachSize {
+			continue
+		}
+		total += attachment.Size
+
+		key := attachment.StoragePath
+		m.Attach(attachment.FileName, gomail.SetCopyFunc(func(w io.Writer) error {
+			r, err := e.attachments.Open(key)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+
+			_, err = io.Copy(w, r)
+			return err
+		}))
+	}
+}
+
+// ratingLine - строка с оценкой отзыва или пустая строка, если оценки нет
+func (e *EmailService) ratingLine(rating int) string {
+	if rating == 0 {
+		return ""
+	}
+	return Translate(e.lang, "email.rating", ratingStars(rating), rating) + "\n"
+}
+
+func (e *EmailService) orNotSpecified(name string) string {
+	if name == "" {
+		return Translate(e.lang, "email.not_specified")
+	}
+	return name
+}
+
+// senderSummary описывает отправителя; личность автора анонимного обращения не раскрывается
+func (e *EmailService) senderSummary(feedback *Feedback) string {
+	if feedback.Anonymous {
+		return Translate(e.lang, "email.sender_anonymous")
+	}
+	return Translate(e.lang, "email.sender",
+		feedback.FirstName,
+		feedback.LastName,
+		feedback.Username,
+		feedback.UserID,
+		e.orNotSpecified(feedback.Phone),
+	)
+}
@@ -0,0 +1,446 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Участники цепочки эскалации
+const (
+	EscalateAssignee       = "assignee"
+	EscalateDepartmentHead = "department_head"
+	EscalateChiefPhysician = "chief_physician"
+)
+
+// EscalationStep - ступень эскалации: кого уведомить и когда относительно срока ответа.
+// Отрицательное смещение - предупреждение до истечения срока.
+type EscalationStep struct {
+	Role   string
+	Offset time.Duration
+}
+
+// Escalation - запись о выполненной эскалации обращения
+type Escalation struct {
+	ID         int64     `json:"id"`
+	FeedbackID int64     `json:"feedback_id"`
+	Level      int       `json:"level"`
+	Role       string    `json:"role"`
+	Recipients string    `json:"recipients"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// slaWatchedStatuses - статусы, в которых срок ответа идет; в awaiting_patient ход за пациентом
+var slaWatchedStatuses = []string{StatusNew, StatusAcknowledged, StatusInProgress, StatusReopened}
+
+func isSLAWatched(status string) bool {
+	for _, s := range slaWatchedStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+const defaultEscalationChain = "assignee:-4h,department_head:0h,chief_physician:24h"
+
+// escalationChain читает SLA_ESCALATION_CHAIN, например "assignee:-4h,department_head:0h,chief_physician:24h".
+// Ступени упорядочены по смещению, чтобы уровень эскалации рос вместе со временем.
+func escalationChain() []EscalationStep {
+	steps := parseEscalationChain(getEnv("SLA_ESCALATION_CHAIN", defaultEscalationChain))
+	if len(steps) == 0 {
+		steps = parseEscalationChain(defaultEscalationChain)
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Offset < steps[j].Offset })
+	return steps
+}
+
+func parseEscalationChain(value string) []EscalationStep {
+	var steps []EscalationStep
+	for _, item := range strings.Split(value, ",") {
+		role, rawOffset, _ := strings.Cut(strings.TrimSpace(item), ":")
+		switch role {
+		case EscalateAssignee, EscalateDepartmentHead, EscalateChiefPhysician:
+		default:
+			continue
+		}
+
+		offset, err := time.ParseDuration(rawOffset)
+		if err != nil {
+			continue
+		}
+		steps = append(steps, EscalationStep{Role: role, Offset: offset})
+	}
+	return steps
+}
+
+// formatEscalationOffset описывает смещение ступени относительно срока: "-4h", "0h", "+24h"
+func formatEscalationOffset(offset time.Duration) string {
+	hours := strconv.FormatFloat(offset.Hours(), 'f', -1, 64) + "h"
+	if offset > 0 {
+		return "+" + hours
+	}
+	return hours
+}
+
+// ListDueFeedbacks возвращает обращения со сроком ответа раньше until, по которым еще идет работа
+func (d *Database) ListDueFeedbacks(until time.Time) ([]*Feedback, error) {
+	query := feedbackSelect + `
+	WHERE f.due_at IS NOT NULL AND f.due_at <= ? AND f.status IN (?` + strings.Repeat(", ?", len(slaWatchedStatuses)-1) + `)
+	ORDER BY f.due_at ASC
+	`
+
+	args := []interface{}{until.UTC()}
+	for _, status := range slaWatchedStatuses {
+		args = append(args, status)
+	}
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due feedbacks: %w", err)
+	}
+	defer rows.Close()
+
+	var feedbacks []*Feedback
+	for rows.Next() {
+		feedback, err := scanFeedback(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		feedbacks = append(feedbacks, feedback)
+	}
+
+	return feedbacks, nil
+}
+
+// ListFeedbacksWithoutDeadline возвращает открытые обращения без срока ответа, для которых
+// политики SLA его требуют, - созданные до появления сроков или до новой политики
+func (d *Database) ListFeedbacksWithoutDeadline(policies []*SLAPolicy) ([]*Feedback, error) {
+	scope, scopeArgs := slaScope(policies)
+	if scope == "" {
+		return nil, nil
+	}
+
+	query := feedbackSelect + `
+	WHERE f.due_at IS NULL AND f.status IN (?` + strings.Repeat(", ?", len(slaWatchedStatuses)-1) + `) AND ` + scope + `
+	ORDER BY f.created_at ASC
+	`
+
+	var args []interface{}
+	for _, status := range slaWatchedStatuses {
+		args = append(args, status)
+	}
+	args = append(args, scopeArgs...)
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feedbacks without deadline: %w", err)
+	}
+	defer rows.Close()
+
+	var feedbacks []*Feedback
+	for rows.Next() {
+		feedback, err := scanFeedback(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		feedbacks = append(feedbacks, feedback)
+	}
+
+	return feedbacks, nil
+}
+
+// LastEscalationLevel - номер последней выполненной ступени эскалации, 0 - эскалаций не было
+func (d *Database) LastEscalationLevel(feedbackID int64) (int, error) {
+	var level int
+	err := d.db.QueryRow(`SELECT COALESCE(MAX(level), 0) FROM feedback_escalations WHERE feedback_id = ?`, feedbackID).Scan(&level)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get escalation level: %w", err)
+	}
+	return level, nil
+}
+
+// SaveEscalation записывает эскалацию; false - эта ступень уже записана другим процессом
+func (d *Database) SaveEscalation(escalation *Escalation) (bool, error) {
+	query := `
+	INSERT IGNORE INTO feedback_escalations (feedback_id, level, role, recipients)
+	VALUES (?, ?, ?, ?)
+	`
+
+	result, err := d.db.Exec(query, escalation.FeedbackID, escalation.Level, escalation.Role, escalation.Recipients)
+	if err != nil {
+		return false, fmt.Errorf("failed to save escalation: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (d *Database) GetEscalations(feedbackID int64) ([]*Escalation, error) {
+	query := `
+	SELECT id, feedback_id, level, role, recipients, created_at
+	FROM feedback_escalations
+	WHERE feedback_id = ?
+	ORDER BY level ASC
+	`
+
+	rows, err := d.db.Query(query, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query escalations: %w", err)
+	}
+	defer rows.Close()
+
+	var escalations []*Escalation
+	for rows.Next() {
+		escalation := &Escalation{}
+		err := rows.Scan(
+			&escalation.ID,
+			&escalation.FeedbackID,
+			&escalation.Level,
+			&escalation.Role,
+			&escalation.Recipients,
+			&escalation.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan escalation: %w", err)
+		}
+		escalations = append(escalations, escalation)
+	}
+
+	return escalations, nil
+}
+
+// setDeadline рассчитывает срок ответа на новое обращение по политикам SLA
+func (t *TelegramBot) setDeadline(feedback *Feedback) {
+	dueAt, err := t.database.FeedbackDeadline(feedback, time.Now())
+	if err != nil {
+		t.logger.Error("Failed to calculate feedback deadline: ", err)
+		return
+	}
+	feedback.DueAt = dueAt
+}
+
+// backfillDeadlines проставляет сроки открытым обращениям, созданным до появления сроков ответа.
+// Политики и праздники загружаются один раз на всю проверку.
+func (t *TelegramBot) backfillDeadlines() {
+	policies, err := t.database.ListSLAPolicies()
+	if err != nil {
+		t.logger.Error("Failed to list sla policies: ", err)
+		return
+	}
+	pending, err := t.database.ListFeedbacksWithoutDeadline(policies)
+	if err != nil {
+		t.logger.Error("Failed to get feedbacks without deadline: ", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	// Обращения отсортированы по дате создания, первое - самое старое
+	holidays, err := t.database.ListHolidays(pending[0].CreatedAt.AddDate(0, 0, -1))
+	if err != nil {
+		t.logger.Error("Failed to list holidays: ", err)
+		return
+	}
+	calendar := NewBusinessCalendarFromEnv(holidays)
+
+	for _, feedback := range pending {
+		dueAt := feedbackDeadline(policies, calendar, feedback, feedback.CreatedAt)
+		if dueAt.IsZero() {
+			continue
+		}
+		if err := t.database.SetFeedbackDeadline(feedback.ID, dueAt); err != nil {
+			t.logger.Error("Failed to set feedback deadline: ", err)
+		}
+	}
+}
+
+// checkDeadlines проставляет сроки старым обращениям и эскалирует обращения,
+// у которых наступила очередная ступень цепочки. Вызывается планировщиком из App.
+func (t *TelegramBot) checkDeadlines() {
+	t.backfillDeadlines()
+
+	chain := escalationChain()
+	var earliest time.Duration
+	for _, step := range chain {
+		if step.Offset < earliest {
+			earliest = step.Offset
+		}
+	}
+
+	now := time.Now()
+	feedbacks, err := t.database.ListDueFeedbacks(now.Add(-earliest))
+	if err != nil {
+		t.logger.Error("Failed to get due feedbacks: ", err)
+		return
+	}
+
+	for _, feedback := range feedbacks {
+		last, err := t.database.LastEscalationLevel(feedback.ID)
+		if err != nil {
+			t.logger.Error("Failed to get escalation level: ", err)
+			continue
+		}
+
+		// За одну проверку - не больше одной ступени: если наступило сразу несколько
+		// (например, у старого обращения срок уже истек), следующие уйдут при очередных проверках
+		if last >= len(chain) {
+			continue
+		}
+		if step := chain[last]; !now.Before(feedback.DueAt.Add(step.Offset)) {
+			t.escalate(feedback, last+1, step)
+		}
+	}
+}
+
+// escalationRecipient - кого уведомить: Telegram ID и/или адрес почты
+type escalationRecipient struct {
+	UserID int64
+	Email  string
+}
+
+// escalationRecipients находит получателей для ступени цепочки.
+// Если никого нет (не назначен ответственный, нет заведующего), уведомляется чат сотрудников.
+func (t *TelegramBot) escalationRecipients(feedback *Feedback, role string) []escalationRecipient {
+	var recipients []escalationRecipient
+
+	switch role {
+	case EscalateAssignee:
+		if feedback.AssigneeID != 0 {
+			recipient := escalationRecipient{UserID: feedback.AssigneeID}
+			if admin := t.admin(feedback.AssigneeID); admin != nil {
+				recipient.Email = admin.Email
+			}
+			recipients = append(recipients, recipient)
+		}
+	case EscalateDepartmentHead:
+		admins, err := t.database.ListAdmins()
+		if err != nil {
+			t.logger.Error("Failed to list admins: ", err)
+		}
+		for _, admin := range admins {
+			if admin.Role == RoleDepartmentHead && feedback.DepartmentID != 0 && admin.DepartmentID == feedback.DepartmentID {
+				recipients = append(recipients, escalationRecipient{UserID: admin.UserID, Email: admin.Email})
+			}
+		}
+	case EscalateChiefPhysician:
+		chief := escalationRecipient{
+			UserID: getEnvAsInt64("CHIEF_PHYSICIAN_USER_ID", 0),
+			Email:  getEnv("CHIEF_PHYSICIAN_EMAIL", ""),
+		}
+		if chief.UserID != 0 || chief.Email != "" {
+			recipients = append(recipients, chief)
+		}
+	}
+
+	if len(recipients) == 0 {
+		recipients = append(recipients, escalationRecipient{UserID: staffChatID()})
+	}
+	return recipients
+}
+
+// escalate уведомляет участников ступени level и записывает эскалацию
+func (t *TelegramBot) escalate(feedback *Feedback, level int, step EscalationStep) {
+	recipients := t.escalationRecipients(feedback, step.Role)
+
+	var names []string
+	for _, recipient := range recipients {
+		if recipient.UserID != 0 {
+			names = append(names, strconv.FormatInt(recipient.UserID, 10))
+		}
+		if recipient.Email != "" {
+			names = append(names, recipient.Email)
+		}
+	}
+
+	escalation := &Escalation{
+		FeedbackID: feedback.ID,
+		Level:      level,
+		Role:       step.Role,
+		Recipients: strings.Join(names, ", "),
+	}
+	saved, err := t.database.SaveEscalation(escalation)
+	if err != nil {
+		t.logger.Error("Failed to save escalation: ", err)
+		return
+	}
+	if !saved {
+		return
+	}
+
+	t.loadFeedbackDetails(feedback)
+	// Предупреждение, дошедшее после срока, отправляется как уведомление о просрочке
+	key := "sla.overdue"
+	if time.Now().Before(feedback.DueAt) {
+		key = "sla.warning"
+	}
+
+	var emails []string
+	for _, recipient := range recipients {
+		if recipient.Email != "" {
+			emails = append(emails, recipient.Email)
+		}
+		if recipient.UserID == 0 {
+			continue
+		}
+
+		lang := t.lang(recipient.UserID)
+		text := Translate(lang, key, ticketLabel(feedback), inTimezone(feedback.DueAt).Format("02.01.2006 15:04")) +
+			"\n" + Translate(lang, "sla.level", level, Translate(lang, "sla.role."+step.Role)) +
+			"\n\n" + feedbackCardText(lang, feedback)
+
+		msg := tgbotapi.NewMessage(recipient.UserID, truncateRunes(text, 4000))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "assign.button.open"), fmt.Sprintf("inbox:v:%d:0:", feedback.ID)),
+			),
+		)
+		if _, err := t.bot.Send(msg); err != nil {
+			t.logger.Error("Failed to send escalation: ", err)
+		}
+	}
+
+	if len(emails) > 0 {
+		lang := t.email.lang
+		subject := Translate(lang, "sla.email_subject", ticketLabel(feedback))
+		body := Translate(lang, key, ticketLabel(feedback), inTimezone(feedback.DueAt).Format("02.01.2006 15:04")) +
+			"\n" + Translate(lang, "sla.level", level, Translate(lang, "sla.role."+step.Role)) +
+			"\n\n" + feedbackCardText(lang, feedback)
+		if err := t.email.Send(emails, subject, body); err != nil {
+			t.logger.Error("Failed to send escalation email: ", err)
+		}
+	}
+
+	t.logger.Infof("Feedback %s escalated to level %d (%s)", ticketLabel(feedback), level, step.Role)
+}
+
+// escalationText описывает выполненные эскалации для подробного просмотра обращения
+func escalationText(lang string, escalations []*Escalation) string {
+	var sb strings.Builder
+	sb.WriteString(Translate(lang, "sla.escalations"))
+	for _, escalation := range escalations {
+		sb.WriteString(fmt.Sprintf("\n%s · %s",
+			inTimezone(escalation.CreatedAt).Format("02.01.2006 15:04"),
+			Translate(lang, "sla.level", escalation.Level, Translate(lang, "sla.role."+escalation.Role)),
+		))
+	}
+	return sb.String()
+}
+
+// runEscalations периодически проверяет сроки ответа на обращения (SLA_CHECK_INTERVAL)
+func (a *App) runEscalations() {
+	ticker := time.NewTicker(getEnvAsDuration("SLA_CHECK_INTERVAL", 5*time.Minute))
+	defer ticker.Stop()
+
+	for range ticker.C {
+		a.bot.checkDeadlines()
+	}
+}
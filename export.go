@@ -0,0 +1,724 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Форматы выгрузки обращений
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+)
+
+// exportDocumentLimit - максимальный размер файла, который бот может отправить в Telegram
+const exportDocumentLimit = 50 << 20
+
+// errExportTooLarge - выгрузка не помещается в exportDocumentLimit
+var errExportTooLarge = errors.New("export is too large")
+
+// limitedBuffer накапливает выгрузку для отправки в Telegram и прерывает ее
+// с errExportTooLarge, как только файл перерастает limit
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, errExportTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+// xlsxCellLimit - максимальная длина текста в ячейке Excel
+const xlsxCellLimit = 32767
+
+// exportWriteTimeout - сколько может идти потоковая выгрузка по HTTP; общий WriteTimeout сервера для нее слишком мал
+const exportWriteTimeout = 10 * time.Minute
+
+var errExportUsage = errors.New("invalid export arguments")
+
+// ExportOptions - что выгружать и в каком виде
+type ExportOptions struct {
+	Format string
+	Filter FeedbackFilter
+	Lang   string
+	// MaskPersonal скрывает данные автора и идентификаторы в тексте (см. RedactPII):
+	// роль сотрудника без PermViewPersonalData
+	MaskPersonal bool
+}
+
+// exportPageSize - сколько обращений выгрузка читает из базы за один запрос
+const exportPageSize = 500
+
+// StreamFeedbacks передает в fn обращения по фильтру по одному, от старых к новым, вместе с ответами анкет.
+// Обращения читаются страницами по exportPageSize, вся выборка в память не загружается.
+func (d *Database) StreamFeedbacks(filter FeedbackFilter, fn func(*Feedback) error) error {
+	where, args := filter.where()
+
+	var last *Feedback
+	for {
+		pageWhere, pageArgs := where, append([]interface{}{}, args...)
+		if last != nil {
+			cursor := "(f.created_at > ? OR (f.created_at = ? AND f.id > ?))"
+			if pageWhere == "" {
+				pageWhere = "WHERE " + cursor + "\n"
+			} else {
+				pageWhere = strings.TrimSuffix(pageWhere, "\n") + " AND " + cursor + "\n"
+			}
+			pageArgs = append(pageArgs, last.CreatedAt.UTC(), last.CreatedAt.UTC(), last.ID)
+		}
+		pageArgs = append(pageArgs, exportPageSize)
+
+		page, err := d.feedbackPage(feedbackSelect+pageWhere+`ORDER BY f.created_at ASC, f.id ASC LIMIT ?`, pageArgs)
+		if err != nil {
+			return err
+		}
+		if err := d.loadFeedbackAnswers(page); err != nil {
+			return err
+		}
+
+		for _, feedback := range page {
+			if err := fn(feedback); err != nil {
+				return err
+			}
+		}
+		if len(page) < exportPageSize {
+			return nil
+		}
+		last = page[len(page)-1]
+	}
+}
+
+// feedbackPage читает одну страницу обращений для StreamFeedbacks
+func (d *Database) feedbackPage(query string, args []interface{}) ([]*Feedback, error) {
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feedbacks: %w", err)
+	}
+	defer rows.Close()
+
+	var feedbacks []*Feedback
+	for rows.Next() {
+		feedback, err := scanFeedback(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		feedbacks = append(feedbacks, feedback)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read feedbacks: %w", err)
+	}
+	return feedbacks, nil
+}
+
+// exportColumn - столбец выгрузки; заголовок берется из каталога по ключу export.column.<key>
+type exportColumn struct {
+	key     string
+	numeric bool
+	value   func(lang string, feedback *Feedback) string
+}
+
+var exportColumns = []exportColumn{
+	{key: "id", numeric: true, value: func(lang string, f *Feedback) string { return strconv.FormatInt(f.ID, 10) }},
+	{key: "ticket", value: func(lang string, f *Feedback) string { return f.TicketCode }},
+	{key: "created_at", value: func(lang string, f *Feedback) string { return exportTime(f.CreatedAt) }},
+	{key: "updated_at", value: func(lang string, f *Feedback) string { return exportTime(f.UpdatedAt) }},
+	{key: "type", value: func(lang string, f *Feedback) string { return getTypeDisplayName(lang, f.Type) }},
+	{key: "status", value: func(lang string, f *Feedback) string { return exportStatusName(lang, f.Status) }},
+	{key: "department", value: func(lang string, f *Feedback) string { return f.DepartmentName }},
+	{key: "location", value: func(lang string, f *Feedback) string { return locationDisplayName(lang, f.Location) }},
+	{key: "rating", numeric: true, value: func(lang string, f *Feedback) string {
+		if f.Rating == 0 {
+			return ""
+		}
+		return strconv.Itoa(f.Rating)
+	}},
+	{key: "anonymous", value: func(lang string, f *Feedback) string {
+		if f.Anonymous {
+			return Translate(lang, "export.yes")
+		}
+		return ""
+	}},
+	{key: "user_id", value: func(lang string, f *Feedback) string {
+		if f.UserID == 0 {
+			return ""
+		}
+		return strconv.FormatInt(f.UserID, 10)
+	}},
+	{key: "username", value: func(lang string, f *Feedback) string { return f.Username }},
+	{key: "first_name", value: func(lang string, f *Feedback) string { return f.FirstName }},
+	{key: "last_name", value: func(lang string, f *Feedback) string { return f.LastName }},
+	{key: "phone", value: func(lang string, f *Feedback) string { return f.Phone }},
+	{key: "assignee", value: func(lang string, f *Feedback) string { return f.AssigneeName }},
+	{key: "due_at", value: func(lang string, f *Feedback) string { return exportTime(f.DueAt) }},
+	{key: "answers", value: func(lang string, f *Feedback) string {
+		var parts []string
+		for _, answer := range f.Answers {
+			parts = append(parts, answer.Question+": "+answer.Label)
+		}
+		return strings.Join(parts, "; ")
+	}},
+	{key: "message", value: func(lang string, f *Feedback) string { return f.Message }},
+}
+
+func exportTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return inTimezone(value).Format("2006-01-02 15:04")
+}
+
+// exportStatusName - название статуса без эмодзи, чтобы по нему было удобно фильтровать в Excel
+func exportStatusName(lang, status string) string {
+	return strings.TrimLeftFunc(statusDisplayName(lang, status), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// maskPersonalData скрывает данные автора обращения, оставляя первую букву имени
+// и две последние цифры телефона, чтобы строки можно было различать
+func maskPersonalData(feedback *Feedback) {
+	mask := func(value string) string {
+		if value == "" {
+			return ""
+		}
+		return string([]rune(value)[:1]) + "***"
+	}
+	feedback.UserID = 0
+	feedback.Username = mask(feedback.Username)
+	feedback.FirstName = mask(feedback.FirstName)
+	feedback.LastName = mask(feedback.LastName)
+	if len(feedback.Phone) > 2 {
+		feedback.Phone = strings.Repeat("*", len(feedback.Phone)-2) + feedback.Phone[len(feedback.Phone)-2:]
+	}
+}
+
+// exportRowWriter записывает строки выгрузки в файл нужного формата
+type exportRowWriter interface {
+	WriteRow(cells []string) error
+	Close() error
+}
+
+// ExportFeedbacks выгружает обращения по фильтру в w и возвращает их число.
+// Данные анонимных авторов не выгружаются: таблица feedback_identities не читается.
+func ExportFeedbacks(db *Database, w io.Writer, opts ExportOptions) (int, error) {
+	var out exportRowWriter
+	switch opts.Format {
+	case ExportCSV:
+		out = newCSVRowWriter(w)
+	case ExportXLSX:
+		numeric := make([]bool, len(exportColumns))
+		for i, column := range exportColumns {
+			numeric[i] = column.numeric
+		}
+		out = newXLSXRowWriter(w, numeric)
+	default:
+		return 0, fmt.Errorf("unknown export format %q", opts.Format)
+	}
+
+	header := make([]string, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = Translate(opts.Lang, "export.column."+column.key)
+	}
+	if err := out.WriteRow(header); err != nil {
+		return 0, fmt.Errorf("failed to write export header: %w", err)
+	}
+
+	count := 0
+	err := db.StreamFeedbacks(opts.Filter, func(feedback *Feedback) error {
+		if opts.MaskPersonal {
+			if !feedback.Anonymous {
+				maskPersonalData(feedback)
+			}
+			feedback = redactFeedback(opts.Lang, feedback)
+		}
+
+		row := make([]string, len(exportColumns))
+		for i, column := range exportColumns {
+			row[i] = column.value(opts.Lang, feedback)
+		}
+		count++
+		return out.WriteRow(row)
+	})
+	if err != nil {
+		return count, fmt.Errorf("failed to export feedbacks: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return count, fmt.Errorf("failed to finish export: %w", err)
+	}
+	return count, nil
+}
+
+// csvRowWriter - CSV с BOM и разделителем ";", который Excel открывает без мастера импорта
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func newCSVRowWriter(w io.Writer) *csvRowWriter {
+	io.WriteString(w, "\ufeff")
+	out := csv.NewWriter(w)
+	out.Comma = ';'
+	out.UseCRLF = true
+	return &csvRowWriter{w: out}
+}
+
+func (c *csvRowWriter) WriteRow(cells []string) error {
+	safe := make([]string, len(cells))
+	for i, cell := range cells {
+		safe[i] = csvSafe(cell)
+	}
+	return c.w.Write(safe)
+}
+
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// csvSafe не дает Excel выполнить текст пациента как формулу: "=HYPERLINK(...)" и т.п.
+// Номера телефонов вида +77011234567 остаются как есть.
+func csvSafe(value string) string {
+	if value == "" {
+		return value
+	}
+	switch value[0] {
+	case '=', '@', '\t', '\r':
+		return "'" + value
+	case '+', '-':
+		if strings.TrimLeft(value[1:], "0123456789 ") != "" {
+			return "'" + value
+		}
+	}
+	return value
+}
+
+// xlsxRowWriter пишет книгу Excel с одним листом, не держа строки в памяти:
+// лист записывается в zip по мере поступления строк
+type xlsxRowWriter struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	numeric []bool
+	rows    int
+	err     error
+}
+
+var xlsxStaticParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Feedback" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	// Стиль 1 - полужирный шрифт для заголовка
+	{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+func newXLSXRowWriter(w io.Writer, numeric []bool) *xlsxRowWriter {
+	x := &xlsxRowWriter{zip: zip.NewWriter(w), numeric: numeric}
+	for _, part := range xlsxStaticParts {
+		x.writePart(part.name, part.body)
+	}
+
+	sheet, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		x.err = err
+		return x
+	}
+	x.sheet = bufio.NewWriter(sheet)
+	// Строка заголовка закреплена при прокрутке
+	x.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<sheetData>`)
+	return x
+}
+
+func (x *xlsxRowWriter) writePart(name, body string) {
+	if x.err != nil {
+		return
+	}
+	part, err := x.zip.Create(name)
+	if err == nil {
+		_, err = io.WriteString(part, body)
+	}
+	x.err = err
+}
+
+func (x *xlsxRowWriter) WriteRow(cells []string) error {
+	if x.err != nil {
+		return x.err
+	}
+	x.rows++
+	header := x.rows == 1
+
+	x.sheet.WriteString(`<row>`)
+	for i, cell := range cells {
+		switch {
+		case header:
+			x.sheet.WriteString(`<c s="1" t="inlineStr"><is><t>`)
+		case cell == "":
+			x.sheet.WriteString(`<c/>`)
+			continue
+		case i < len(x.numeric) && x.numeric[i]:
+			x.sheet.WriteString(`<c><v>` + cell + `</v></c>`)
+			continue
+		default:
+			x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		}
+		if err := xml.EscapeText(x.sheet, []byte(truncateRunes(cell, xlsxCellLimit))); err != nil {
+			x.err = err
+			return err
+		}
+		x.sheet.WriteString(`</t></is></c>`)
+	}
+	_, x.err = x.sheet.WriteString(`</row>`)
+	return x.err
+}
+
+func (x *xlsxRowWriter) Close() error {
+	if x.err != nil {
+		return x.err
+	}
+	x.sheet.WriteString(`</sheetData>`)
+	if x.rows > 0 {
+		x.sheet.WriteString(fmt.Sprintf(`<autoFilter ref="A1:%s%d"/>`, xlsxColumnName(len(x.numeric)), x.rows))
+	}
+	x.sheet.WriteString(`</worksheet>`)
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// xlsxColumnName - буквенное имя столбца Excel по номеру с единицы: 1 - A, 27 - AA
+func xlsxColumnName(number int) string {
+	var name []byte
+	for number > 0 {
+		number--
+		name = append([]byte{byte('A' + number%26)}, name...)
+		number /= 26
+	}
+	return string(name)
+}
+
+// exportFileName - имя файла выгрузки, например feedback_20260101-20260131.xlsx
+func exportFileName(format string, filter FeedbackFilter) string {
+	name := "feedback"
+	if !filter.From.IsZero() {
+		name += "_" + inTimezone(filter.From).Format("20060102")
+		if !filter.To.IsZero() {
+			name += "-" + inTimezone(filter.To.Add(-time.Second)).Format("20060102")
+		}
+	}
+	return name + "." + format
+}
+
+func exportContentType(format string) string {
+	if format == ExportCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+// exportDateRange переводит даты "с" и "по" (включительно) в границы выборки в часовом поясе TIMEZONE
+func exportDateRange(fromValue, toValue string) (time.Time, time.Time, bool) {
+	loc := nowInTimezone().Location()
+	var from, to time.Time
+	if fromValue != "" {
+		date, ok := parseInboxDate(fromValue)
+		if !ok {
+			return from, to, false
+		}
+		from, _ = time.ParseInLocation(inboxDateFormat, date, loc)
+	}
+	if toValue != "" {
+		date, ok := parseInboxDate(toValue)
+		if !ok {
+			return from, to, false
+		}
+		to, _ = time.ParseInLocation(inboxDateFormat, date, loc)
+		to = to.AddDate(0, 0, 1)
+	}
+	return from, to, true
+}
+
+// parseExportArgs разбирает аргументы /export и команды export:
+// [csv|xlsx] [complaint|review] [open|статус] [mine|assignee=ID] [дата с] [дата по] [код отделения].
+// userID - сотрудник, вызвавший /export; 0 для командной строки, где "mine" недоступно
+func parseExportArgs(db *Database, args []string, userID int64) (ExportOptions, error) {
+	opts := ExportOptions{Format: ExportXLSX}
+	var dates []string
+	for _, arg := range args {
+		arg = strings.ToLower(arg)
+		switch {
+		case arg == ExportCSV || arg == ExportXLSX:
+			opts.Format = arg
+		case arg == "complaint" || arg == "review":
+			opts.Filter.Type = arg
+		case arg == "open":
+			opts.Filter.Statuses = openStatuses
+		case arg == "mine":
+			// "mine" доступно только в боте, где известен сотрудник
+			if userID == 0 {
+				return opts, errExportUsage
+			}
+			opts.Filter.AssigneeID = userID
+		case strings.HasPrefix(arg, "assignee="):
+			assigneeID, err := strconv.ParseInt(strings.TrimPrefix(arg, "assignee="), 10, 64)
+			if err != nil || assigneeID <= 0 {
+				return opts, errExportUsage
+			}
+			opts.Filter.AssigneeID = assigneeID
+		case isSupportedStatus(arg):
+			opts.Filter.Statuses = []string{arg}
+		default:
+			if _, ok := parseInboxDate(arg); ok {
+				dates = append(dates, arg)
+				continue
+			}
+			department, err := db.GetDepartmentByCode(arg)
+			if err != nil {
+				return opts, err
+			}
+			if department == nil {
+				return opts, errExportUsage
+			}
+			opts.Filter.DepartmentID = department.ID
+		}
+	}
+
+	if len(dates) > 2 {
+		return opts, errExportUsage
+	}
+	dates = append(dates, "", "")
+	from, to, _ := exportDateRange(dates[0], dates[1])
+	opts.Filter.From, opts.Filter.To = from, to
+	return opts, nil
+}
+
+// handleExportCommand обрабатывает /export: выгрузка отправляется документом в чат.
+// Заведующий получает только свое отделение, наблюдатель - без данных авторов.
+func (t *TelegramBot) handleExportCommand(message *tgbotapi.Message, admin *Admin) {
+	chatID := message.Chat.ID
+	opts, err := parseExportArgs(t.database, strings.Fields(message.CommandArguments()), message.From.ID)
+	if err != nil {
+		if !errors.Is(err, errExportUsage) {
+			t.logger.Error("Failed to parse export arguments: ", err)
+		}
+		t.sendMessage(chatID, t.tr(chatID, "export.usage"))
+		return
+	}
+	if scope := admin.ScopeDepartmentID(); scope != 0 {
+		opts.Filter.DepartmentID = scope
+	}
+	opts.Lang = t.lang(chatID)
+	opts.MaskPersonal = !admin.Can(PermViewPersonalData)
+
+	buf := &limitedBuffer{limit: exportDocumentLimit}
+	count, err := ExportFeedbacks(t.database, buf, opts)
+	if errors.Is(err, errExportTooLarge) {
+		t.sendMessage(chatID, t.tr(chatID, "export.too_large"))
+		return
+	}
+	if err != nil {
+		t.logger.Error("Failed to export feedbacks: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "export.error"))
+		return
+	}
+	if count == 0 {
+		t.sendMessage(chatID, t.tr(chatID, "export.empty"))
+		return
+	}
+
+	document := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: exportFileName(opts.Format, opts.Filter), Bytes: buf.Bytes()})
+	document.Caption = t.tr(chatID, "export.caption", count)
+	if _, err := t.bot.Send(document); err != nil {
+		t.logger.Error("Failed to send export: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "export.error"))
+	}
+}
+
+// exportHandler отдает выгрузку обращений (Authorization: Bearer API_TOKEN):
+// /feedback/export?format=csv|xlsx&type=complaint|review&status=open|<статус>&from=2026-01-01&to=2026-01-31&dept=<код>&lang=kk|ru|en
+// Токен API дает полный доступ, поэтому данные авторов скрываются только параметром mask=1.
+func (a *App) exportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !a.authorizeAPI(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	opts := ExportOptions{Format: ExportXLSX, MaskPersonal: query.Get("mask") == "1"}
+	if format := query.Get("format"); format != "" {
+		if format != ExportCSV && format != ExportXLSX {
+			http.Error(w, "invalid format", http.StatusBadRequest)
+			return
+		}
+		opts.Format = format
+	}
+
+	switch feedbackType := query.Get("type"); feedbackType {
+	case "":
+	case "complaint", "review":
+		opts.Filter.Type = feedbackType
+	default:
+		http.Error(w, "invalid type", http.StatusBadRequest)
+		return
+	}
+
+	switch status := query.Get("status"); {
+	case status == "":
+	case status == "open":
+		opts.Filter.Statuses = openStatuses
+	case isSupportedStatus(status):
+		opts.Filter.Statuses = []string{status}
+	default:
+		http.Error(w, "invalid status", http.StatusBadRequest)
+		return
+	}
+
+	from, to, ok := exportDateRange(query.Get("from"), query.Get("to"))
+	if !ok {
+		http.Error(w, "invalid date", http.StatusBadRequest)
+		return
+	}
+	opts.Filter.From, opts.Filter.To = from, to
+
+	if code := query.Get("dept"); code != "" {
+		department, err := a.database.GetDepartmentByCode(code)
+		if err != nil {
+			a.logger.Error("Failed to get department: ", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		if department == nil {
+			http.Error(w, "department not found", http.StatusNotFound)
+			return
+		}
+		opts.Filter.DepartmentID = department.ID
+	}
+
+	if assignee := query.Get("assignee"); assignee != "" {
+		assigneeID, err := strconv.ParseInt(assignee, 10, 64)
+		if err != nil || assigneeID <= 0 {
+			http.Error(w, "invalid assignee", http.StatusBadRequest)
+			return
+		}
+		opts.Filter.AssigneeID = assigneeID
+	}
+
+	if opts.Lang = normalizeLanguage(query.Get("lang")); opts.Lang == "" {
+		opts.Lang = staffLanguage()
+	}
+
+	// Строки отправляются по мере чтения из базы; ошибка в середине выгрузки только логируется
+	w.Header().Set("Content-Type", exportContentType(opts.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, exportFileName(opts.Format, opts.Filter)))
+	w.Header().Set("Cache-Control", "no-store")
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil {
+		a.logger.Warn("Failed to extend export write deadline: ", err)
+	}
+	if _, err := ExportFeedbacks(a.database, w, opts); err != nil {
+		a.logger.Error("Failed to export feedbacks: ", err)
+	}
+}
+
+// runExportCLI пишет выгрузку в stdout или в файл -o <путь>, например
+// "hospital-feedback-bot export xlsx complaint 2026-01-01 2026-01-31 -o january.xlsx"
+func runExportCLI(args []string) error {
+	var output string
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "-o" && i+1 < len(args):
+			output = args[i+1]
+			i++
+		case args[i] == "-assignee" && i+1 < len(args):
+			rest = append(rest, "assignee="+args[i+1])
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	db, err := NewDatabase()
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	opts, err := parseExportArgs(db, rest, 0)
+	if err != nil {
+		if errors.Is(err, errExportUsage) {
+			return errors.New(cliUsage)
+		}
+		return err
+	}
+	opts.Lang = staffLanguage()
+
+	if output == "" {
+		count, err := ExportFeedbacks(db, os.Stdout, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%d feedbacks exported\n", count)
+		return nil
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	count, err := ExportFeedbacks(db, file, opts)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	// Ошибка записи на диск может проявиться только при закрытии файла
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	fmt.Fprintf(os.Stderr, "%d feedbacks exported to %s\n", count, output)
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLimitedBufferStopsExport(t *testing.T) {
+	buf := &limitedBuffer{limit: 64 << 10}
+	out := newXLSXRowWriter(buf, make([]bool, 2))
+
+	var err error
+	row := []string{strings.Repeat("жалоба ", 200), "0"}
+	for i := 0; i < 10000 && err == nil; i++ {
+		err = out.WriteRow(row)
+	}
+	if err == nil {
+		err = out.Close()
+	}
+	if !errors.Is(err, errExportTooLarge) {
+		t.Fatalf("err = %v, want errExportTooLarge", err)
+	}
+	if buf.Len() > buf.limit {
+		t.Fatalf("buffer grew to %d bytes, limit %d", buf.Len(), buf.limit)
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// form возвращает текущую анкету; ее можно перезагрузить на лету командой /form_reload
+func (t *TelegramBot) form() *FormEngine {
+	t.formMu.RLock()
+	defer t.formMu.RUnlock()
+	return t.formEngine
+}
+
+// reloadForm заново загружает анкету и возвращает ее источник
+func (t *TelegramBot) reloadForm() (string, error) {
+	engine, source, err := LoadFormEngine(t.database)
+	if err != nil {
+		return "", err
+	}
+
+	t.formMu.Lock()
+	t.formEngine = engine
+	t.formMu.Unlock()
+
+	return source, nil
+}
+
+// startFeedback начинает анкету с уже выбранным типом обращения.
+// Отделение и место из QR-кода сохраняются, остальные ответы сбрасываются.
+func (t *TelegramBot) startFeedback(chatID int64, from *tgbotapi.User, state *UserState, feedbackType string) {
+	location := state.Data["location"]
+	startDepartmentID := state.Data["start_department_id"]
+
+	// Файлы от незаконченного прошлого обращения больше не понадобятся
+	t.discardPendingAttachments(state)
+	state.Reset()
+	// Не заставляем заполнять анкету, если отправить ее все равно не получится
+	if rejection := t.checkRateLimit(from.ID); rejection != nil {
+		t.rejectSpam(chatID, rejection)
+		return
+	}
+	state.Data["type"] = feedbackType
+	if location != "" {
+		state.Data["location"] = location
+	}
+	if startDepartmentID != "" {
+		state.Data["start_department_id"] = startDepartmentID
+		state.Data["department_id"] = startDepartmentID
+	}
+
+	t.enterStep(chatID, from, state, t.form().First())
+}
+
+// advanceForm переходит к следующему шагу после ответа на текущий
+func (t *TelegramBot) advanceForm(chatID int64, from *tgbotapi.User, state *UserState) {
+	form := t.form()
+	current := form.Step(state.Data["step"])
+	if current == nil {
+		// Анкета изменилась, пока пользователь ее заполнял
+		t.discardPendingAttachments(state)
+		state.Reset()
+		t.sendMainMenu(chatID, from.ID)
+		return
+	}
+
+	t.enterStep(chatID, from, state, form.Next(current, state.Data))
+}
+
+// enterStep задает вопрос шага; шаги, которые не нужно задавать, пропускаются.
+// Когда шаги закончились, пользователю показывается предпросмотр обращения.
+func (t *TelegramBot) enterStep(chatID int64, from *tgbotapi.User, state *UserState, step *FormStep) {
+	form := t.form()
+	editing := state.Data["editing"] == "1"
+	// Ограничение защищает от зацикленных переходов, как в FormEngine.Path
+	for hops := 0; step != nil && hops <= len(form.Definition().Steps); hops++ {
+		// При редактировании из предпросмотра пройденные шаги не задаются повторно
+		if form.Applies(step, state.Data) && !(editing && stepVisited(state, step.ID)) {
+			state.Data["step"] = step.ID
+			state.State = stepStates[step.Kind]
+			if t.askStep(chatID, state, step) {
+				markStepVisited(state, step.ID)
+				return
+			}
+		}
+		step = form.Next(step, state.Data)
+	}
+
+	delete(state.Data, "step")
+	delete(state.Data, "editing")
+	t.showPreview(chatID, state)
+}
+
+// stepVisited проверяет, задавался ли уже вопрос шага
+func stepVisited(state *UserState, stepID string) bool {
+	for _, id := range strings.Split(state.Data["visited"], ",") {
+		if id == stepID {
+			return true
+		}
+	}
+	return false
+}
+
+func markStepVisited(state *UserState, stepID string) {
+	if stepVisited(state, stepID) {
+		return
+	}
+	if state.Data["visited"] == "" {
+		state.Data["visited"] = stepID
+		return
+	}
+	state.Data["visited"] += "," + stepID
+}
+
+func unmarkStepVisited(state *UserState, stepID string) {
+	var ids []string
+	for _, id := range strings.Split(state.Data["visited"], ",") {
+		if id != "" && id != stepID {
+			ids = append(ids, id)
+		}
+	}
+	state.Data["visited"] = strings.Join(ids, ",")
+}
+
+// askStep задает вопрос шага. Возвращает false, если шаг сейчас не нужен
+// (например, отделение уже известно из QR-кода).
+func (t *TelegramBot) askStep(chatID int64, state *UserState, step *FormStep) bool {
+	switch step.Kind {
+	case StepFeedbackType:
+		return t.askType(chatID, state, step)
+	case StepAnonymity:
+		return t.askAnonymity(chatID, state)
+	case StepDepartment:
+		return t.askDepartment(chatID, state)
+	case StepMessage:
+		return t.askMessage(chatID, state)
+	case StepContact:
+		return t.askContact(chatID, state)
+	case StepRating:
+		return t.askRating(chatID, state)
+	default:
+		t.askQuestion(chatID, step)
+		return true
+	}
+}
+
+// currentStep возвращает шаг анкеты, на котором находится пользователь
+func (t *TelegramBot) currentStep(state *UserState) *FormStep {
+	return t.form().Step(state.Data["step"])
+}
+
+// askType просит выбрать тип обращения, если он еще не выбран в главном меню
+func (t *TelegramBot) askType(chatID int64, state *UserState, step *FormStep) bool {
+	if state.Data["type"] != "" {
+		return false
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(feedbackTypeLabel(t.lang(chatID), "complaint"), "form:"+step.ID+":complaint"),
+			tgbotapi.NewInlineKeyboardButtonData(feedbackTypeLabel(t.lang(chatID), "review"), "form:"+step.ID+":review"),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, t.tr(chatID, "type.ask"))
+	msg.ReplyMarkup = keyboard
+	t.bot.Send(msg)
+	return true
+}
+
+// askQuestion задает дополнительный вопрос анкеты
+func (t *TelegramBot) askQuestion(chatID int64, step *FormStep) {
+	lang := t.lang(chatID)
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	switch step.Kind {
+	case StepChoice:
+		for _, option := range step.Options {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(option.Label.In(lang), "form:"+step.ID+":"+option.Value),
+			))
+		}
+	case StepYesNo:
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "button.yes"), "form:"+step.ID+":yes"),
+			tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "button.no"), "form:"+step.ID+":no"),
+		))
+	case StepDate:
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "button.today"), "form:"+step.ID+":today"),
+			tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "button.yesterday"), "form:"+step.ID+":yesterday"),
+		))
+	}
+
+	if !step.Required {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "button.skip"), "form:"+step.ID+":__skip"),
+		))
+	}
+
+	text := step.Question.In(lang)
+	if step.Kind == StepDate {
+		text += "\n\n" + Translate(lang, "form.date_hint")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	if len(rows) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+	t.bot.Send(msg)
+}
+
+// handleAnswerInput принимает текстовый ответ на дополнительный вопрос анкеты
+func (t *TelegramBot) handleAnswerInput(message *tgbotapi.Message, state *UserState) {
+	step := t.currentStep(state)
+	if step == nil || step.IsSystem() {
+		state.Reset()
+		t.sendMainMenu(message.Chat.ID, message.From.ID)
+		return
+	}
+
+	t.answerStep(message.Chat.ID, message.From, state, step, message.Text)
+}
+
+// handleFormCallback обрабатывает кнопки анкеты вида form:<шаг>:<значение>
+func (t *TelegramBot) handleFormCallback(chatID int64, from *tgbotapi.User, state *UserState, payload string) {
+	stepID, value, ok := strings.Cut(payload, ":")
+	step := t.currentStep(state)
+	if !ok || step == nil || step.ID != stepID {
+		// Кнопка от уже пройденного шага
+		return
+	}
+
+	if step.Kind == StepFeedbackType {
+		if value != "complaint" && value != "review" {
+			return
+		}
+		state.Data["type"] = value
+		t.advanceForm(chatID, from, state)
+		return
+	}
+
+	t.answerStep(chatID, from, state, step, value)
+}
+
+// answerStep проверяет ответ, сохраняет его в состоянии и переходит дальше
+func (t *TelegramBot) answerStep(chatID int64, from *tgbotapi.User, state *UserState, step *FormStep, input string) {
+	if input == "__skip" && !step.Required {
+		delete(state.Data, step.DataKey())
+		t.advanceForm(chatID, from, state)
+		return
+	}
+
+	value, problem := step.ValidateAnswer(input, nowInTimezone(), t.lang(chatID))
+	if problem != "" {
+		t.sendMessage(chatID, problem)
+		t.askQuestion(chatID, step)
+		return
+	}
+
+	state.Data[step.DataKey()] = value
+	t.advanceForm(chatID, from, state)
+}
+
+// handleFormCommand обрабатывает команды администратора для просмотра и перезагрузки анкеты
+func (t *TelegramBot) handleFormCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	if message.Command() == "form_reload" {
+		source, err := t.reloadForm()
+		if err != nil {
+			t.logger.Error("Failed to reload form: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "form.reload_failed", err.Error()))
+			return
+		}
+		t.sendMessage(chatID, t.tr(chatID, "form.reloaded", source))
+	}
+
+	definition := t.form().Definition()
+	var sb strings.Builder
+	sb.WriteString(t.tr(chatID, "form.summary", definition.Name) + "\n\n")
+	for i, step := range definition.Steps {
+		sb.WriteString(fmt.Sprintf("%d. %s [%s]", i+1, step.ID, step.Kind))
+		if question := step.Question.In(t.lang(chatID)); question != "" {
+			sb.WriteString(" — " + question)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n" + t.tr(chatID, "form.reload_hint"))
+	t.sendMessage(chatID, sb.String())
+}
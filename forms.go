@@ -0,0 +1,747 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Виды шагов анкеты. Системные шаги заполняют поля самого обращения,
+// остальные сохраняются в feedback_answers.
+const (
+	StepFeedbackType = "feedback_type"
+	StepAnonymity    = "anonymity"
+	StepDepartment   = "department"
+	StepMessage      = "message"
+	StepContact      = "contact"
+	StepRating       = "rating"
+
+	StepText   = "text"
+	StepChoice = "choice"
+	StepDate   = "date"
+	StepNumber = "number"
+	StepYesNo  = "yesno"
+)
+
+// FormEnd в поле next завершает анкету
+const FormEnd = "end"
+
+// stepStates - состояние диалога, в котором бот ждет ответа на шаг данного вида
+var stepStates = map[string]string{
+	StepFeedbackType: StateWaitingForType,
+	StepAnonymity:    StateWaitingForAnonymity,
+	StepDepartment:   StateWaitingForDepartment,
+	StepMessage:      StateWaitingForMessage,
+	StepContact:      StateWaitingForContact,
+	StepRating:       StateWaitingForRating,
+	StepText:         StateWaitingForAnswer,
+	StepChoice:       StateWaitingForAnswer,
+	StepDate:         StateWaitingForAnswer,
+	StepNumber:       StateWaitingForAnswer,
+	StepYesNo:        StateWaitingForAnswer,
+}
+
+// systemStepKeys - ключ UserState.Data, в который системный шаг кладет ответ
+var systemStepKeys = map[string]string{
+	StepFeedbackType: "type",
+	StepAnonymity:    "anonymous",
+	StepDepartment:   "department_id",
+	StepMessage:      "message",
+	StepContact:      "phone",
+	StepRating:       "rating",
+}
+
+// FormDefinition - декларативное описание анкеты обращения
+type FormDefinition struct {
+	Name  string      `json:"name"`
+	Steps []*FormStep `json:"steps"`
+}
+
+type FormStep struct {
+	ID         string          `json:"id"`
+	Kind       string          `json:"kind"`
+	Question   LocalizedText   `json:"question,omitempty"`
+	Options    []FormOption    `json:"options,omitempty"`
+	Required   bool            `json:"required,omitempty"`
+	Validation *FormValidation `json:"validation,omitempty"`
+	// When - шаг задается только при выполнении условия
+	When *FormCondition `json:"when,omitempty"`
+	// Branches - переходы по ответам; проверяются по порядку, первый подходящий побеждает
+	Branches []FormBranch `json:"branches,omitempty"`
+	// Next - следующий шаг по умолчанию; пусто - следующий по порядку, "end" - конец анкеты
+	Next string `json:"next,omitempty"`
+}
+
+type FormOption struct {
+	Value string        `json:"value"`
+	Label LocalizedText `json:"label"`
+}
+
+// LocalizedText - текст анкеты на нескольких языках.
+// В JSON задается строкой или объектом вида {"kk": "...", "ru": "...", "en": "..."}.
+type LocalizedText map[string]string
+
+func (l *LocalizedText) UnmarshalJSON(raw []byte) error {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		*l = LocalizedText{"": text}
+		return nil
+	}
+
+	var texts map[string]string
+	if err := json.Unmarshal(raw, &texts); err != nil {
+		return fmt.Errorf("localized text must be a string or an object: %w", err)
+	}
+	*l = texts
+	return nil
+}
+
+// In возвращает текст на языке lang, иначе на языке по умолчанию, иначе любой из заданных
+func (l LocalizedText) In(lang string) string {
+	for _, key := range append([]string{lang, defaultLanguage(), ""}, supportedLanguages...) {
+		if text := l[key]; text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// Matches проверяет, совпадает ли ввод с текстом на любом языке
+func (l LocalizedText) Matches(input string) bool {
+	for _, text := range l {
+		if text != "" && strings.EqualFold(input, text) {
+			return true
+		}
+	}
+	return false
+}
+
+type FormValidation struct {
+	MinLength int      `json:"min_length,omitempty"`
+	MaxLength int      `json:"max_length,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	Integer   bool     `json:"integer,omitempty"`
+	NotFuture bool     `json:"not_future,omitempty"`
+	MaxAge    int      `json:"max_age_days,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// FormCondition сравнивает ответ на шаг Step со значением
+type FormCondition struct {
+	Step      string   `json:"step"`
+	Equals    string   `json:"equals,omitempty"`
+	NotEquals string   `json:"not_equals,omitempty"`
+	In        []string `json:"in,omitempty"`
+}
+
+type FormBranch struct {
+	If   FormCondition `json:"if"`
+	Next string        `json:"next"`
+}
+
+func (s *FormStep) IsSystem() bool {
+	_, ok := systemStepKeys[s.Kind]
+	return ok
+}
+
+// DataKey - ключ UserState.Data, в котором хранится ответ на шаг
+func (s *FormStep) DataKey() string {
+	if key, ok := systemStepKeys[s.Kind]; ok {
+		return key
+	}
+	return "answer:" + s.ID
+}
+
+func (s *FormStep) Option(value string) *FormOption {
+	for i := range s.Options {
+		if s.Options[i].Value == value {
+			return &s.Options[i]
+		}
+	}
+	return nil
+}
+
+// FormEngine - конечный автомат, проводящий пользователя по шагам анкеты
+type FormEngine struct {
+	definition *FormDefinition
+	index      map[string]int
+}
+
+func NewFormEngine(definition *FormDefinition) (*FormEngine, error) {
+	engine := &FormEngine{
+		definition: definition,
+		index:      make(map[string]int),
+	}
+	if err := engine.validate(); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+func (e *FormEngine) Definition() *FormDefinition {
+	return e.definition
+}
+
+func (e *FormEngine) First() *FormStep {
+	if len(e.definition.Steps) == 0 {
+		return nil
+	}
+	return e.definition.Steps[0]
+}
+
+func (e *FormEngine) Step(id string) *FormStep {
+	if i, ok := e.index[id]; ok {
+		return e.definition.Steps[i]
+	}
+	return nil
+}
+
+// StepByKind возвращает первый шаг указанного вида
+func (e *FormEngine) StepByKind(kind string) *FormStep {
+	for _, step := range e.definition.Steps {
+		if step.Kind == kind {
+			return step
+		}
+	}
+	return nil
+}
+
+// Next определяет шаг после step с учетом ветвлений и условий When; nil - анкета закончена
+func (e *FormEngine) Next(step *FormStep, data map[string]string) *FormStep {
+	next := e.following(step, data)
+	// Циклы отсекаются при загрузке анкеты, ограничение - вторая линия защиты
+	for hops := 0; next != nil && next.When != nil && !e.matches(*next.When, data); hops++ {
+		if hops >= len(e.definition.Steps) {
+			return nil
+		}
+		next = e.following(next, data)
+	}
+	return next
+}
+
+// Applies проверяет условие When шага
+func (e *FormEngine) Applies(step *FormStep, data map[string]string) bool {
+	return step.When == nil || e.matches(*step.When, data)
+}
+
+func (e *FormEngine) following(step *FormStep, data map[string]string) *FormStep {
+	target := step.Next
+	for _, branch := range step.Branches {
+		if e.matches(branch.If, data) {
+			target = branch.Next
+			break
+		}
+	}
+
+	switch target {
+	case FormEnd:
+		return nil
+	case "":
+		i := e.index[step.ID] + 1
+		if i >= len(e.definition.Steps) {
+			return nil
+		}
+		return e.definition.Steps[i]
+	default:
+		return e.Step(target)
+	}
+}
+
+func (e *FormEngine) matches(condition FormCondition, data map[string]string) bool {
+	step := e.Step(condition.Step)
+	if step == nil {
+		return false
+	}
+	value := data[step.DataKey()]
+
+	switch {
+	case len(condition.In) > 0:
+		for _, candidate := range condition.In {
+			if value == candidate {
+				return true
+			}
+		}
+		return false
+	case condition.NotEquals != "":
+		return value != condition.NotEquals
+	default:
+		return value == condition.Equals
+	}
+}
+
+// Path возвращает шаги, через которые проходит анкета при данных ответах
+// Условия проверяются только по ответам на шаги, уже лежащие на пути,
+// поэтому устаревшие ответы с другой ветки не влияют на результат.
+func (e *FormEngine) Path(data map[string]string) []*FormStep {
+	reached := make(map[string]string, len(data))
+	for key, value := range data {
+		reached[key] = value
+	}
+	for _, step := range e.definition.Steps {
+		if !step.IsSystem() {
+			delete(reached, step.DataKey())
+		}
+	}
+
+	var path []*FormStep
+	step := e.First()
+	if step != nil && !e.Applies(step, reached) {
+		step = e.Next(step, reached)
+	}
+	// Ограничение защищает от зацикленных переходов в анкете
+	for step != nil && len(path) < len(e.definition.Steps) {
+		path = append(path, step)
+		if value, ok := data[step.DataKey()]; ok {
+			reached[step.DataKey()] = value
+		}
+		step = e.Next(step, reached)
+	}
+	return path
+}
+
+// Answers возвращает ответы на несистемные шаги, лежащие на пути анкеты,
+// с вопросами и подписями на языке lang.
+// Ответы на шаги, ставшие ненужными после смены типа или ветки, отбрасываются.
+func (e *FormEngine) Answers(data map[string]string, lang string) []*FeedbackAnswer {
+	var answers []*FeedbackAnswer
+	for _, step := range e.Path(data) {
+		if step.IsSystem() {
+			continue
+		}
+		value, ok := data[step.DataKey()]
+		if !ok || value == "" {
+			continue
+		}
+
+		answer := &FeedbackAnswer{
+			StepID:   step.ID,
+			Question: step.Question.In(lang),
+			Value:    value,
+			Label:    value,
+		}
+		switch step.Kind {
+		case StepChoice:
+			if option := step.Option(value); option != nil {
+				answer.Label = option.Label.In(lang)
+			}
+		case StepYesNo:
+			answer.Label = yesNoLabel(lang, value)
+		case StepDate:
+			if date, err := time.Parse("2006-01-02", value); err == nil {
+				answer.Label = date.Format("02.01.2006")
+			}
+		}
+		answers = append(answers, answer)
+	}
+	return answers
+}
+
+func (e *FormEngine) validate() error {
+	seenKinds := make(map[string]bool)
+	for i, step := range e.definition.Steps {
+		if step.ID == "" {
+			return fmt.Errorf("form step %d has no id", i+1)
+		}
+		if strings.ContainsAny(step.ID, ": ") || len(step.ID) > 24 {
+			return fmt.Errorf("form step id %q must be shorter than 25 chars and contain no spaces or colons", step.ID)
+		}
+		if _, ok := e.index[step.ID]; ok {
+			return fmt.Errorf("duplicate form step id %q", step.ID)
+		}
+		if _, ok := stepStates[step.Kind]; !ok {
+			return fmt.Errorf("form step %q has unknown kind %q", step.ID, step.Kind)
+		}
+		if step.IsSystem() {
+			if seenKinds[step.Kind] {
+				return fmt.Errorf("form has more than one %q step", step.Kind)
+			}
+			seenKinds[step.Kind] = true
+		} else if step.Question.In(LangKazakh) == "" {
+			return fmt.Errorf("form step %q has no question", step.ID)
+		}
+		if step.Kind == StepChoice {
+			if len(step.Options) == 0 {
+				return fmt.Errorf("choice step %q has no options", step.ID)
+			}
+			for _, option := range step.Options {
+				// Данные кнопки form:<шаг>:<значение> не должны превышать 64 байта
+				if option.Value == "" || len("form:"+step.ID+":"+option.Value) > 64 {
+					return fmt.Errorf("choice step %q has empty or too long option value %q", step.ID, option.Value)
+				}
+			}
+		}
+		if v := step.Validation; v != nil && v.Pattern != "" {
+			pattern, err := regexp.Compile(v.Pattern)
+			if err != nil {
+				return fmt.Errorf("form step %q has invalid pattern: %w", step.ID, err)
+			}
+			v.pattern = pattern
+		}
+		e.index[step.ID] = i
+	}
+
+	if !seenKinds[StepMessage] {
+		return fmt.Errorf("form must contain a %q step", StepMessage)
+	}
+
+	for _, step := range e.definition.Steps {
+		targets := []string{step.Next}
+		conditions := []*FormCondition{step.When}
+		for i := range step.Branches {
+			targets = append(targets, step.Branches[i].Next)
+			conditions = append(conditions, &step.Branches[i].If)
+		}
+		for _, target := range targets {
+			if target != "" && target != FormEnd && e.Step(target) == nil {
+				return fmt.Errorf("form step %q refers to unknown step %q", step.ID, target)
+			}
+		}
+		for _, condition := range conditions {
+			if condition != nil && e.Step(condition.Step) == nil {
+				return fmt.Errorf("form step %q has condition on unknown step %q", step.ID, condition.Step)
+			}
+		}
+	}
+
+	return e.validateAcyclic()
+}
+
+// successors возвращает все шаги, в которые можно перейти из step по next и ветвлениям
+func (e *FormEngine) successors(step *FormStep) []*FormStep {
+	targets := []string{step.Next}
+	for _, branch := range step.Branches {
+		targets = append(targets, branch.Next)
+	}
+
+	var steps []*FormStep
+	for _, target := range targets {
+		switch target {
+		case FormEnd:
+		case "":
+			if i := e.index[step.ID] + 1; i < len(e.definition.Steps) {
+				steps = append(steps, e.definition.Steps[i])
+			}
+		default:
+			steps = append(steps, e.Step(target))
+		}
+	}
+	return steps
+}
+
+// validateAcyclic отклоняет анкеты, в которых переходы образуют цикл:
+// шаги цикла, пропускаемые по условию When, зациклили бы переход к следующему шагу
+func (e *FormEngine) validateAcyclic() error {
+	// Нулевое значение в marks - шаг еще не посещен
+	const (
+		inProgress = iota + 1
+		done
+	)
+	marks := make(map[string]int, len(e.definition.Steps))
+
+	var visit func(step *FormStep) error
+	visit = func(step *FormStep) error {
+		switch marks[step.ID] {
+		case inProgress:
+			return fmt.Errorf("form step %q is part of a transition cycle", step.ID)
+		case done:
+			return nil
+		}
+		marks[step.ID] = inProgress
+		for _, next := range e.successors(step) {
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		marks[step.ID] = done
+		return nil
+	}
+
+	for _, step := range e.definition.Steps {
+		if err := visit(step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateAnswer проверяет и нормализует ответ на несистемный шаг.
+// Возвращает текст ошибки для пользователя на языке lang, если ответ не подходит.
+func (s *FormStep) ValidateAnswer(input string, now time.Time, lang string) (string, string) {
+	input = strings.TrimSpace(input)
+	v := s.Validation
+	if v == nil {
+		v = &FormValidation{}
+	}
+
+	switch s.Kind {
+	case StepText:
+		length := len([]rune(input))
+		if input == "" {
+			return "", Translate(lang, "form.text_required")
+		}
+		if v.MinLength > 0 && length < v.MinLength {
+			return "", Translate(lang, "form.min_length", v.MinLength)
+		}
+		if v.MaxLength > 0 && length > v.MaxLength {
+			return "", Translate(lang, "form.max_length", v.MaxLength)
+		}
+		if v.pattern != nil && !v.pattern.MatchString(input) {
+			return "", Translate(lang, "form.pattern")
+		}
+		return input, ""
+	case StepNumber:
+		number, err := strconv.ParseFloat(strings.ReplaceAll(input, ",", "."), 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return "", Translate(lang, "form.number")
+		}
+		if v.Integer && number != math.Trunc(number) {
+			return "", Translate(lang, "form.integer")
+		}
+		if v.Min != nil && number < *v.Min {
+			return "", Translate(lang, "form.min", strconv.FormatFloat(*v.Min, 'f', -1, 64))
+		}
+		if v.Max != nil && number > *v.Max {
+			return "", Translate(lang, "form.max", strconv.FormatFloat(*v.Max, 'f', -1, 64))
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), ""
+	case StepDate:
+		date, ok := parseFormDate(input, now)
+		if !ok {
+			return "", Translate(lang, "form.date_format")
+		}
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if v.NotFuture && date.After(today) {
+			return "", Translate(lang, "form.date_future")
+		}
+		if v.MaxAge > 0 && date.Before(today.AddDate(0, 0, -v.MaxAge)) {
+			return "", Translate(lang, "form.date_too_old", v.MaxAge)
+		}
+		return date.Format("2006-01-02"), ""
+	case StepChoice:
+		for _, option := range s.Options {
+			if input == option.Value || option.Label.Matches(input) {
+				return option.Value, ""
+			}
+		}
+		return "", Translate(lang, "choose_button")
+	case StepYesNo:
+		switch strings.ToLower(input) {
+		case "yes", "иә", "да", "ия":
+			return "yes", ""
+		case "no", "жоқ", "нет":
+			return "no", ""
+		}
+		if matchesTranslation(input, "button.yes") {
+			return "yes", ""
+		}
+		if matchesTranslation(input, "button.no") {
+			return "no", ""
+		}
+		return "", Translate(lang, "form.yes_no")
+	}
+
+	return "", Translate(lang, "form.unsupported")
+}
+
+// parseFormDate понимает КК.АА.ЖЖЖЖ, ЖЖЖЖ-АА-КК и кнопки today/yesterday
+func parseFormDate(input string, now time.Time) (time.Time, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(input) {
+	case "today":
+		return today, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	}
+
+	for _, layout := range []string{"02.01.2006", "2.1.2006", "2006-01-02"} {
+		if date, err := time.ParseInLocation(layout, input, now.Location()); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func yesNoLabel(lang, value string) string {
+	if value == "yes" {
+		return Translate(lang, "form.answer.yes")
+	}
+	return Translate(lang, "form.answer.no")
+}
+
+// defaultFormDefinition повторяет исходный порядок шагов бота
+func defaultFormDefinition() *FormDefinition {
+	return &FormDefinition{
+		Name: "default",
+		Steps: []*FormStep{
+			{ID: "type", Kind: StepFeedbackType},
+			{ID: "anonymous", Kind: StepAnonymity},
+			{ID: "department", Kind: StepDepartment},
+			{ID: "rating", Kind: StepRating, When: &FormCondition{Step: "type", Equals: "review"}},
+			{ID: "message", Kind: StepMessage},
+			{ID: "contact", Kind: StepContact},
+		},
+	}
+}
+
+// LoadFormEngine загружает анкету из файла FORM_DEFINITION_FILE,
+// иначе из последней активной записи form_definitions, иначе берет анкету по умолчанию
+func LoadFormEngine(database *Database) (*FormEngine, string, error) {
+	if path := getEnv("FORM_DEFINITION_FILE", ""); path != "" {
+		// Анкеты описываются только в JSON; YAML не поддерживается
+		if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+			return nil, "", fmt.Errorf("form definition must be JSON, YAML is not supported: %s", path)
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read form definition: %w", err)
+		}
+		engine, err := parseFormDefinition(raw)
+		return engine, "file " + path, err
+	}
+
+	raw, err := database.GetActiveFormDefinition()
+	if err != nil {
+		return nil, "", err
+	}
+	if raw != "" {
+		engine, err := parseFormDefinition([]byte(raw))
+		return engine, "database", err
+	}
+
+	engine, err := NewFormEngine(defaultFormDefinition())
+	return engine, "default", err
+}
+
+func parseFormDefinition(raw []byte) (*FormEngine, error) {
+	definition := &FormDefinition{}
+	if err := json.Unmarshal(raw, definition); err != nil {
+		return nil, fmt.Errorf("failed to parse form definition: %w", err)
+	}
+	return NewFormEngine(definition)
+}
+
+// GetActiveFormDefinition возвращает JSON последней активной анкеты или пустую строку
+func (d *Database) GetActiveFormDefinition() (string, error) {
+	query := `
+	SELECT definition
+	FROM form_definitions
+	WHERE active = TRUE
+	ORDER BY id DESC
+	LIMIT 1
+	`
+
+	var definition string
+	err := d.db.QueryRow(query).Scan(&definition)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get form definition: %w", err)
+	}
+	return definition, nil
+}
+
+// FeedbackAnswer - ответ на дополнительный вопрос анкеты
+type FeedbackAnswer struct {
+	ID         int64  `json:"id"`
+	FeedbackID int64  `json:"feedback_id"`
+	StepID     string `json:"step_id"`
+	Question   string `json:"question"`
+	Value      string `json:"value"`
+	Label      string `json:"label"`
+}
+
+func (d *Database) SaveFeedbackAnswer(answer *FeedbackAnswer) error {
+	query := `
+	INSERT INTO feedback_answers (feedback_id, step_id, question, value, label)
+	VALUES (?, ?, ?, ?, ?)
+	`
+
+	result, err := d.db.Exec(query, answer.FeedbackID, answer.StepID, answer.Question, answer.Value, answer.Label)
+	if err != nil {
+		return fmt.Errorf("failed to save feedback answer: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	answer.ID = id
+	return nil
+}
+
+// loadFeedbackAnswers загружает ответы анкет сразу для всех обращений одним запросом
+func (d *Database) loadFeedbackAnswers(feedbacks []*Feedback) error {
+	if len(feedbacks) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*Feedback, len(feedbacks))
+	args := make([]interface{}, 0, len(feedbacks))
+	for _, feedback := range feedbacks {
+		byID[feedback.ID] = feedback
+		args = append(args, feedback.ID)
+	}
+
+	query := `
+	SELECT id, feedback_id, step_id, question, value, label
+	FROM feedback_answers
+	WHERE feedback_id IN (?` + strings.Repeat(", ?", len(args)-1) + `)
+	ORDER BY id ASC
+	`
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query feedback answers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		answer := &FeedbackAnswer{}
+		if err := rows.Scan(&answer.ID, &answer.FeedbackID, &answer.StepID, &answer.Question, &answer.Value, &answer.Label); err != nil {
+			return fmt.Errorf("failed to scan feedback answer: %w", err)
+		}
+		if feedback := byID[answer.FeedbackID]; feedback != nil {
+			feedback.Answers = append(feedback.Answers, answer)
+		}
+	}
+
+	return rows.Err()
+}
+
+func (d *Database) GetFeedbackAnswers(feedbackID int64) ([]*FeedbackAnswer, error) {
+	query := `
+	SELECT id, feedback_id, step_id, question, value, label
+	FROM feedback_answers
+	WHERE feedback_id = ?
+	ORDER BY id ASC
+	`
+
+	rows, err := d.db.Query(query, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feedback answers: %w", err)
+	}
+	defer rows.Close()
+
+	var answers []*FeedbackAnswer
+	for rows.Next() {
+		answer := &FeedbackAnswer{}
+		if err := rows.Scan(&answer.ID, &answer.FeedbackID, &answer.StepID, &answer.Question, &answer.Value, &answer.Label); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback answer: %w", err)
+		}
+		answers = append(answers, answer)
+	}
+
+	return answers, nil
+}
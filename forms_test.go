@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseFormDefinitionCycles(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr string
+	}{
+		{
+			name: "linear form",
+			raw: `{"steps":[
+				{"id":"type","kind":"feedback_type"},
+				{"id":"message","kind":"message"}
+			]}`,
+		},
+		{
+			name: "branch forward",
+			raw: `{"steps":[
+				{"id":"type","kind":"feedback_type","branches":[{"if":{"step":"type","equals":"review"},"next":"message"}]},
+				{"id":"ward","kind":"text","question":{"kk":"Палата?"}},
+				{"id":"message","kind":"message"}
+			]}`,
+		},
+		{
+			name: "explicit next cycle",
+			raw: `{"steps":[
+				{"id":"message","kind":"message","next":"extra"},
+				{"id":"extra","kind":"yesno","question":{"kk":"Тағы?"},"next":"message"}
+			]}`,
+			wantErr: "transition cycle",
+		},
+		{
+			name: "branch cycle through skipped step",
+			raw: `{"steps":[
+				{"id":"type","kind":"feedback_type"},
+				{"id":"ward","kind":"text","question":{"kk":"Палата?"},"when":{"step":"type","equals":"complaint"}},
+				{"id":"message","kind":"message","branches":[{"if":{"step":"type","equals":"review"},"next":"ward"}]}
+			]}`,
+			wantErr: "transition cycle",
+		},
+		{
+			name: "self reference",
+			raw: `{"steps":[
+				{"id":"message","kind":"message","next":"message"}
+			]}`,
+			wantErr: "transition cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseFormDefinition([]byte(tt.raw))
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBundledFormsAreValid(t *testing.T) {
+	if _, err := NewFormEngine(defaultFormDefinition()); err != nil {
+		t.Fatalf("default form: %v", err)
+	}
+
+	raw, err := os.ReadFile("forms/intake.example.json")
+	if err != nil {
+		t.Fatalf("read example form: %v", err)
+	}
+	if _, err := parseFormDefinition(raw); err != nil {
+		t.Fatalf("example form: %v", err)
+	}
+}
+
+func TestFormEngineNextSkipsSteps(t *testing.T) {
+	engine, err := parseFormDefinition([]byte(`{"steps":[
+		{"id":"type","kind":"feedback_type"},
+		{"id":"ward","kind":"text","question":{"kk":"Палата?"},"when":{"step":"type","equals":"complaint"}},
+		{"id":"doctor","kind":"text","question":{"kk":"Дәрігер?"},"when":{"step":"type","equals":"complaint"}},
+		{"id":"message","kind":"message"}
+	]}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	tests := []struct {
+		feedbackType string
+		want         string
+	}{
+		{"complaint", "ward"},
+		{"review", "message"},
+	}
+	for _, tt := range tests {
+		next := engine.Next(engine.First(), map[string]string{"type": tt.feedbackType})
+		if next == nil || next.ID != tt.want {
+			t.Fatalf("Next after type=%s = %v, want %s", tt.feedbackType, next, tt.want)
+		}
+	}
+}
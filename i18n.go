@@ -0,0 +1,247 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Поддерживаемые языки интерфейса
+const (
+	LangKazakh  = "kk"
+	LangRussian = "ru"
+	LangEnglish = "en"
+)
+
+// Catalog - тексты интерфейса на одном языке по ключам
+type Catalog map[string]string
+
+var catalogs = map[string]Catalog{
+	LangKazakh:  catalogKazakh,
+	LangRussian: catalogRussian,
+	LangEnglish: catalogEnglish,
+}
+
+var supportedLanguages = []string{LangKazakh, LangRussian, LangEnglish}
+
+// languageNames - названия языков на самих этих языках для кнопок выбора
+var languageNames = map[string]string{
+	LangKazakh:  "🇰🇿 Қазақша",
+	LangRussian: "🇷🇺 Русский",
+	LangEnglish: "🇬🇧 English",
+}
+
+// Translate возвращает текст по ключу на языке lang.
+// Если перевода нет, берется язык по умолчанию, затем казахский, затем сам ключ.
+func Translate(lang, key string, args ...interface{}) string {
+	text, ok := catalogs[lang][key]
+	if !ok {
+		text, ok = catalogs[defaultLanguage()][key]
+	}
+	if !ok {
+		text, ok = catalogs[LangKazakh][key]
+	}
+	if !ok {
+		return key
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// matchesTranslation проверяет, совпадает ли текст с переводом ключа на любом языке.
+// Нужно для reply-кнопок: пользователь мог сменить язык, пока клавиатура была на экране.
+func matchesTranslation(text, key string) bool {
+	text = strings.TrimSpace(text)
+	for _, catalog := range catalogs {
+		if value, ok := catalog[key]; ok && value == text {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeLanguage приводит language_code Telegram ("ru", "en-US") к поддерживаемому языку
+func normalizeLanguage(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if base, _, ok := strings.Cut(code, "-"); ok {
+		code = base
+	}
+	if _, ok := catalogs[code]; ok {
+		return code
+	}
+	return ""
+}
+
+// defaultLanguage - язык для пользователей, чей язык Telegram не поддерживается
+func defaultLanguage() string {
+	if lang := normalizeLanguage(getEnv("DEFAULT_LANGUAGE", LangKazakh)); lang != "" {
+		return lang
+	}
+	return LangKazakh
+}
+
+// staffLanguage - язык писем и сохраненных ответов анкеты для сотрудников
+func staffLanguage() string {
+	if lang := normalizeLanguage(getEnv("EMAIL_LANGUAGE", LangRussian)); lang != "" {
+		return lang
+	}
+	return LangRussian
+}
+
+func (d *Database) GetUserLanguage(userID int64) (string, error) {
+	var lang string
+	err := d.db.QueryRow("SELECT language FROM user_languages WHERE user_id = ?", userID).Scan(&lang)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user language: %w", err)
+	}
+	return lang, nil
+}
+
+func (d *Database) SaveUserLanguage(userID int64, lang string) error {
+	query := `
+	INSERT INTO user_languages (user_id, language)
+	VALUES (?, ?)
+	ON DUPLICATE KEY UPDATE language = VALUES(language)
+	`
+
+	if _, err := d.db.Exec(query, userID, lang); err != nil {
+		return fmt.Errorf("failed to save user language: %w", err)
+	}
+	return nil
+}
+
+// cachedLanguage - язык пользователя в кэше и время его последнего сообщения боту
+type cachedLanguage struct {
+	lang   string
+	usedAt time.Time
+}
+
+// rememberLanguage определяет язык пользователя при первом обращении:
+// сохраненный выбор, иначе язык его клиента Telegram, иначе язык по умолчанию
+func (t *TelegramBot) rememberLanguage(from *tgbotapi.User) {
+	t.langMu.Lock()
+	entry, ok := t.languages[from.ID]
+	if ok {
+		entry.usedAt = time.Now()
+		t.languages[from.ID] = entry
+	}
+	t.langMu.Unlock()
+	if ok {
+		return
+	}
+
+	lang, err := t.database.GetUserLanguage(from.ID)
+	if err != nil {
+		t.logger.Error("Failed to get user language: ", err)
+	}
+	if lang == "" {
+		lang = normalizeLanguage(from.LanguageCode)
+	}
+	if lang == "" {
+		lang = defaultLanguage()
+	}
+
+	t.cacheLanguage(from.ID, lang)
+}
+
+// lang возвращает язык пользователя; в личном чате ID чата совпадает с ID пользователя.
+// Язык пользователя, давно не писавшего боту, загружается из user_languages заново.
+func (t *TelegramBot) lang(chatID int64) string {
+	t.langMu.RLock()
+	entry, ok := t.languages[chatID]
+	t.langMu.RUnlock()
+	if ok {
+		return entry.lang
+	}
+	// Групповые чаты язык не выбирают
+	if chatID <= 0 {
+		return defaultLanguage()
+	}
+
+	lang, err := t.database.GetUserLanguage(chatID)
+	if err != nil {
+		t.logger.Error("Failed to get user language: ", err)
+		return defaultLanguage()
+	}
+	if lang == "" {
+		lang = defaultLanguage()
+	}
+	t.cacheLanguage(chatID, lang)
+	return lang
+}
+
+func (t *TelegramBot) cacheLanguage(userID int64, lang string) {
+	t.langMu.Lock()
+	t.languages[userID] = cachedLanguage{lang: lang, usedAt: time.Now()}
+	t.langMu.Unlock()
+}
+
+// forgetIdleLanguages убирает из кэша языки пользователей, не писавших боту дольше idle,
+// чтобы кэш не рос с каждым новым пользователем. Выбор остается в user_languages.
+func (t *TelegramBot) forgetIdleLanguages(idle time.Duration) {
+	cutoff := time.Now().Add(-idle)
+
+	t.langMu.Lock()
+	defer t.langMu.Unlock()
+	for userID, entry := range t.languages {
+		if entry.usedAt.Before(cutoff) {
+			delete(t.languages, userID)
+		}
+	}
+}
+
+// tr переводит ключ на язык пользователя
+func (t *TelegramBot) tr(chatID int64, key string, args ...interface{}) string {
+	return Translate(t.lang(chatID), key, args...)
+}
+
+func (t *TelegramBot) setLanguage(userID int64, lang string) error {
+	if err := t.database.SaveUserLanguage(userID, lang); err != nil {
+		return err
+	}
+
+	t.cacheLanguage(userID, lang)
+	return nil
+}
+
+// askLanguage показывает кнопки выбора языка
+func (t *TelegramBot) askLanguage(chatID int64) {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, lang := range supportedLanguages {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(languageNames[lang], "lang:"+lang))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, t.tr(chatID, "language.ask"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+	t.bot.Send(msg)
+}
+
+// handleLanguageSelection сохраняет выбранный язык (lang:<код>)
+func (t *TelegramBot) handleLanguageSelection(chatID int64, from *tgbotapi.User, state *UserState, value string) {
+	lang := normalizeLanguage(value)
+	if lang == "" {
+		t.askLanguage(chatID)
+		return
+	}
+
+	if err := t.setLanguage(from.ID, lang); err != nil {
+		t.logger.Error("Failed to save user language: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+
+	t.sendMessage(chatID, t.tr(chatID, "language.saved", languageNames[lang]))
+	// Посреди заполнения обращения меню не показываем, чтобы не сбить шаг анкеты
+	if state.State == StateStart {
+		t.sendMainMenu(chatID, from.ID)
+	}
+}
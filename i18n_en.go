@@ -0,0 +1,390 @@
+package main
+
+// catalogEnglish - тексты на английском
+var catalogEnglish = Catalog{
+	// Главное меню и общие кнопки
+	"menu.title":            "🏥 Hospital feedback system main menu\n\nChoose an action:",
+	"button.complaint":      "📝 Submit a complaint",
+	"button.review":         "⭐ Leave a review",
+	"button.help":           "❓ Help",
+	"button.stats":          "📊 Statistics",
+	"button.language":       "🌐 Language",
+	"button.main_menu":      "🏠 Main menu",
+	"button.new_request":    "🏥 New request",
+	"button.submit":         "✅ Submit",
+	"button.skip":           "⏭ Skip",
+	"button.yes":            "✅ Yes",
+	"button.no":             "❌ No",
+	"button.today":          "Today",
+	"button.yesterday":      "Yesterday",
+	"button.edit_text":      "✏️ Edit text",
+	"button.change_type":    "🔄 Change type",
+	"button.mystatus":       "📋 My requests",
+	"button.inbox":          "📥 Feedback",
+	"button.back_to_list":   "⬅️ Back to list",
+	"button.assign":         "👤 Assign",
+	"button.my_tickets":     "🙋 Assigned to me",
+	"button.prev":           "⬅️ Back",
+	"button.next":           "Next ➡️",
+	"button.take":           "🙋 Take",
+	"button.mark_processed": "✅ Mark processed",
+	"button.cancel":         "❌ Cancel",
+
+	"type.complaint": "Complaint",
+	"type.review":    "Review",
+	"type.ask":       "Choose the request type:",
+	"type.invalid":   "Please choose ‘complaint’ or ‘review’",
+
+	"access.denied":       "❌ You do not have access to this action",
+	"access.stats_denied": "❌ You do not have access to statistics",
+	"error.save":          "❌ Something went wrong while saving. Please try again later.",
+	"error.generic":       "❌ Something went wrong. Please try again later.",
+	"choose_button":       "👇 Please choose one of the buttons below.",
+
+	"language.ask":   "🌐 Choose your language:",
+	"language.saved": "✅ Language changed: %s",
+
+	"location.ward": "Ward %s",
+
+	// Шаги обращения
+	"anonymity.ask":         "Would you like to send the request under your name or anonymously?\n\n🕶 For anonymous requests your name and account are hidden from staff, but we can still reply to you through this bot.",
+	"anonymity.no":          "👤 Under my name",
+	"anonymity.yes":         "🕶 Anonymously",
+	"department.ask":        "🏢 Which department is your request about?",
+	"department.skip":       "🤷 Not sure / skip",
+	"message.ask.complaint": "📝 Please describe your complaint in detail. We will review it as soon as possible.",
+	"message.ask.review":    "⭐ Please describe your review in detail. We truly value your opinion.",
+	"message.empty":         "✍️ Please write your message as text or send a photo, document, voice or video message.",
+	"attachment.failed":     "❌ Could not download the file. Please try sending another file.",
+	"attachment.too_large":  "❌ The file is larger than %d MB and will not be attached. Please send a smaller file.",
+	"attachment.received":   "📎 Files received: %d\n\nYou can send more files or text. When you are ready, press «✅ Submit».",
+	"contact.ask":           "📞 If you like, leave your phone number so that we can call you back.\n\nThis step is optional — you can press «%s».",
+	"contact.share":         "📱 Share my number",
+	"contact.own_only":      "❌ You can only share your own number with the «📱 Share my number» button.",
+	"contact.saved":         "✅ Your number has been saved.",
+	"contact.skipped":       "👌 Fine, we will send it without a number.",
+
+	"feedback.sent.complaint": "✅ Your complaint has been sent!\n\nWe will review it and take the necessary action.\n\nWould you like to send another request?",
+	"feedback.sent.review":    "✅ Your review has been sent!\n\nWe will review it and take the necessary action.\n\nWould you like to send another request?",
+	"feedback.ticket":         "🎫 Request number: %s\nYou can check its status with the /mystatus command.",
+
+	// Предпросмотр и отмена
+	"preview.title":        "👀 Please check your request:",
+	"preview.type":         "Type: %s",
+	"preview.anonymous":    "🕶 Anonymous request",
+	"preview.department":   "🏢 Department: %s",
+	"preview.phone":        "📞 Phone: %s",
+	"preview.rating":       "⭐ Rating: %s",
+	"preview.files":        "📎 Files: %d",
+	"preview.message":      "💬 Text:",
+	"preview.footer":       "If everything is correct, press «✅ Submit».",
+	"preview.type_changed": "🔄 Request type changed: %s",
+	"cancel.nothing":       "ℹ️ There is no active request to cancel.",
+	"cancel.done":          "❌ The request was not sent, the entered data has been deleted.",
+
+	// Дополнительные вопросы анкеты
+	"form.date_hint":     "📅 Type the date as DD.MM.YYYY or press a button.",
+	"form.text_required": "✍️ Please type your answer as text.",
+	"form.min_length":    "✍️ The answer must be at least %d characters long.",
+	"form.max_length":    "✍️ The answer must be at most %d characters long.",
+	"form.pattern":       "✍️ The answer format is not valid, please try again.",
+	"form.number":        "🔢 Please enter a number.",
+	"form.integer":       "🔢 Please enter a whole number.",
+	"form.min":           "🔢 The number must be at least %s.",
+	"form.max":           "🔢 The number must be at most %s.",
+	"form.date_format":   "📅 Enter the date as DD.MM.YYYY, for example 05.03.2025.",
+	"form.date_future":   "📅 The date cannot be in the future.",
+	"form.date_too_old":  "📅 The date cannot be more than %d days ago.",
+	"form.yes_no":        "👇 Please choose «Yes» or «No».",
+	"form.unsupported":   "❌ This step cannot be answered.",
+	"form.answer.yes":    "Yes",
+	"form.answer.no":     "No",
+	"form.reload_failed": "❌ Could not load the form: %s",
+	"form.reloaded":      "✅ Form reloaded (%s)",
+	"form.summary":       "📋 Form: %s",
+	"form.reload_hint":   "Reload: /form_reload",
+
+	// Оценка отзыва
+	"rating.ask":           "⭐ Please rate us from 1 to 5:",
+	"rating.invalid":       "⭐ Please choose a number from 1 to 5.",
+	"rating.stats.empty":   "⭐ No ratings yet",
+	"rating.stats.average": "⭐ Average rating: %.1f (%d ratings)",
+	"rating.stats.trend":   "📈 Last %d days: %.1f (previous period: %.1f) %s %+.1f",
+	"rating.stats.recent":  "📈 Last %d days: %.1f",
+
+	// Статус обращений для пациента
+	"mystatus.empty":           "📋 You have no requests yet.",
+	"mystatus.title":           "📋 Your requests (page %d of %d):",
+	"mystatus.item":            "🎫 %s · %s · %s\nStatus: %s\nUpdated: %s",
+	"status.new":               "🆕 Received",
+	"status.acknowledged":      "👀 Acknowledged",
+	"status.in_progress":       "🔄 In progress",
+	"status.awaiting_patient":  "⏳ Awaiting patient reply",
+	"status.resolved":          "✅ Resolved",
+	"status.rejected":          "🚫 Rejected",
+	"status.reopened":          "🔁 Reopened",
+	"status.usage":             "Usage: /status <ticket> <status> [comment]\nStatuses: new, acknowledged, in_progress, awaiting_patient, resolved, rejected, reopened",
+	"status.changed":           "✅ Status of %s: %s → %s",
+	"status.transition_denied": "❌ This status change is not allowed from the current status",
+	"status.history":           "📜 Status history:",
+	"status.history_empty":     "The status has not changed yet.",
+
+	// Переписка по обращению
+	"thread.reply_usage":     "Usage: /reply <request number> [reply text]\nExample: /reply K7QM-3XPA Hello! We have reviewed your complaint...",
+	"thread.usage":           "Usage: /thread <request number>",
+	"thread.not_found":       "❌ No request with this number",
+	"thread.title":           "💬 Conversation for request %s",
+	"thread.empty":           "No messages yet.",
+	"thread.author.patient":  "👤 Patient",
+	"thread.author.staff":    "🏥 Staff",
+	"thread.button.reply":    "↩️ Reply",
+	"thread.ask":             "✍️ Please write your reply for request %s in a single message.\nCancel: /cancel",
+	"thread.text_required":   "✍️ Please type your reply as text.",
+	"thread.delivery_failed": "❌ Could not deliver the message. Please try again later.",
+	"thread.staff_message":   "🏥 Hospital reply to your request %s:\n\n%s\n\nTo answer, press «↩️ Reply» or reply to this message.",
+	"thread.sent_to_patient": "✅ Reply sent to the author of request %s",
+	"thread.patient_message": "💬 The patient wrote about request %s:\n\n%s",
+	"thread.sent_to_staff":   "✅ Your message about request %s has been passed on to staff.",
+
+	// Карточка обращения в чате сотрудников
+	"card.title":            "🆕 Request %s",
+	"card.type":             "Type: %s",
+	"card.rating":           "⭐ Rating: %s",
+	"card.department":       "🏢 Department: %s",
+	"card.location":         "📍 Location: %s",
+	"card.sender":           "👤 Sender: %s",
+	"card.sender_anonymous": "👤 Sender: anonymous",
+	"card.phone":            "📞 Phone: %s",
+	"card.date":             "📅 Date: %s",
+	"card.files":            "📎 Files: %d",
+	"card.status":           "📌 Status: %s",
+	"card.assignee":         "🙋 Assignee: %s",
+	"card.due":              "⏰ Reply due: %s",
+	"card.overdue":          "🔥 Reply overdue since %s",
+	"card.taken":            "The request is assigned to you",
+	"card.processed":        "The request is marked as processed",
+
+	// Список обращений для сотрудников
+	"inbox.usage":             "Usage: /list [complaint|review] [open|all|new|acknowledged|in_progress|awaiting_patient|resolved|rejected|reopened] [mine] [from date] [to date] [department code]\nDates: 2026-01-31 or 31.01.2026\n/inbox - the same, but shows only open feedback by default",
+	"inbox.title":             "📥 Feedback: %d (page %d of %d)",
+	"inbox.empty":             "📭 No feedback matches the selected filters",
+	"inbox.filters":           "Type: %s · Status: %s · Period: %s · Department: %s",
+	"inbox.item":              "%d. 🎫 %s · %s · %s\n%s\n%s",
+	"inbox.all":               "all",
+	"inbox.status.open":       "open",
+	"inbox.period.range":      "%s - %s",
+	"inbox.period.from":       "from %s",
+	"inbox.period.to":         "until %s",
+	"inbox.filter_assignee":   "Assignee: %s",
+	"inbox.button.type":       "🔀 Type",
+	"inbox.button.status":     "📌 Status",
+	"inbox.button.period":     "📅 Period",
+	"inbox.button.department": "🏢 Department",
+	"inbox.choose_department": "🏢 Choose a department:",
+	"inbox.updated":           "🕓 Updated: %s",
+	"inbox.history":           "🗂 Conversation:",
+	"inbox.status_changed":    "Status changed: %s",
+
+	// Назначение ответственных
+	"assign.usage":       "Usage: /assign <ticket> <user_id|me|auto>\nauto - the next staff member of the department in rotation",
+	"assign.not_staff":   "❌ This staff member cannot handle this feedback",
+	"assign.done":        "✅ Feedback %s assigned to %s",
+	"assign.notify":      "📌 Feedback %s has been assigned to you",
+	"assign.choose":      "👤 Choose who is responsible for feedback %s:",
+	"assign.button.open": "📂 Open",
+	"assign.button.back": "⬅️ Back to feedback",
+
+	// Сроки ответа (SLA)
+	"sla.title":                "⏱ Response deadlines (SLA)",
+	"sla.default":              "Default: complaints — %d h, reviews — %d h (0 — no deadline)",
+	"sla.hours":                "%d business h",
+	"sla.business_hours":       "🕘 Business hours: %s, weekdays: %s",
+	"sla.holidays":             "📅 Holidays:",
+	"sla.holidays_empty":       "None",
+	"sla.chain":                "📣 Escalation chain (relative to the deadline):",
+	"sla.role.assignee":        "Assignee",
+	"sla.role.department_head": "Department head",
+	"sla.role.chief_physician": "Chief physician",
+	"sla.hint":                 "Set deadline: /sla_set <complaint|review|*> <department code|*> <hours>\nRemove: /sla_remove <complaint|review|*> <department code|*>\nHoliday: /holiday_add <YYYY-MM-DD> [name], /holiday_remove <YYYY-MM-DD>",
+	"sla.set_usage":            "Usage: /sla_set <complaint|review|*> <department code|*> <hours>\nExample: /sla_set complaint cardio 16",
+	"sla.saved":                "✅ Deadline saved",
+	"sla.remove_usage":         "Usage: /sla_remove <complaint|review|*> <department code|*>",
+	"sla.not_found":            "❌ Not found",
+	"sla.removed":              "✅ Removed",
+	"sla.holiday_usage":        "Usage: /holiday_add <YYYY-MM-DD> [name] or /holiday_remove <YYYY-MM-DD>",
+	"sla.holiday_saved":        "✅ %s marked as a non-working day",
+	"sla.holiday_removed":      "✅ Holiday removed",
+	"sla.warning":              "⏰ Reply to feedback %s is due soon: %s",
+	"sla.overdue":              "🔥 Reply to feedback %s is overdue, it was due %s",
+	"sla.level":                "Escalation %d: %s",
+	"sla.email_subject":        "Feedback %s escalated",
+	"sla.escalations":          "📣 Escalations:",
+
+	// Сводки руководству по почте
+	"digest.title.daily":       "📊 Daily feedback digest",
+	"digest.title.weekly":      "📊 Weekly feedback digest",
+	"digest.subject.daily":     "Feedback digest for %s",
+	"digest.subject.weekly":    "Weekly feedback digest as of %s",
+	"digest.period":            "Period: %s — %s",
+	"digest.new":               "🆕 New in period: %d",
+	"digest.unresolved":        "📂 Unresolved: %d",
+	"digest.overdue":           "🔥 Overdue: %d",
+	"digest.more":              "…and %d more",
+	"digest.none":              "None",
+	"digest.by_department":     "🏢 By department",
+	"digest.column.department": "Department",
+	"digest.column.new":        "New",
+	"digest.column.open":       "Open",
+	"digest.column.overdue":    "Overdue",
+	"digest.due":               "due",
+	"digest.footer":            "This is an automatic digest from the hospital feedback system.",
+
+	// Выгрузка
+	"export.usage":             "Usage: /export [csv|xlsx] [complaint|review] [open|new|acknowledged|in_progress|awaiting_patient|resolved|rejected|reopened] [mine|assignee=ID] [from date] [to date] [department code]\nDates: 2026-01-31 or 31.01.2026. Defaults to xlsx with all feedback",
+	"export.empty":             "📭 No feedback to export",
+	"export.error":             "❌ Failed to build the export",
+	"export.too_large":         "❌ The export exceeds 50 MB - narrow the period or use the HTTP API",
+	"export.caption":           "📤 Export: %d feedback items",
+	"export.yes":               "yes",
+	"export.column.id":         "#",
+	"export.column.ticket":     "Code",
+	"export.column.created_at": "Created",
+	"export.column.updated_at": "Updated",
+	"export.column.type":       "Type",
+	"export.column.status":     "Status",
+	"export.column.department": "Department",
+	"export.column.location":   "Location",
+	"export.column.rating":     "Rating",
+	"export.column.anonymous":  "Anonymous",
+	"export.column.user_id":    "Telegram ID",
+	"export.column.username":   "Username",
+	"export.column.first_name": "First name",
+	"export.column.last_name":  "Last name",
+	"export.column.phone":      "Phone",
+	"export.column.assignee":   "Assignee",
+	"export.column.due_at":     "Response due",
+	"export.column.answers":    "Form answers",
+	"export.column.message":    "Text",
+
+	// Антиспам
+	"antispam.rate_hour":        "🙏 Thank you for your feedback! You can send at most %d submissions per hour. Please try again a little later.",
+	"antispam.rate_day":         "🙏 Thank you for your feedback! You can send at most %d submissions per day. Please try again tomorrow.",
+	"antispam.too_short":        "✏️ Please describe the situation in a bit more detail - at least %d characters, so we can look into it.",
+	"antispam.too_long":         "✏️ Your message is too long: %d characters, the maximum is %d. Please shorten it and send it again.",
+	"antispam.duplicate":        "ℹ️ We have already received a similar submission from you and passed it to our staff. There is no need to send it again - we will reply.",
+	"antispam.title":            "🛡 Anti-spam",
+	"antispam.limits":           "Limits: %d per hour, %d per day, text length from %d to %d characters, duplicates checked within %s",
+	"antispam.counters":         "Rejected (today / 7 days / 30 days):",
+	"antispam.counter":          "• %s: %d / %d / %d",
+	"antispam.reason.rate_hour": "hourly limit",
+	"antispam.reason.rate_day":  "daily limit",
+	"antispam.reason.too_short": "too short",
+	"antispam.reason.too_long":  "too long",
+	"antispam.reason.duplicate": "duplicates",
+
+	// Скрытие персональных идентификаторов
+	"redact.iin":   "[IIN hidden]",
+	"redact.card":  "[card •••• %s]",
+	"redact.phone": "[phone …%s]",
+	"redact.email": "[email hidden]",
+
+	// Статистика
+	"stats.error":                 "❌ Could not load statistics",
+	"stats.summary":               "📊 Request statistics for all time\n\n📝 Complaints: %d\n⭐ Reviews: %d\n📈 Total: %d",
+	"stats.by_department":         "🏢 By department:",
+	"stats.scope":                 "🏢 Department: %s",
+	"stats.assignee":              "🙋 Assignee: %s",
+	"stats.usage":                 "Usage: /stats [me|user_id] [today|7d|30d|all | from date [to date]]\nDates: 2026-01-31 or 31.01.2026, at most %d days",
+	"stats.not_specified":         "Not specified",
+	"stats.period.today":          "Today",
+	"stats.period.7d":             "7 days",
+	"stats.period.30d":            "30 days",
+	"stats.period.custom":         "📅 Range…",
+	"stats.period.all":            "All time",
+	"stats.period.title":          "📊 Statistics: %s (%s — %s)",
+	"stats.period.total":          "📈 Total: %d (%s vs previous period: %d)",
+	"stats.period.complaints":     "📝 Complaints: %d (%s)",
+	"stats.period.reviews":        "⭐ Reviews: %d (%s)",
+	"stats.period.ask":            "Enter the period: start and end date, e.g. 2026-01-01 2026-01-31 or 01.01.2026 31.01.2026",
+	"stats.period.invalid":        "❌ Could not parse the dates, or the period is longer than %d days. Example: 2026-01-01 2026-01-31",
+	"stats.median_first_response": "⏱ Median time to first response: %s",
+	"stats.median_resolution":     "✅ Median time to resolution: %s",
+	"stats.no_data":               "no data",
+	"stats.was":                   "(was %s)",
+	"stats.duration.days":         "%dd %dh",
+	"stats.duration.hours":        "%dh %dm",
+	"stats.duration.minutes":      "%d min",
+	"stats.by_status":             "📌 By status:",
+	"stats.by_hour":               "🕐 By hour:",
+	"stats.charts":                "📈 Charts",
+	"stats.charts_error":          "❌ Failed to render charts",
+	"chart.trend":                 "Complaints and reviews per day",
+	"chart.status":                "Feedback by status",
+	"chart.department":            "Feedback by department",
+	"chart.no_data":               "No feedback in this period",
+
+	// Сотрудники и роли
+	"role.super_admin":           "👑 Super admin",
+	"role.handler":               "🛠 Handler",
+	"role.viewer":                "👁 Viewer",
+	"role.department_head":       "🏥 Department head",
+	"admins.title":               "👥 Staff:",
+	"admins.empty":               "No staff in the database (ADMIN_USER_ID is always a super admin).",
+	"admins.hint":                "Add/update: /admin_add <user_id> <role> [department code] [name]\nRoles: super_admin, handler, viewer, department_head\nRemove: /admin_remove <user_id>\nEscalation email: /admin_email <user_id> [email]",
+	"admins.add_usage":           "Usage: /admin_add <user_id> <role> [department code] [name]\nRoles: super_admin, handler, viewer, department_head\nExample: /admin_add 123456789 department_head cardio Askar",
+	"admins.department_required": "❌ Please give a department code for the department_head role",
+	"admins.saved":               "✅ Staff member %d saved: %s",
+	"admins.remove_usage":        "Usage: /admin_remove <user_id>",
+	"admins.remove_self":         "❌ You cannot remove yourself",
+	"admins.not_found":           "❌ Staff member not found",
+	"admins.removed":             "✅ Staff member removed",
+	"admins.email_usage":         "Usage: /admin_email <user_id> [email]\nOmit the address to remove it",
+	"admins.email_saved":         "✅ Staff email saved",
+
+	// Управление отделениями
+	"departments.error":        "❌ Could not load the department list",
+	"departments.empty":        "🏢 No departments have been added yet.\n\nAdd: /dept_add <code> <name>",
+	"departments.title":        "🏢 Departments:",
+	"departments.hint":         "Add/update: /dept_add <code> <name>\nHide: /dept_remove <code>",
+	"departments.add_usage":    "Usage: /dept_add <code> <name>\nExample: /dept_add cardio Cardiology",
+	"departments.bad_code":     "❌ The code may contain only Latin letters, digits and _ (up to 32 characters)",
+	"departments.save_error":   "❌ Could not save the department",
+	"departments.saved":        "✅ Department saved: %s — %s",
+	"departments.remove_usage": "Usage: /dept_remove <code>",
+	"departments.remove_error": "❌ Could not hide the department",
+	"departments.not_found":    "❌ No department with this code",
+	"departments.removed":      "✅ Department hidden",
+
+	"help.text": `ℹ️ Help
+
+📝 How to submit a complaint:
+1. Press "📝 Submit a complaint"
+2. Describe your complaint in detail
+3. Send the message
+
+⭐ How to leave a review:
+1. Press "⭐ Leave a review"
+2. Describe your review in detail
+3. Send the message
+
+👀 Before sending, the bot shows your request: you can edit the text, change the type or cancel it.
+❌ You can stop at any time with the /cancel command.
+📋 Check the status of your requests with the /mystatus command.
+💬 If staff reply to your request, you can answer with the «↩️ Reply» button.
+🌐 Change the language with the /language command.
+
+📧 Your request is sent to the hospital administration by email.
+
+🔙 Use /start or /menu to return to the main menu`,
+
+	// Письмо сотрудникам
+	"email.subject":          "New request: %s (%s)",
+	"email.body":             "🏥 New request in the feedback system\n\n%s\n📝 Request type: %s\n%s🏢 Department: %s\n📍 Location: %s\n📅 Date: %s\n\n💬 Message:\n%s\n%s%s\n---\nThis is an automatic notification from the hospital feedback system.",
+	"email.sender_anonymous": "👤 Sender: anonymous\n",
+	"email.sender":           "👤 Sender:\n• Name: %s %s\n• Username: @%s\n• ID: %d\n• Phone: %s\n",
+	"email.rating":           "⭐ Rating: %s (%d/5)",
+	"email.answers":          "📋 Additional details:",
+	"email.attachments":      "📎 Attachments:",
+	"email.attachment":       "• %s (%s, %d KB)",
+	"email.not_specified":    "not specified",
+}
@@ -0,0 +1,390 @@
+package main
+
+// catalogKazakh - тексты на казахском; этот язык используется, если перевода нет
+var catalogKazakh = Catalog{
+	// Главное меню и общие кнопки
+	"menu.title":            "🏥 Аурухананың кері байланыс жүйесінің басты мәзірі\n\nӘрекетті таңдаңыз:",
+	"button.complaint":      "📝 Шағым жіберу",
+	"button.review":         "⭐ Пікір қалдыру",
+	"button.help":           "❓ Көмек",
+	"button.stats":          "📊 Статистика",
+	"button.language":       "🌐 Тіл",
+	"button.main_menu":      "🏠 Басты мәзір",
+	"button.new_request":    "🏥 Жаңа өтініш",
+	"button.submit":         "✅ Жіберу",
+	"button.skip":           "⏭ Өткізу",
+	"button.yes":            "✅ Иә",
+	"button.no":             "❌ Жоқ",
+	"button.today":          "Бүгін",
+	"button.yesterday":      "Кеше",
+	"button.edit_text":      "✏️ Мәтінді өзгерту",
+	"button.change_type":    "🔄 Түрін өзгерту",
+	"button.mystatus":       "📋 Менің өтініштерім",
+	"button.inbox":          "📥 Өтініштер",
+	"button.back_to_list":   "⬅️ Тізімге",
+	"button.assign":         "👤 Тағайындау",
+	"button.my_tickets":     "🙋 Маған тағайындалғандар",
+	"button.prev":           "⬅️ Артқа",
+	"button.next":           "Әрі қарай ➡️",
+	"button.take":           "🙋 Алу",
+	"button.mark_processed": "✅ Қаралды",
+	"button.cancel":         "❌ Бас тарту",
+
+	"type.complaint": "Шағым",
+	"type.review":    "Пікір",
+	"type.ask":       "Өтініш түрін таңдаңыз:",
+	"type.invalid":   "Өтініш, ‘шағым’ немесе ‘пікір’ таңдаңыз",
+
+	"access.denied":       "❌ Сізде бұл әрекетке қолжетімділік жоқ",
+	"access.stats_denied": "❌ Сізде статистикаға қолжетімділік жоқ",
+	"error.save":          "❌ Сақтау кезінде қате орын алды. Кейінірек қайталап көріңіз.",
+	"error.generic":       "❌ Қате орын алды. Кейінірек қайталап көріңіз.",
+	"choose_button":       "👇 Төмендегі батырмалардың бірін таңдаңыз.",
+
+	"language.ask":   "🌐 Тілді таңдаңыз:",
+	"language.saved": "✅ Тіл өзгертілді: %s",
+
+	"location.ward": "Палата №%s",
+
+	// Шаги обращения
+	"anonymity.ask":         "Өтінішті атыңызбен жібересіз бе, әлде анонимді түрде ме?\n\n🕶 Анонимді өтініште сіздің атыңыз бен аккаунтыңыз қызметкерлерге көрсетілмейді, бірақ біз сізге осы бот арқылы жауап бере аламыз.",
+	"anonymity.no":          "👤 Атыммен жіберу",
+	"anonymity.yes":         "🕶 Анонимді жіберу",
+	"department.ask":        "🏢 Өтінішіңіз қай бөлімшеге қатысты?",
+	"department.skip":       "🤷 Білмеймін / өткізу",
+	"message.ask.complaint": "📝 Өтініш, шағымыңызды толық сипаттаңыз. Біз оны мүмкіндігінше қысқа мерзімде қарастырамыз.",
+	"message.ask.review":    "⭐ Өтініш, пікіріңізді толық сипаттаңыз. Біз сіздің пікіріңізді жоғары бағалаймыз.",
+	"message.empty":         "✍️ Өтініш, хабарламаңызды мәтінмен жазыңыз немесе фото, құжат, дауыстық не бейне хабарлама жіберіңіз.",
+	"attachment.failed":     "❌ Файлды жүктеу мүмкін болмады. Басқа файл жіберіп көріңіз.",
+	"attachment.too_large":  "❌ Файл %d МБ-тан үлкен, сондықтан тіркелмейді. Кішірек файл жіберіңіз.",
+	"attachment.received":   "📎 Қабылданған файлдар: %d\n\nТағы файл немесе мәтін жіберуге болады. Дайын болсаңыз, «✅ Жіберу» батырмасын басыңыз.",
+	"contact.ask":           "📞 Қажет болса, біз сізге қоңырау шалуымыз үшін телефон нөміріңізді қалдырыңыз.\n\nБұл қадам міндетті емес — «%s» батырмасын басуға болады.",
+	"contact.share":         "📱 Нөмірді бөлісу",
+	"contact.own_only":      "❌ Тек өз нөміріңізді «📱 Нөмірді бөлісу» батырмасы арқылы жібере аласыз.",
+	"contact.saved":         "✅ Нөміріңіз сақталды.",
+	"contact.skipped":       "👌 Жақсы, нөмірсіз жібереміз.",
+
+	"feedback.sent.complaint": "✅ Сіздің шағымыңыз сәтті жіберілді!\n\nБіз сіздің шағымыңызды қарап, қажетті шараларды қабылдаймыз.\n\nТағы бір өтініш жібергіңіз келе ме?",
+	"feedback.sent.review":    "✅ Сіздің пікіріңіз сәтті жіберілді!\n\nБіз сіздің пікіріңізді қарап, қажетті шараларды қабылдаймыз.\n\nТағы бір өтініш жібергіңіз келе ме?",
+	"feedback.ticket":         "🎫 Өтініш нөмірі: %s\nОның күйін /mystatus пәрменімен тексеруге болады.",
+
+	// Предпросмотр и отмена
+	"preview.title":        "👀 Өтінішіңізді тексеріңіз:",
+	"preview.type":         "Түрі: %s",
+	"preview.anonymous":    "🕶 Анонимді өтініш",
+	"preview.department":   "🏢 Бөлім: %s",
+	"preview.phone":        "📞 Телефон: %s",
+	"preview.rating":       "⭐ Баға: %s",
+	"preview.files":        "📎 Файлдар: %d",
+	"preview.message":      "💬 Мәтін:",
+	"preview.footer":       "Бәрі дұрыс болса, «✅ Жіберу» батырмасын басыңыз.",
+	"preview.type_changed": "🔄 Өтініш түрі өзгертілді: %s",
+	"cancel.nothing":       "ℹ️ Тоқтататын белсенді өтініш жоқ.",
+	"cancel.done":          "❌ Өтініш жіберілмеді, енгізілген деректер өшірілді.",
+
+	// Дополнительные вопросы анкеты
+	"form.date_hint":     "📅 Күнді КК.АА.ЖЖЖЖ пішімінде жазыңыз немесе батырманы басыңыз.",
+	"form.text_required": "✍️ Жауапты мәтінмен жазыңыз.",
+	"form.min_length":    "✍️ Жауап кемінде %d таңбадан тұруы керек.",
+	"form.max_length":    "✍️ Жауап %d таңбадан аспауы керек.",
+	"form.pattern":       "✍️ Жауап пішімі дұрыс емес, қайталап көріңіз.",
+	"form.number":        "🔢 Санды енгізіңіз.",
+	"form.integer":       "🔢 Бүтін санды енгізіңіз.",
+	"form.min":           "🔢 Сан %s кем болмауы керек.",
+	"form.max":           "🔢 Сан %s аспауы керек.",
+	"form.date_format":   "📅 Күнді КК.АА.ЖЖЖЖ пішімінде енгізіңіз, мысалы 05.03.2025.",
+	"form.date_future":   "📅 Күн болашақта болмауы керек.",
+	"form.date_too_old":  "📅 Күн %d күннен ескі болмауы керек.",
+	"form.yes_no":        "👇 «Иә» немесе «Жоқ» таңдаңыз.",
+	"form.unsupported":   "❌ Бұл қадамға жауап беру мүмкін емес.",
+	"form.answer.yes":    "Иә",
+	"form.answer.no":     "Жоқ",
+	"form.reload_failed": "❌ Сауалнаманы жүктеу мүмкін болмады: %s",
+	"form.reloaded":      "✅ Сауалнама қайта жүктелді (%s)",
+	"form.summary":       "📋 Сауалнама: %s",
+	"form.reload_hint":   "Қайта жүктеу: /form_reload",
+
+	// Оценка отзыва
+	"rating.ask":           "⭐ Бізге 1-ден 5-ке дейін баға беріңіз:",
+	"rating.invalid":       "⭐ 1-ден 5-ке дейінгі санды таңдаңыз.",
+	"rating.stats.empty":   "⭐ Бағалар әлі жоқ",
+	"rating.stats.average": "⭐ Орташа баға: %.1f (%d баға)",
+	"rating.stats.trend":   "📈 Соңғы %d күн: %.1f (алдыңғы кезең: %.1f) %s %+.1f",
+	"rating.stats.recent":  "📈 Соңғы %d күн: %.1f",
+
+	// Статус обращений для пациента
+	"mystatus.empty":           "📋 Сізде әлі өтініштер жоқ.",
+	"mystatus.title":           "📋 Сіздің өтініштеріңіз (%d/%d бет):",
+	"mystatus.item":            "🎫 %s · %s · %s\nКүйі: %s\nСоңғы өзгеріс: %s",
+	"status.new":               "🆕 Қабылданды",
+	"status.acknowledged":      "👀 Қарауға алынды",
+	"status.in_progress":       "🔄 Жұмыста",
+	"status.awaiting_patient":  "⏳ Пациенттің жауабын күтуде",
+	"status.resolved":          "✅ Шешілді",
+	"status.rejected":          "🚫 Қабылданбады",
+	"status.reopened":          "🔁 Қайта ашылды",
+	"status.usage":             "Қолданылуы: /status <өтініш нөмірі> <күйі> [түсініктеме]\nКүйлер: new, acknowledged, in_progress, awaiting_patient, resolved, rejected, reopened",
+	"status.changed":           "✅ %s өтінішінің күйі: %s → %s",
+	"status.transition_denied": "❌ Ағымдағы күйден таңдалған күйге өтуге болмайды",
+	"status.history":           "📜 Күйлер тарихы:",
+	"status.history_empty":     "Күйі әлі өзгерген жоқ.",
+
+	// Переписка по обращению
+	"thread.reply_usage":     "Қолданылуы: /reply <өтініш нөмірі> [жауап мәтіні]\nМысалы: /reply K7QM-3XPA Сәлеметсіз бе! Біз сіздің шағымыңызды қарадық...",
+	"thread.usage":           "Қолданылуы: /thread <өтініш нөмірі>",
+	"thread.not_found":       "❌ Мұндай нөмірмен өтініш табылмады",
+	"thread.title":           "💬 %s өтініші бойынша хат алмасу",
+	"thread.empty":           "Хабарламалар әлі жоқ.",
+	"thread.author.patient":  "👤 Пациент",
+	"thread.author.staff":    "🏥 Қызметкер",
+	"thread.button.reply":    "↩️ Жауап беру",
+	"thread.ask":             "✍️ %s өтініші бойынша жауабыңызды бір хабарламамен жазыңыз.\nБас тарту: /cancel",
+	"thread.text_required":   "✍️ Жауапты мәтінмен жазыңыз.",
+	"thread.delivery_failed": "❌ Хабарламаны жеткізу мүмкін болмады. Кейінірек қайталап көріңіз.",
+	"thread.staff_message":   "🏥 %s өтінішіңізге аурухана жауабы:\n\n%s\n\nЖауап беру үшін «↩️ Жауап беру» батырмасын басыңыз немесе осы хабарламаға жауап жазыңыз.",
+	"thread.sent_to_patient": "✅ Жауап %s өтінішінің авторына жіберілді",
+	"thread.patient_message": "💬 Пациент %s өтініші бойынша жазды:\n\n%s",
+	"thread.sent_to_staff":   "✅ Хабарламаңыз %s өтініші бойынша қызметкерлерге жіберілді.",
+
+	// Карточка обращения в чате сотрудников
+	"card.title":            "🆕 Өтініш %s",
+	"card.type":             "Түрі: %s",
+	"card.rating":           "⭐ Баға: %s",
+	"card.department":       "🏢 Бөлімше: %s",
+	"card.location":         "📍 Орны: %s",
+	"card.sender":           "👤 Жіберуші: %s",
+	"card.sender_anonymous": "👤 Жіберуші: анонимді",
+	"card.phone":            "📞 Телефон: %s",
+	"card.date":             "📅 Күні: %s",
+	"card.files":            "📎 Файлдар: %d",
+	"card.status":           "📌 Күйі: %s",
+	"card.assignee":         "🙋 Жауапты: %s",
+	"card.due":              "⏰ Жауап беру мерзімі: %s",
+	"card.overdue":          "🔥 Жауап беру мерзімі өтті: %s",
+	"card.taken":            "Өтініш сізге бекітілді",
+	"card.processed":        "Өтініш қаралды деп белгіленді",
+
+	// Список обращений для сотрудников
+	"inbox.usage":             "Қолданылуы: /list [complaint|review] [open|all|new|acknowledged|in_progress|awaiting_patient|resolved|rejected|reopened] [mine] [басталу күні] [аяқталу күні] [бөлімше коды]\nКүндер: 2026-01-31 немесе 31.01.2026\n/inbox - дәл солай, бірақ әдепкі бойынша тек ашық өтініштер",
+	"inbox.title":             "📥 Өтініштер: %d (%d/%d бет)",
+	"inbox.empty":             "📭 Таңдалған сүзгілер бойынша өтініштер жоқ",
+	"inbox.filters":           "Түрі: %s · Күйі: %s · Кезең: %s · Бөлімше: %s",
+	"inbox.item":              "%d. 🎫 %s · %s · %s\n%s\n%s",
+	"inbox.all":               "барлығы",
+	"inbox.status.open":       "ашық",
+	"inbox.period.range":      "%s - %s",
+	"inbox.period.from":       "%s бастап",
+	"inbox.period.to":         "%s дейін",
+	"inbox.filter_assignee":   "Жауапты: %s",
+	"inbox.button.type":       "🔀 Түрі",
+	"inbox.button.status":     "📌 Күйі",
+	"inbox.button.period":     "📅 Кезең",
+	"inbox.button.department": "🏢 Бөлімше",
+	"inbox.choose_department": "🏢 Бөлімшені таңдаңыз:",
+	"inbox.updated":           "🕓 Өзгертілді: %s",
+	"inbox.history":           "🗂 Хат алмасу:",
+	"inbox.status_changed":    "Күйі өзгертілді: %s",
+
+	// Назначение ответственных
+	"assign.usage":       "Қолданылуы: /assign <өтініш нөмірі> <user_id|me|auto>\nauto - бөлімшенің кезектегі қызметкері",
+	"assign.not_staff":   "❌ Бұл қызметкер осы өтінішпен жұмыс істей алмайды",
+	"assign.done":        "✅ %s өтініші тағайындалды: %s",
+	"assign.notify":      "📌 Сізге %s өтініші тағайындалды",
+	"assign.choose":      "👤 %s өтінішіне жауаптыны таңдаңыз:",
+	"assign.button.open": "📂 Ашу",
+	"assign.button.back": "⬅️ Өтінішке",
+
+	// Сроки ответа (SLA)
+	"sla.title":                "⏱ Жауап беру мерзімдері (SLA)",
+	"sla.default":              "Әдепкі: шағымдар — %d сағ, пікірлер — %d сағ (0 — мерзімсіз)",
+	"sla.hours":                "%d жұмыс сағаты",
+	"sla.business_hours":       "🕘 Жұмыс уақыты: %s, апта күндері: %s",
+	"sla.holidays":             "📅 Мереке күндері:",
+	"sla.holidays_empty":       "Берілмеген",
+	"sla.chain":                "📣 Эскалация тізбегі (мерзімге қатысты):",
+	"sla.role.assignee":        "Жауапты",
+	"sla.role.department_head": "Бөлімше меңгерушісі",
+	"sla.role.chief_physician": "Бас дәрігер",
+	"sla.hint":                 "Мерзім беру: /sla_set <complaint|review|*> <бөлімше коды|*> <сағат>\nЖою: /sla_remove <complaint|review|*> <бөлімше коды|*>\nМереке: /holiday_add <ЖЖЖЖ-АА-КК> [атауы], /holiday_remove <ЖЖЖЖ-АА-КК>",
+	"sla.set_usage":            "Қолданылуы: /sla_set <complaint|review|*> <бөлімше коды|*> <сағат>\nМысалы: /sla_set complaint cardio 16",
+	"sla.saved":                "✅ Жауап беру мерзімі сақталды",
+	"sla.remove_usage":         "Қолданылуы: /sla_remove <complaint|review|*> <бөлімше коды|*>",
+	"sla.not_found":            "❌ Табылмады",
+	"sla.removed":              "✅ Жойылды",
+	"sla.holiday_usage":        "Қолданылуы: /holiday_add <ЖЖЖЖ-АА-КК> [атауы] немесе /holiday_remove <ЖЖЖЖ-АА-КК>",
+	"sla.holiday_saved":        "✅ %s жұмыс емес күн ретінде белгіленді",
+	"sla.holiday_removed":      "✅ Мереке күні жойылды",
+	"sla.warning":              "⏰ %s өтінішіне жауап беру мерзімі жақында бітеді: %s",
+	"sla.overdue":              "🔥 %s өтінішіне жауап кешіктірілді, мерзімі %s болған",
+	"sla.level":                "Эскалация %d: %s",
+	"sla.email_subject":        "%s өтінішінің эскалациясы",
+	"sla.escalations":          "📣 Эскалациялар:",
+
+	// Сводки руководству по почте
+	"digest.title.daily":       "📊 Өтініштердің күнделікті жиынтығы",
+	"digest.title.weekly":      "📊 Өтініштердің апталық жиынтығы",
+	"digest.subject.daily":     "%s күнгі өтініштер жиынтығы",
+	"digest.subject.weekly":    "%s күнгі апталық өтініштер жиынтығы",
+	"digest.period":            "Кезең: %s — %s",
+	"digest.new":               "🆕 Кезеңдегі жаңалары: %d",
+	"digest.unresolved":        "📂 Шешілмегені: %d",
+	"digest.overdue":           "🔥 Мерзімі өткені: %d",
+	"digest.more":              "…тағы %d",
+	"digest.none":              "Жоқ",
+	"digest.by_department":     "🏢 Бөлімшелер бойынша",
+	"digest.column.department": "Бөлімше",
+	"digest.column.new":        "Жаңа",
+	"digest.column.open":       "Ашық",
+	"digest.column.overdue":    "Мерзімі өткен",
+	"digest.due":               "мерзімі",
+	"digest.footer":            "Бұл аурухананың кері байланыс жүйесінің автоматты жиынтығы.",
+
+	// Выгрузка
+	"export.usage":             "Қолданылуы: /export [csv|xlsx] [complaint|review] [open|new|acknowledged|in_progress|awaiting_patient|resolved|rejected|reopened] [mine|assignee=ID] [басталу күні] [аяқталу күні] [бөлімше коды]\nКүндер: 2026-01-31 немесе 31.01.2026. Әдепкі бойынша - барлық өтініштер xlsx форматында",
+	"export.empty":             "📭 Жүктеуге өтініштер жоқ",
+	"export.error":             "❌ Жүктеуді құру мүмкін болмады",
+	"export.too_large":         "❌ Жүктеу 50 МБ-тан асады - кезеңді қысқартыңыз немесе HTTP API қолданыңыз",
+	"export.caption":           "📤 Жүктеу: %d өтініш",
+	"export.yes":               "иә",
+	"export.column.id":         "№",
+	"export.column.ticket":     "Код",
+	"export.column.created_at": "Құрылған",
+	"export.column.updated_at": "Өзгертілген",
+	"export.column.type":       "Түрі",
+	"export.column.status":     "Мәртебе",
+	"export.column.department": "Бөлімше",
+	"export.column.location":   "Орын",
+	"export.column.rating":     "Баға",
+	"export.column.anonymous":  "Анонимді",
+	"export.column.user_id":    "Telegram ID",
+	"export.column.username":   "Username",
+	"export.column.first_name": "Аты",
+	"export.column.last_name":  "Тегі",
+	"export.column.phone":      "Телефон",
+	"export.column.assignee":   "Жауапты",
+	"export.column.due_at":     "Жауап беру мерзімі",
+	"export.column.answers":    "Сауалнама жауаптары",
+	"export.column.message":    "Мәтін",
+
+	// Антиспам
+	"antispam.rate_hour":        "🙏 Белсенділігіңізге рахмет! Соңғы бір сағатта %d өтініштен артық жіберуге болмайды. Біраздан кейін қайталап көріңіз.",
+	"antispam.rate_day":         "🙏 Белсенділігіңізге рахмет! Бір тәулікте %d өтініштен артық жіберуге болмайды. Ертең қайталап көріңіз.",
+	"antispam.too_short":        "✏️ Жағдайды толығырақ сипаттаңызшы - біз түсіну үшін кемінде %d таңба.",
+	"antispam.too_long":         "✏️ Хабарлама тым ұзын: %d таңба, ал ең көбі %d. Қысқартып, қайта жіберіңізші.",
+	"antispam.duplicate":        "ℹ️ Сізден ұқсас өтініш бұрын алынып, қызметкерлерге жіберілді. Оны қайта жіберудің қажеті жоқ - біз міндетті түрде жауап береміз.",
+	"antispam.title":            "🛡 Антиспам",
+	"antispam.limits":           "Шектеулер: сағатына %d, тәулігіне %d, мәтін ұзындығы %d-ден %d таңбаға дейін, қайталаулар %s ішінде ізделеді",
+	"antispam.counters":         "Қабылданбағаны (бүгін / 7 күн / 30 күн):",
+	"antispam.counter":          "• %s: %d / %d / %d",
+	"antispam.reason.rate_hour": "сағаттық шектеу",
+	"antispam.reason.rate_day":  "тәуліктік шектеу",
+	"antispam.reason.too_short": "тым қысқа",
+	"antispam.reason.too_long":  "тым ұзын",
+	"antispam.reason.duplicate": "қайталаулар",
+
+	// Скрытие персональных идентификаторов
+	"redact.iin":   "[ЖСН жасырылған]",
+	"redact.card":  "[карта •••• %s]",
+	"redact.phone": "[телефон …%s]",
+	"redact.email": "[email жасырылған]",
+
+	// Статистика
+	"stats.error":                 "❌ Статистиканы алу кезінде қате орын алды",
+	"stats.summary":               "📊 Барлық уақыттағы өтініштер статистикасы\n\n📝 Шағымдар: %d\n⭐ Пікірлер: %d\n📈 Барлығы: %d",
+	"stats.by_department":         "🏢 Бөлімшелер бойынша:",
+	"stats.scope":                 "🏢 Бөлімше: %s",
+	"stats.assignee":              "🙋 Жауапты: %s",
+	"stats.usage":                 "Қолданылуы: /stats [me|user_id] [today|7d|30d|all | басталу күні [аяқталу күні]]\nКүндер: 2026-01-31 немесе 31.01.2026, кезең - %d күннен аспайды",
+	"stats.not_specified":         "Көрсетілмеген",
+	"stats.period.today":          "Бүгін",
+	"stats.period.7d":             "7 күн",
+	"stats.period.30d":            "30 күн",
+	"stats.period.custom":         "📅 Кезең…",
+	"stats.period.all":            "Барлық уақыт",
+	"stats.period.title":          "📊 Статистика: %s (%s — %s)",
+	"stats.period.total":          "📈 Барлығы: %d (алдыңғы кезеңмен салыстырғанда %s: %d)",
+	"stats.period.complaints":     "📝 Шағымдар: %d (%s)",
+	"stats.period.reviews":        "⭐ Пікірлер: %d (%s)",
+	"stats.period.ask":            "Кезеңді енгізіңіз: басталу және аяқталу күні, мысалы 2026-01-01 2026-01-31 немесе 01.01.2026 31.01.2026",
+	"stats.period.invalid":        "❌ Күндерді тану мүмкін болмады немесе кезең %d күннен ұзақ. Мысалы: 2026-01-01 2026-01-31",
+	"stats.median_first_response": "⏱ Алғашқы жауапқа дейінгі медиана: %s",
+	"stats.median_resolution":     "✅ Шешілгенге дейінгі медиана: %s",
+	"stats.no_data":               "деректер жоқ",
+	"stats.was":                   "(бұрын %s)",
+	"stats.duration.days":         "%d күн %d сағ",
+	"stats.duration.hours":        "%d сағ %d мин",
+	"stats.duration.minutes":      "%d мин",
+	"stats.by_status":             "📌 Мәртебелер бойынша:",
+	"stats.by_hour":               "🕐 Сағаттар бойынша:",
+	"stats.charts":                "📈 Графиктер",
+	"stats.charts_error":          "❌ Графиктерді құру мүмкін болмады",
+	"chart.trend":                 "Күндер бойынша шағымдар мен пікірлер",
+	"chart.status":                "Мәртебелер бойынша өтініштер",
+	"chart.department":            "Бөлімшелер бойынша өтініштер",
+	"chart.no_data":               "Кезеңде өтініштер жоқ",
+
+	// Сотрудники и роли
+	"role.super_admin":           "👑 Супер-әкімші",
+	"role.handler":               "🛠 Өңдеуші",
+	"role.viewer":                "👁 Бақылаушы",
+	"role.department_head":       "🏥 Бөлімше меңгерушісі",
+	"admins.title":               "👥 Қызметкерлер:",
+	"admins.empty":               "Дерекқорда қызметкерлер жоқ (ADMIN_USER_ID әрқашан супер-әкімші).",
+	"admins.hint":                "Қосу/өзгерту: /admin_add <user_id> <рөл> [бөлімше коды] [аты]\nРөлдер: super_admin, handler, viewer, department_head\nЖою: /admin_remove <user_id>\nЭскалацияға арналған пошта: /admin_email <user_id> [email]",
+	"admins.add_usage":           "Қолданылуы: /admin_add <user_id> <рөл> [бөлімше коды] [аты]\nРөлдер: super_admin, handler, viewer, department_head\nМысалы: /admin_add 123456789 department_head cardio Асқар",
+	"admins.department_required": "❌ department_head рөлі үшін бөлімше кодын көрсетіңіз",
+	"admins.saved":               "✅ Қызметкер %d сақталды: %s",
+	"admins.remove_usage":        "Қолданылуы: /admin_remove <user_id>",
+	"admins.remove_self":         "❌ Өзіңізді жоюға болмайды",
+	"admins.not_found":           "❌ Мұндай қызметкер табылмады",
+	"admins.removed":             "✅ Қызметкер жойылды",
+	"admins.email_usage":         "Қолданылуы: /admin_email <user_id> [email]\nМекенжайсыз пошта жойылады",
+	"admins.email_saved":         "✅ Қызметкердің поштасы сақталды",
+
+	// Управление отделениями
+	"departments.error":        "❌ Бөлімшелер тізімін алу кезінде қате орын алды",
+	"departments.empty":        "🏢 Бөлімшелер әлі қосылмаған.\n\nҚосу: /dept_add <код> <атауы>",
+	"departments.title":        "🏢 Бөлімшелер:",
+	"departments.hint":         "Қосу/өзгерту: /dept_add <код> <атауы>\nЖасыру: /dept_remove <код>",
+	"departments.add_usage":    "Қолданылуы: /dept_add <код> <атауы>\nМысалы: /dept_add cardio Кардиология",
+	"departments.bad_code":     "❌ Код тек латын әріптерінен, сандардан және _ белгісінен тұруы керек (32 таңбаға дейін)",
+	"departments.save_error":   "❌ Бөлімшені сақтау кезінде қате орын алды",
+	"departments.saved":        "✅ Бөлімше сақталды: %s — %s",
+	"departments.remove_usage": "Қолданылуы: /dept_remove <код>",
+	"departments.remove_error": "❌ Бөлімшені жасыру кезінде қате орын алды",
+	"departments.not_found":    "❌ Мұндай кодпен бөлімше табылмады",
+	"departments.removed":      "✅ Бөлімше жасырылды",
+
+	"help.text": `ℹ️ Көмек
+
+📝 Шағым жіберу үшін:
+1. "📝 Шағым жіберу" батырмасын шертіңіз
+2. Шағымыңызды толық сипаттаңыз
+3. Хабарламаны жіберіңіз
+
+⭐ Пікірді қалай қалдыруға болады:
+1. "⭐ Пікір қалдыру" батырмасын басыңыз
+2. Пікіріңізді толық сипаттаңыз
+3. Хабарламаны жіберіңіз
+
+👀 Жіберер алдында бот өтінішті көрсетеді: мәтінді немесе түрін өзгертуге, не бас тартуға болады.
+❌ Өтінішті кез келген кезде /cancel пәрменімен тоқтатуға болады.
+📋 Өтініштеріңіздің күйін /mystatus пәрменімен көруге болады.
+💬 Қызметкерлер жауап берсе, оларға «↩️ Жауап беру» батырмасымен жауап жазуға болады.
+🌐 Тілді /language пәрменімен өзгертуге болады.
+
+📧 Сіздің өтінішіңіз әкімшілікке email арқылы жіберіледі.
+
+🔙 Басты мәзірге оралу үшін /start немесе /menu пәрменін пайдаланыңыз`,
+
+	// Письмо сотрудникам
+	"email.subject":          "Жаңа өтініш: %s (%s)",
+	"email.body":             "🏥 Кері байланыс жүйесіндегі жаңа өтініш\n\n%s\n📝 Өтініш түрі: %s\n%s🏢 Бөлімше: %s\n📍 Орны: %s\n📅 Күні: %s\n\n💬 Хабарлама:\n%s\n%s%s\n---\nБұл аурухананың кері байланыс жүйесінің автоматты хабарламасы.",
+	"email.sender_anonymous": "👤 Жіберуші: анонимді\n",
+	"email.sender":           "👤 Жіберуші:\n• Аты: %s %s\n• Username: @%s\n• ID: %d\n• Телефон: %s\n",
+	"email.rating":           "⭐ Баға: %s (%d/5)",
+	"email.answers":          "📋 Қосымша мәліметтер:",
+	"email.attachments":      "📎 Тіркемелер:",
+	"email.attachment":       "• %s (%s, %d КБ)",
+	"email.not_specified":    "көрсетілмеген",
+}
@@ -0,0 +1,390 @@
+package main
+
+// catalogRussian - тексты на русском; этот же язык по умолчанию используется в письмах
+var catalogRussian = Catalog{
+	// Главное меню и общие кнопки
+	"menu.title":            "🏥 Главное меню системы обратной связи больницы\n\nВыберите действие:",
+	"button.complaint":      "📝 Отправить жалобу",
+	"button.review":         "⭐ Оставить отзыв",
+	"button.help":           "❓ Помощь",
+	"button.stats":          "📊 Статистика",
+	"button.language":       "🌐 Язык",
+	"button.main_menu":      "🏠 Главное меню",
+	"button.new_request":    "🏥 Новое обращение",
+	"button.submit":         "✅ Отправить",
+	"button.skip":           "⏭ Пропустить",
+	"button.yes":            "✅ Да",
+	"button.no":             "❌ Нет",
+	"button.today":          "Сегодня",
+	"button.yesterday":      "Вчера",
+	"button.edit_text":      "✏️ Изменить текст",
+	"button.change_type":    "🔄 Изменить тип",
+	"button.mystatus":       "📋 Мои обращения",
+	"button.inbox":          "📥 Обращения",
+	"button.back_to_list":   "⬅️ К списку",
+	"button.assign":         "👤 Назначить",
+	"button.my_tickets":     "🙋 Назначенные мне",
+	"button.prev":           "⬅️ Назад",
+	"button.next":           "Далее ➡️",
+	"button.take":           "🙋 Взять",
+	"button.mark_processed": "✅ Рассмотрено",
+	"button.cancel":         "❌ Отменить",
+
+	"type.complaint": "Жалоба",
+	"type.review":    "Отзыв",
+	"type.ask":       "Выберите тип обращения:",
+	"type.invalid":   "Пожалуйста, выберите ‘жалоба’ или ‘отзыв’",
+
+	"access.denied":       "❌ У вас нет доступа к этому действию",
+	"access.stats_denied": "❌ У вас нет доступа к статистике",
+	"error.save":          "❌ Произошла ошибка при сохранении. Попробуйте позже.",
+	"error.generic":       "❌ Произошла ошибка. Попробуйте позже.",
+	"choose_button":       "👇 Выберите одну из кнопок ниже.",
+
+	"language.ask":   "🌐 Выберите язык:",
+	"language.saved": "✅ Язык изменен: %s",
+
+	"location.ward": "Палата №%s",
+
+	// Шаги обращения
+	"anonymity.ask":         "Отправить обращение от своего имени или анонимно?\n\n🕶 В анонимном обращении ваше имя и аккаунт не показываются сотрудникам, но мы сможем ответить вам через этого бота.",
+	"anonymity.no":          "👤 От своего имени",
+	"anonymity.yes":         "🕶 Анонимно",
+	"department.ask":        "🏢 К какому отделению относится обращение?",
+	"department.skip":       "🤷 Не знаю / пропустить",
+	"message.ask.complaint": "📝 Пожалуйста, подробно опишите жалобу. Мы рассмотрим ее в кратчайшие сроки.",
+	"message.ask.review":    "⭐ Пожалуйста, подробно опишите ваш отзыв. Мы очень ценим ваше мнение.",
+	"message.empty":         "✍️ Пожалуйста, напишите сообщение текстом или отправьте фото, документ, голосовое или видеосообщение.",
+	"attachment.failed":     "❌ Не удалось загрузить файл. Попробуйте отправить другой файл.",
+	"attachment.too_large":  "❌ Файл больше %d МБ и не будет приложен. Отправьте файл меньшего размера.",
+	"attachment.received":   "📎 Получено файлов: %d\n\nМожно отправить еще файлы или текст. Когда будете готовы, нажмите «✅ Отправить».",
+	"contact.ask":           "📞 Если нужно, оставьте номер телефона, чтобы мы могли вам перезвонить.\n\nЭтот шаг необязателен — можно нажать «%s».",
+	"contact.share":         "📱 Поделиться номером",
+	"contact.own_only":      "❌ Можно отправить только свой номер кнопкой «📱 Поделиться номером».",
+	"contact.saved":         "✅ Номер сохранен.",
+	"contact.skipped":       "👌 Хорошо, отправим без номера.",
+
+	"feedback.sent.complaint": "✅ Ваша жалоба успешно отправлена!\n\nМы рассмотрим ее и примем необходимые меры.\n\nХотите отправить еще одно обращение?",
+	"feedback.sent.review":    "✅ Ваш отзыв успешно отправлен!\n\nМы рассмотрим его и примем необходимые меры.\n\nХотите отправить еще одно обращение?",
+	"feedback.ticket":         "🎫 Номер обращения: %s\nСтатус можно проверить командой /mystatus.",
+
+	// Предпросмотр и отмена
+	"preview.title":        "👀 Проверьте обращение:",
+	"preview.type":         "Тип: %s",
+	"preview.anonymous":    "🕶 Анонимное обращение",
+	"preview.department":   "🏢 Отделение: %s",
+	"preview.phone":        "📞 Телефон: %s",
+	"preview.rating":       "⭐ Оценка: %s",
+	"preview.files":        "📎 Файлы: %d",
+	"preview.message":      "💬 Текст:",
+	"preview.footer":       "Если все верно, нажмите «✅ Отправить».",
+	"preview.type_changed": "🔄 Тип обращения изменен: %s",
+	"cancel.nothing":       "ℹ️ Нет активного обращения для отмены.",
+	"cancel.done":          "❌ Обращение не отправлено, введенные данные удалены.",
+
+	// Дополнительные вопросы анкеты
+	"form.date_hint":     "📅 Напишите дату в формате ДД.ММ.ГГГГ или нажмите кнопку.",
+	"form.text_required": "✍️ Напишите ответ текстом.",
+	"form.min_length":    "✍️ Ответ должен содержать не менее %d символов.",
+	"form.max_length":    "✍️ Ответ должен содержать не более %d символов.",
+	"form.pattern":       "✍️ Неверный формат ответа, попробуйте еще раз.",
+	"form.number":        "🔢 Введите число.",
+	"form.integer":       "🔢 Введите целое число.",
+	"form.min":           "🔢 Число должно быть не меньше %s.",
+	"form.max":           "🔢 Число должно быть не больше %s.",
+	"form.date_format":   "📅 Введите дату в формате ДД.ММ.ГГГГ, например 05.03.2025.",
+	"form.date_future":   "📅 Дата не может быть в будущем.",
+	"form.date_too_old":  "📅 Дата не может быть старше %d дней.",
+	"form.yes_no":        "👇 Выберите «Да» или «Нет».",
+	"form.unsupported":   "❌ На этот шаг нельзя ответить.",
+	"form.answer.yes":    "Да",
+	"form.answer.no":     "Нет",
+	"form.reload_failed": "❌ Не удалось загрузить анкету: %s",
+	"form.reloaded":      "✅ Анкета перезагружена (%s)",
+	"form.summary":       "📋 Анкета: %s",
+	"form.reload_hint":   "Перезагрузить: /form_reload",
+
+	// Оценка отзыва
+	"rating.ask":           "⭐ Оцените нас от 1 до 5:",
+	"rating.invalid":       "⭐ Выберите число от 1 до 5.",
+	"rating.stats.empty":   "⭐ Оценок пока нет",
+	"rating.stats.average": "⭐ Средняя оценка: %.1f (оценок: %d)",
+	"rating.stats.trend":   "📈 Последние %d дн.: %.1f (предыдущий период: %.1f) %s %+.1f",
+	"rating.stats.recent":  "📈 Последние %d дн.: %.1f",
+
+	// Статус обращений для пациента
+	"mystatus.empty":           "📋 У вас пока нет обращений.",
+	"mystatus.title":           "📋 Ваши обращения (стр. %d из %d):",
+	"mystatus.item":            "🎫 %s · %s · %s\nСтатус: %s\nОбновлено: %s",
+	"status.new":               "🆕 Получено",
+	"status.acknowledged":      "👀 Принято к рассмотрению",
+	"status.in_progress":       "🔄 В работе",
+	"status.awaiting_patient":  "⏳ Ожидает ответа пациента",
+	"status.resolved":          "✅ Решено",
+	"status.rejected":          "🚫 Отклонено",
+	"status.reopened":          "🔁 Открыто повторно",
+	"status.usage":             "Использование: /status <номер обращения> <статус> [комментарий]\nСтатусы: new, acknowledged, in_progress, awaiting_patient, resolved, rejected, reopened",
+	"status.changed":           "✅ Статус обращения %s: %s → %s",
+	"status.transition_denied": "❌ Из текущего статуса нельзя перейти в выбранный",
+	"status.history":           "📜 История статусов:",
+	"status.history_empty":     "Статус еще не менялся.",
+
+	// Переписка по обращению
+	"thread.reply_usage":     "Использование: /reply <номер обращения> [текст ответа]\nНапример: /reply K7QM-3XPA Здравствуйте! Мы рассмотрели вашу жалобу...",
+	"thread.usage":           "Использование: /thread <номер обращения>",
+	"thread.not_found":       "❌ Обращение с таким номером не найдено",
+	"thread.title":           "💬 Переписка по обращению %s",
+	"thread.empty":           "Сообщений пока нет.",
+	"thread.author.patient":  "👤 Пациент",
+	"thread.author.staff":    "🏥 Сотрудник",
+	"thread.button.reply":    "↩️ Ответить",
+	"thread.ask":             "✍️ Напишите ответ по обращению %s одним сообщением.\nОтменить: /cancel",
+	"thread.text_required":   "✍️ Напишите ответ текстом.",
+	"thread.delivery_failed": "❌ Не удалось доставить сообщение. Попробуйте позже.",
+	"thread.staff_message":   "🏥 Ответ больницы по вашему обращению %s:\n\n%s\n\nЧтобы ответить, нажмите «↩️ Ответить» или ответьте на это сообщение.",
+	"thread.sent_to_patient": "✅ Ответ отправлен автору обращения %s",
+	"thread.patient_message": "💬 Пациент написал по обращению %s:\n\n%s",
+	"thread.sent_to_staff":   "✅ Ваше сообщение по обращению %s передано сотрудникам.",
+
+	// Карточка обращения в чате сотрудников
+	"card.title":            "🆕 Обращение %s",
+	"card.type":             "Тип: %s",
+	"card.rating":           "⭐ Оценка: %s",
+	"card.department":       "🏢 Отделение: %s",
+	"card.location":         "📍 Место: %s",
+	"card.sender":           "👤 Отправитель: %s",
+	"card.sender_anonymous": "👤 Отправитель: анонимно",
+	"card.phone":            "📞 Телефон: %s",
+	"card.date":             "📅 Дата: %s",
+	"card.files":            "📎 Файлов: %d",
+	"card.status":           "📌 Статус: %s",
+	"card.assignee":         "🙋 Ответственный: %s",
+	"card.due":              "⏰ Срок ответа: %s",
+	"card.overdue":          "🔥 Срок ответа истек: %s",
+	"card.taken":            "Обращение закреплено за вами",
+	"card.processed":        "Обращение отмечено как рассмотренное",
+
+	// Список обращений для сотрудников
+	"inbox.usage":             "Использование: /list [complaint|review] [open|all|new|acknowledged|in_progress|awaiting_patient|resolved|rejected|reopened] [mine] [дата с] [дата по] [код отделения]\nДаты: 2026-01-31 или 31.01.2026\n/inbox - то же, но по умолчанию только открытые обращения",
+	"inbox.title":             "📥 Обращений: %d (стр. %d из %d)",
+	"inbox.empty":             "📭 Обращений по выбранным фильтрам нет",
+	"inbox.filters":           "Тип: %s · Статус: %s · Период: %s · Отделение: %s",
+	"inbox.item":              "%d. 🎫 %s · %s · %s\n%s\n%s",
+	"inbox.all":               "все",
+	"inbox.status.open":       "открытые",
+	"inbox.period.range":      "%s - %s",
+	"inbox.period.from":       "с %s",
+	"inbox.period.to":         "по %s",
+	"inbox.filter_assignee":   "Ответственный: %s",
+	"inbox.button.type":       "🔀 Тип",
+	"inbox.button.status":     "📌 Статус",
+	"inbox.button.period":     "📅 Период",
+	"inbox.button.department": "🏢 Отделение",
+	"inbox.choose_department": "🏢 Выберите отделение:",
+	"inbox.updated":           "🕓 Изменено: %s",
+	"inbox.history":           "🗂 Переписка:",
+	"inbox.status_changed":    "Статус изменен: %s",
+
+	// Назначение ответственных
+	"assign.usage":       "Использование: /assign <номер обращения> <user_id|me|auto>\nauto - следующий сотрудник отделения по очереди",
+	"assign.not_staff":   "❌ Этот сотрудник не может вести данное обращение",
+	"assign.done":        "✅ Обращение %s назначено: %s",
+	"assign.notify":      "📌 Вам назначено обращение %s",
+	"assign.choose":      "👤 Выберите ответственного за обращение %s:",
+	"assign.button.open": "📂 Открыть",
+	"assign.button.back": "⬅️ К обращению",
+
+	// Сроки ответа (SLA)
+	"sla.title":                "⏱ Сроки ответа (SLA)",
+	"sla.default":              "По умолчанию: жалобы — %d ч, отзывы — %d ч (0 — без срока)",
+	"sla.hours":                "%d рабочих ч",
+	"sla.business_hours":       "🕘 Рабочее время: %s, дни недели: %s",
+	"sla.holidays":             "📅 Праздничные дни:",
+	"sla.holidays_empty":       "Не заданы",
+	"sla.chain":                "📣 Цепочка эскалации (относительно срока):",
+	"sla.role.assignee":        "Ответственный",
+	"sla.role.department_head": "Заведующий отделением",
+	"sla.role.chief_physician": "Главный врач",
+	"sla.hint":                 "Задать срок: /sla_set <complaint|review|*> <код отделения|*> <часы>\nУдалить: /sla_remove <complaint|review|*> <код отделения|*>\nПраздник: /holiday_add <ГГГГ-ММ-ДД> [название], /holiday_remove <ГГГГ-ММ-ДД>",
+	"sla.set_usage":            "Использование: /sla_set <complaint|review|*> <код отделения|*> <часы>\nНапример: /sla_set complaint cardio 16",
+	"sla.saved":                "✅ Срок ответа сохранен",
+	"sla.remove_usage":         "Использование: /sla_remove <complaint|review|*> <код отделения|*>",
+	"sla.not_found":            "❌ Не найдено",
+	"sla.removed":              "✅ Удалено",
+	"sla.holiday_usage":        "Использование: /holiday_add <ГГГГ-ММ-ДД> [название] или /holiday_remove <ГГГГ-ММ-ДД>",
+	"sla.holiday_saved":        "✅ %s отмечен как нерабочий день",
+	"sla.holiday_removed":      "✅ Праздничный день удален",
+	"sla.warning":              "⏰ Скоро истекает срок ответа на обращение %s: %s",
+	"sla.overdue":              "🔥 Просрочен ответ на обращение %s, срок был %s",
+	"sla.level":                "Эскалация %d: %s",
+	"sla.email_subject":        "Эскалация обращения %s",
+	"sla.escalations":          "📣 Эскалации:",
+
+	// Сводки руководству по почте
+	"digest.title.daily":       "📊 Ежедневная сводка обращений",
+	"digest.title.weekly":      "📊 Еженедельная сводка обращений",
+	"digest.subject.daily":     "Сводка обращений за %s",
+	"digest.subject.weekly":    "Недельная сводка обращений на %s",
+	"digest.period":            "Период: %s — %s",
+	"digest.new":               "🆕 Новые за период: %d",
+	"digest.unresolved":        "📂 Не решены: %d",
+	"digest.overdue":           "🔥 Просрочены: %d",
+	"digest.more":              "…и еще %d",
+	"digest.none":              "Нет",
+	"digest.by_department":     "🏢 По отделениям",
+	"digest.column.department": "Отделение",
+	"digest.column.new":        "Новые",
+	"digest.column.open":       "Открытые",
+	"digest.column.overdue":    "Просроченные",
+	"digest.due":               "срок",
+	"digest.footer":            "Это автоматическая сводка системы обратной связи больницы.",
+
+	// Выгрузка
+	"export.usage":             "Использование: /export [csv|xlsx] [complaint|review] [open|new|acknowledged|in_progress|awaiting_patient|resolved|rejected|reopened] [mine|assignee=ID] [дата с] [дата по] [код отделения]\nДаты: 2026-01-31 или 31.01.2026. По умолчанию - xlsx со всеми обращениями",
+	"export.empty":             "📭 Нет обращений для выгрузки",
+	"export.error":             "❌ Не удалось сформировать выгрузку",
+	"export.too_large":         "❌ Выгрузка больше 50 МБ - сузьте период или воспользуйтесь HTTP API",
+	"export.caption":           "📤 Выгрузка: %d обращений",
+	"export.yes":               "да",
+	"export.column.id":         "№",
+	"export.column.ticket":     "Код",
+	"export.column.created_at": "Создано",
+	"export.column.updated_at": "Изменено",
+	"export.column.type":       "Тип",
+	"export.column.status":     "Статус",
+	"export.column.department": "Отделение",
+	"export.column.location":   "Место",
+	"export.column.rating":     "Оценка",
+	"export.column.anonymous":  "Анонимно",
+	"export.column.user_id":    "Telegram ID",
+	"export.column.username":   "Username",
+	"export.column.first_name": "Имя",
+	"export.column.last_name":  "Фамилия",
+	"export.column.phone":      "Телефон",
+	"export.column.assignee":   "Ответственный",
+	"export.column.due_at":     "Срок ответа",
+	"export.column.answers":    "Ответы анкеты",
+	"export.column.message":    "Текст",
+
+	// Антиспам
+	"antispam.rate_hour":        "🙏 Спасибо за активность! За последний час можно отправить не больше %d обращений. Пожалуйста, попробуйте немного позже.",
+	"antispam.rate_day":         "🙏 Спасибо за активность! За сутки можно отправить не больше %d обращений. Пожалуйста, попробуйте завтра.",
+	"antispam.too_short":        "✏️ Пожалуйста, опишите ситуацию чуть подробнее - не короче %d символов, чтобы мы смогли разобраться.",
+	"antispam.too_long":         "✏️ Сообщение получилось слишком длинным: %d символов при максимуме %d. Пожалуйста, сократите его и отправьте еще раз.",
+	"antispam.duplicate":        "ℹ️ Похожее обращение от вас уже получено и передано сотрудникам. Повторно отправлять его не нужно - мы обязательно ответим.",
+	"antispam.title":            "🛡 Антиспам",
+	"antispam.limits":           "Ограничения: %d в час, %d в сутки, длина текста от %d до %d символов, повторы ищутся за %s",
+	"antispam.counters":         "Отклонено (сегодня / 7 дней / 30 дней):",
+	"antispam.counter":          "• %s: %d / %d / %d",
+	"antispam.reason.rate_hour": "лимит в час",
+	"antispam.reason.rate_day":  "лимит в сутки",
+	"antispam.reason.too_short": "слишком короткие",
+	"antispam.reason.too_long":  "слишком длинные",
+	"antispam.reason.duplicate": "повторы",
+
+	// Скрытие персональных идентификаторов
+	"redact.iin":   "[ИИН скрыт]",
+	"redact.card":  "[карта •••• %s]",
+	"redact.phone": "[телефон …%s]",
+	"redact.email": "[email скрыт]",
+
+	// Статистика
+	"stats.error":                 "❌ Ошибка при получении статистики",
+	"stats.summary":               "📊 Статистика обращений за все время\n\n📝 Жалоб: %d\n⭐ Отзывов: %d\n📈 Всего: %d",
+	"stats.by_department":         "🏢 По отделениям:",
+	"stats.scope":                 "🏢 Отделение: %s",
+	"stats.assignee":              "🙋 Ответственный: %s",
+	"stats.usage":                 "Использование: /stats [me|user_id] [today|7d|30d|all | дата с [дата по]]\nДаты: 2026-01-31 или 31.01.2026, период - не больше %d дней",
+	"stats.not_specified":         "Не указано",
+	"stats.period.today":          "Сегодня",
+	"stats.period.7d":             "7 дней",
+	"stats.period.30d":            "30 дней",
+	"stats.period.custom":         "📅 Период…",
+	"stats.period.all":            "Все время",
+	"stats.period.title":          "📊 Статистика: %s (%s — %s)",
+	"stats.period.total":          "📈 Всего: %d (%s к предыдущему периоду: %d)",
+	"stats.period.complaints":     "📝 Жалоб: %d (%s)",
+	"stats.period.reviews":        "⭐ Отзывов: %d (%s)",
+	"stats.period.ask":            "Введите период: дату начала и окончания, например 2026-01-01 2026-01-31 или 01.01.2026 31.01.2026",
+	"stats.period.invalid":        "❌ Не удалось разобрать даты или период длиннее %d дней. Пример: 2026-01-01 2026-01-31",
+	"stats.median_first_response": "⏱ Медиана до первого ответа: %s",
+	"stats.median_resolution":     "✅ Медиана до решения: %s",
+	"stats.no_data":               "нет данных",
+	"stats.was":                   "(было %s)",
+	"stats.duration.days":         "%d дн %d ч",
+	"stats.duration.hours":        "%d ч %d мин",
+	"stats.duration.minutes":      "%d мин",
+	"stats.by_status":             "📌 По статусам:",
+	"stats.by_hour":               "🕐 По часам:",
+	"stats.charts":                "📈 Графики",
+	"stats.charts_error":          "❌ Не удалось построить графики",
+	"chart.trend":                 "Жалобы и отзывы по дням",
+	"chart.status":                "Обращения по статусам",
+	"chart.department":            "Обращения по отделениям",
+	"chart.no_data":               "Нет обращений за период",
+
+	// Сотрудники и роли
+	"role.super_admin":           "👑 Супер-администратор",
+	"role.handler":               "🛠 Обработчик",
+	"role.viewer":                "👁 Наблюдатель",
+	"role.department_head":       "🏥 Заведующий отделением",
+	"admins.title":               "👥 Сотрудники:",
+	"admins.empty":               "В базе нет сотрудников (ADMIN_USER_ID всегда супер-администратор).",
+	"admins.hint":                "Добавить/изменить: /admin_add <user_id> <роль> [код отделения] [имя]\nРоли: super_admin, handler, viewer, department_head\nУдалить: /admin_remove <user_id>\nПочта для эскалаций: /admin_email <user_id> [email]",
+	"admins.add_usage":           "Использование: /admin_add <user_id> <роль> [код отделения] [имя]\nРоли: super_admin, handler, viewer, department_head\nНапример: /admin_add 123456789 department_head cardio Аскар",
+	"admins.department_required": "❌ Для роли department_head укажите код отделения",
+	"admins.saved":               "✅ Сотрудник %d сохранен: %s",
+	"admins.remove_usage":        "Использование: /admin_remove <user_id>",
+	"admins.remove_self":         "❌ Нельзя удалить самого себя",
+	"admins.not_found":           "❌ Сотрудник не найден",
+	"admins.removed":             "✅ Сотрудник удален",
+	"admins.email_usage":         "Использование: /admin_email <user_id> [email]\nБез адреса почта удаляется",
+	"admins.email_saved":         "✅ Почта сотрудника сохранена",
+
+	// Управление отделениями
+	"departments.error":        "❌ Ошибка при получении списка отделений",
+	"departments.empty":        "🏢 Отделения еще не добавлены.\n\nДобавить: /dept_add <код> <название>",
+	"departments.title":        "🏢 Отделения:",
+	"departments.hint":         "Добавить/изменить: /dept_add <код> <название>\nСкрыть: /dept_remove <код>",
+	"departments.add_usage":    "Использование: /dept_add <код> <название>\nНапример: /dept_add cardio Кардиология",
+	"departments.bad_code":     "❌ Код может содержать только латинские буквы, цифры и _ (до 32 символов)",
+	"departments.save_error":   "❌ Ошибка при сохранении отделения",
+	"departments.saved":        "✅ Отделение сохранено: %s — %s",
+	"departments.remove_usage": "Использование: /dept_remove <код>",
+	"departments.remove_error": "❌ Ошибка при скрытии отделения",
+	"departments.not_found":    "❌ Отделение с таким кодом не найдено",
+	"departments.removed":      "✅ Отделение скрыто",
+
+	"help.text": `ℹ️ Помощь
+
+📝 Как отправить жалобу:
+1. Нажмите кнопку "📝 Отправить жалобу"
+2. Подробно опишите жалобу
+3. Отправьте сообщение
+
+⭐ Как оставить отзыв:
+1. Нажмите кнопку "⭐ Оставить отзыв"
+2. Подробно опишите отзыв
+3. Отправьте сообщение
+
+👀 Перед отправкой бот покажет обращение: можно изменить текст или тип либо отменить его.
+❌ Обращение можно прервать в любой момент командой /cancel.
+📋 Статус своих обращений можно посмотреть командой /mystatus.
+💬 Если сотрудники ответят на обращение, им можно ответить кнопкой «↩️ Ответить».
+🌐 Язык можно сменить командой /language.
+
+📧 Ваше обращение будет отправлено администрации по email.
+
+🔙 Чтобы вернуться в главное меню, используйте /start или /menu`,
+
+	// Письмо сотрудникам
+	"email.subject":          "Новое обращение: %s (%s)",
+	"email.body":             "🏥 Новое обращение в системе обратной связи\n\n%s\n📝 Тип обращения: %s\n%s🏢 Отделение: %s\n📍 Место: %s\n📅 Дата: %s\n\n💬 Сообщение:\n%s\n%s%s\n---\nЭто автоматическое уведомление от системы обратной связи больницы.",
+	"email.sender_anonymous": "👤 Отправитель: анонимно\n",
+	"email.sender":           "👤 Отправитель:\n• Имя: %s %s\n• Username: @%s\n• ID: %d\n• Телефон: %s\n",
+	"email.rating":           "⭐ Оценка: %s (%d/5)",
+	"email.answers":          "📋 Дополнительные сведения:",
+	"email.attachments":      "📎 Вложения:",
+	"email.attachment":       "• %s (%s, %d КБ)",
+	"email.not_specified":    "не указано",
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestForgetIdleLanguages(t *testing.T) {
+	now := time.Now()
+	bot := &TelegramBot{languages: map[int64]cachedLanguage{
+		1: {lang: LangKazakh, usedAt: now.Add(-48 * time.Hour)},
+		2: {lang: LangEnglish, usedAt: now.Add(-time.Hour)},
+	}}
+
+	bot.forgetIdleLanguages(24 * time.Hour)
+
+	if _, ok := bot.languages[1]; ok {
+		t.Fatal("idle language was not evicted")
+	}
+	if got := bot.lang(2); got != LangEnglish {
+		t.Fatalf("lang(2) = %q, want %q", got, LangEnglish)
+	}
+	// Групповой чат не читает язык из базы
+	if got := bot.lang(-100); got != defaultLanguage() {
+		t.Fatalf("lang(-100) = %q, want default %q", got, defaultLanguage())
+	}
+}
@@ -0,0 +1,681 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// inboxPageSize - сколько обращений показывать на одной странице /inbox и /list
+const inboxPageSize = 8
+
+// detailMessageLimit - сколько символов текста обращения показывать в подробном просмотре
+const detailMessageLimit = 2500
+
+// FeedbackFilter - условия выборки обращений для ListFeedbacks; пустые поля не ограничивают выборку
+type FeedbackFilter struct {
+	Type         string
+	Statuses     []string
+	DepartmentID int64
+	AssigneeID   int64
+	From         time.Time // включительно
+	To           time.Time // не включительно
+	WithoutCard  bool      // только обращения без карточки в чате сотрудников
+}
+
+func (f FeedbackFilter) where() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if f.Type != "" {
+		conditions = append(conditions, "f.type = ?")
+		args = append(args, f.Type)
+	}
+	if len(f.Statuses) > 0 {
+		conditions = append(conditions, "f.status IN (?"+strings.Repeat(", ?", len(f.Statuses)-1)+")")
+		for _, status := range f.Statuses {
+			args = append(args, status)
+		}
+	}
+	if f.DepartmentID != 0 {
+		conditions = append(conditions, "f.department_id = ?")
+		args = append(args, f.DepartmentID)
+	}
+	if f.AssigneeID != 0 {
+		conditions = append(conditions, "f.assignee_id = ?")
+		args = append(args, f.AssigneeID)
+	}
+	if !f.From.IsZero() {
+		conditions = append(conditions, "f.created_at >= ?")
+		args = append(args, f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		conditions = append(conditions, "f.created_at < ?")
+		args = append(args, f.To.UTC())
+	}
+	if f.WithoutCard {
+		conditions = append(conditions, "COALESCE(f.card_message_id, 0) = 0")
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND ") + "\n", args
+}
+
+// ListFeedbacks возвращает страницу обращений по фильтру, новые сверху, и их общее число
+func (d *Database) ListFeedbacks(filter FeedbackFilter, limit, offset int) ([]*Feedback, int, error) {
+	where, args := filter.where()
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM feedback f " + where
+	if err := d.db.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count feedbacks: %w", err)
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	query := feedbackSelect + where + `
+	ORDER BY f.created_at DESC, f.id DESC
+	LIMIT ? OFFSET ?
+	`
+
+	rows, err := d.db.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query feedbacks: %w", err)
+	}
+	defer rows.Close()
+
+	var feedbacks []*Feedback
+	for rows.Next() {
+		feedback, err := scanFeedback(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		feedbacks = append(feedbacks, feedback)
+	}
+
+	return feedbacks, total, nil
+}
+
+// inboxStatusCodes - короткие коды статусов для callback data (лимит Telegram - 64 байта)
+var inboxStatusCodes = map[string]string{
+	"n": StatusNew,
+	"a": StatusAcknowledged,
+	"w": StatusInProgress,
+	"q": StatusAwaitingPatient,
+	"r": StatusResolved,
+	"x": StatusRejected,
+	"e": StatusReopened,
+}
+
+// inboxOpen - код фильтра «все открытые обращения», см. openStatuses
+const inboxOpen = "o"
+
+// inboxStatusCycle - порядок переключения фильтра по статусу кнопкой
+var inboxStatusCycle = []string{"", inboxOpen, "n", "a", "w", "q", "e", "r", "x"}
+
+// inboxStatuses - статусы, которые выбирает код фильтра
+func inboxStatuses(code string) []string {
+	if code == inboxOpen {
+		return openStatuses
+	}
+	if status, ok := inboxStatusCodes[code]; ok {
+		return []string{status}
+	}
+	return nil
+}
+
+const inboxDateFormat = "20060102"
+
+// inboxFilter - фильтр списка обращений в боте. Хранится прямо в callback data
+// в виде "тип.статус.с.по.отделение.ответственный", например "c.o.20260101.20260131.3.".
+type inboxFilter struct {
+	Type         string // c - жалобы, r - отзывы
+	Status       string // код из inboxStatusCodes или inboxOpen
+	From         string // ГГГГММДД, включительно
+	To           string // ГГГГММДД, включительно
+	DepartmentID int64
+	AssigneeID   int64
+}
+
+func (f inboxFilter) encode() string {
+	return strings.Join([]string{f.Type, f.Status, f.From, f.To, encodeID(f.DepartmentID), encodeID(f.AssigneeID)}, ".")
+}
+
+func encodeID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+func parseInboxFilter(value string) inboxFilter {
+	parts := strings.Split(value, ".")
+	for len(parts) < 6 {
+		parts = append(parts, "")
+	}
+
+	filter := inboxFilter{Type: parts[0], Status: parts[1], From: parts[2], To: parts[3]}
+	filter.DepartmentID, _ = strconv.ParseInt(parts[4], 10, 64)
+	filter.AssigneeID, _ = strconv.ParseInt(parts[5], 10, 64)
+	return filter
+}
+
+func (f inboxFilter) feedbackType() string {
+	switch f.Type {
+	case "c":
+		return "complaint"
+	case "r":
+		return "review"
+	default:
+		return ""
+	}
+}
+
+// database переводит фильтр в условия выборки; scope != 0 ограничивает выборку отделением сотрудника
+func (f inboxFilter) database(scope int64) FeedbackFilter {
+	filter := FeedbackFilter{
+		Type:         f.feedbackType(),
+		Statuses:     inboxStatuses(f.Status),
+		DepartmentID: f.DepartmentID,
+		AssigneeID:   f.AssigneeID,
+	}
+	if scope != 0 {
+		filter.DepartmentID = scope
+	}
+
+	loc := nowInTimezone().Location()
+	if from, err := time.ParseInLocation(inboxDateFormat, f.From, loc); err == nil {
+		filter.From = from
+	}
+	if to, err := time.ParseInLocation(inboxDateFormat, f.To, loc); err == nil {
+		filter.To = to.AddDate(0, 0, 1)
+	}
+	return filter
+}
+
+// nextType, nextStatus и nextPeriod - значения фильтра для следующего нажатия кнопки
+func (f inboxFilter) nextType() inboxFilter {
+	switch f.Type {
+	case "":
+		f.Type = "c"
+	case "c":
+		f.Type = "r"
+	default:
+		f.Type = ""
+	}
+	return f
+}
+
+func (f inboxFilter) nextStatus() inboxFilter {
+	next := inboxStatusCycle[0]
+	for i, status := range inboxStatusCycle {
+		if status == f.Status && i+1 < len(inboxStatusCycle) {
+			next = inboxStatusCycle[i+1]
+		}
+	}
+	f.Status = next
+	return f
+}
+
+// nextPeriod переключает период: все время → сегодня → 7 дней → 30 дней → все время
+func (f inboxFilter) nextPeriod() inboxFilter {
+	today := nowInTimezone()
+	daysAgo := func(days int) string {
+		return today.AddDate(0, 0, -days).Format(inboxDateFormat)
+	}
+
+	from := ""
+	if f.To == "" {
+		switch f.From {
+		case "":
+			from = daysAgo(0)
+		case daysAgo(0):
+			from = daysAgo(6)
+		case daysAgo(6):
+			from = daysAgo(29)
+		}
+	}
+	f.From, f.To = from, ""
+	return f
+}
+
+// handleInboxCommand обрабатывает /inbox (по умолчанию только открытые обращения), /list
+// и /mytickets (открытые обращения, назначенные сотруднику)
+func (t *TelegramBot) handleInboxCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	var filter inboxFilter
+	switch message.Command() {
+	case "inbox":
+		filter.Status = inboxOpen
+	case "mytickets":
+		filter.Status = inboxOpen
+		filter.AssigneeID = message.From.ID
+	}
+
+	var dates []string
+	for _, arg := range strings.Fields(message.CommandArguments()) {
+		arg = strings.ToLower(arg)
+		switch arg {
+		case "complaint", "review":
+			filter.Type = arg[:1]
+			continue
+		case "all":
+			filter.Status = ""
+			continue
+		case "open":
+			filter.Status = inboxOpen
+			continue
+		case "mine":
+			filter.AssigneeID = message.From.ID
+			continue
+		}
+
+		if code := inboxStatusCode(arg); code != "" {
+			filter.Status = code
+			continue
+		}
+		if date, ok := parseInboxDate(arg); ok {
+			dates = append(dates, date)
+			continue
+		}
+
+		department, err := t.database.GetDepartmentByCode(arg)
+		if err != nil {
+			t.logger.Error("Failed to get department: ", err)
+		}
+		if department == nil {
+			t.sendMessage(chatID, t.tr(chatID, "inbox.usage"))
+			return
+		}
+		filter.DepartmentID = department.ID
+	}
+
+	switch len(dates) {
+	case 0:
+	case 1:
+		filter.From = dates[0]
+	case 2:
+		filter.From, filter.To = dates[0], dates[1]
+	default:
+		t.sendMessage(chatID, t.tr(chatID, "inbox.usage"))
+		return
+	}
+
+	t.showInbox(chatID, message.From, filter, 0, 0)
+}
+
+// inboxStatusCode - код фильтра по полному названию статуса
+func inboxStatusCode(status string) string {
+	for code, s := range inboxStatusCodes {
+		if s == status {
+			return code
+		}
+	}
+	return ""
+}
+
+// parseInboxDate понимает даты вида 2026-01-31 и 31.01.2026
+func parseInboxDate(value string) (string, bool) {
+	for _, layout := range []string{"2006-01-02", "02.01.2006"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.Format(inboxDateFormat), true
+		}
+	}
+	return "", false
+}
+
+// handleInboxCallback обрабатывает кнопки списка обращений:
+// inbox:l:<страница>:<фильтр> - страница списка,
+// inbox:v:<id>:<страница>:<фильтр> - подробный просмотр,
+// inbox:s:<статус>:<id>:<страница>:<фильтр> - смена статуса,
+// inbox:a:<id> и inbox:A:<id>:<сотрудник> - выбор ответственного,
+// inbox:d:<страница>:<фильтр> - выбор отделения для фильтра.
+func (t *TelegramBot) handleInboxCallback(callback *tgbotapi.CallbackQuery, payload string) {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+	if t.authorize(callback.From.ID, PermViewFeedback) == nil {
+		t.bot.Request(tgbotapi.NewCallback(callback.ID, t.tr(chatID, "access.denied")))
+		return
+	}
+
+	action, rest, _ := strings.Cut(payload, ":")
+	switch action {
+	case "l":
+		rawPage, rawFilter, _ := strings.Cut(rest, ":")
+		page, _ := strconv.Atoi(rawPage)
+		t.showInbox(chatID, callback.From, parseInboxFilter(rawFilter), page, messageID)
+	case "d":
+		rawPage, rawFilter, _ := strings.Cut(rest, ":")
+		page, _ := strconv.Atoi(rawPage)
+		t.showInboxDepartments(chatID, parseInboxFilter(rawFilter), page, messageID)
+	case "v":
+		parts := strings.SplitN(rest, ":", 3)
+		if len(parts) != 3 {
+			return
+		}
+		id, _ := strconv.ParseInt(parts[0], 10, 64)
+		page, _ := strconv.Atoi(parts[1])
+		t.showFeedbackDetail(chatID, callback.From, id, parseInboxFilter(parts[2]), page, messageID)
+	case "a":
+		id, _ := strconv.ParseInt(rest, 10, 64)
+		t.showAssignees(chatID, callback.From, id, messageID)
+	case "A":
+		rawID, rawUserID, _ := strings.Cut(rest, ":")
+		id, _ := strconv.ParseInt(rawID, 10, 64)
+		userID, _ := strconv.ParseInt(rawUserID, 10, 64)
+		t.setAssignee(callback, id, userID)
+	case "s":
+		parts := strings.SplitN(rest, ":", 4)
+		if len(parts) != 4 {
+			return
+		}
+		id, _ := strconv.ParseInt(parts[1], 10, 64)
+		page, _ := strconv.Atoi(parts[2])
+		t.setInboxStatus(callback, id, parts[0], parseInboxFilter(parts[3]), page)
+	}
+}
+
+// showInbox показывает страницу обращений по фильтру.
+// messageID != 0 - страница листается кнопками, сообщение редактируется на месте.
+func (t *TelegramBot) showInbox(chatID int64, from *tgbotapi.User, filter inboxFilter, page, messageID int) {
+	admin := t.authorize(from.ID, PermViewFeedback)
+	if admin == nil {
+		t.sendMessage(chatID, t.tr(chatID, "access.denied"))
+		return
+	}
+	if page < 0 {
+		page = 0
+	}
+	// Заведующий отделением видит только свое отделение, фильтр по отделению ему не нужен
+	if admin.ScopeDepartmentID() != 0 {
+		filter.DepartmentID = 0
+	}
+
+	feedbacks, total, err := t.database.ListFeedbacks(filter.database(admin.ScopeDepartmentID()), inboxPageSize, page*inboxPageSize)
+	if err != nil {
+		t.logger.Error("Failed to list feedbacks: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+
+	lang := t.lang(chatID)
+	var sb strings.Builder
+	if total == 0 {
+		sb.WriteString(Translate(lang, "inbox.empty"))
+	} else {
+		pages := (total + inboxPageSize - 1) / inboxPageSize
+		sb.WriteString(Translate(lang, "inbox.title", total, page+1, pages))
+	}
+	sb.WriteString("\n" + t.inboxFilterSummary(lang, filter))
+
+	encoded := filter.encode()
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, feedback := range feedbacks {
+		sb.WriteString("\n\n" + inboxLine(lang, page*inboxPageSize+i+1, feedback))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%d. %s", page*inboxPageSize+i+1, ticketLabel(feedback)),
+			fmt.Sprintf("inbox:v:%d:%d:%s", feedback.ID, page, encoded),
+		)))
+	}
+
+	var pager []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		pager = append(pager, tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "button.prev"), fmt.Sprintf("inbox:l:%d:%s", page-1, encoded)))
+	}
+	if (page+1)*inboxPageSize < total {
+		pager = append(pager, tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "button.next"), fmt.Sprintf("inbox:l:%d:%s", page+1, encoded)))
+	}
+	if len(pager) > 0 {
+		rows = append(rows, pager)
+	}
+
+	// Кнопки фильтров переключают значение по кругу и открывают первую страницу
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "inbox.button.type"), "inbox:l:0:"+filter.nextType().encode()),
+		tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "inbox.button.status"), "inbox:l:0:"+filter.nextStatus().encode()),
+	))
+	filterRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "inbox.button.period"), "inbox:l:0:"+filter.nextPeriod().encode()),
+	)
+	if admin.ScopeDepartmentID() == 0 {
+		filterRow = append(filterRow, tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "inbox.button.department"), fmt.Sprintf("inbox:d:%d:%s", page, encoded)))
+	}
+	rows = append(rows, filterRow)
+
+	t.sendOrEdit(chatID, messageID, truncateRunes(sb.String(), 4000), tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// showInboxDepartments предлагает выбрать отделение для фильтра списка
+func (t *TelegramBot) showInboxDepartments(chatID int64, filter inboxFilter, page, messageID int) {
+	departments, err := t.database.ListDepartments(false)
+	if err != nil {
+		t.logger.Error("Failed to list departments: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+
+	lang := t.lang(chatID)
+	all := filter
+	all.DepartmentID = 0
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "inbox.all"), "inbox:l:0:"+all.encode())),
+	}
+	for _, department := range departments {
+		selected := filter
+		selected.DepartmentID = department.ID
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(department.Name, "inbox:l:0:"+selected.encode()),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "button.back_to_list"), fmt.Sprintf("inbox:l:%d:%s", page, filter.encode())),
+	))
+
+	t.sendOrEdit(chatID, messageID, Translate(lang, "inbox.choose_department"), tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// showFeedbackDetail показывает обращение целиком: текст, автора, переписку и кнопки смены статуса
+func (t *TelegramBot) showFeedbackDetail(chatID int64, from *tgbotapi.User, id int64, filter inboxFilter, page, messageID int) {
+	feedback, err := t.database.GetFeedback(id)
+	if err != nil {
+		t.logger.Error("Failed to get feedback: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+	if feedback == nil || !t.canAccessFeedback(from.ID, PermViewFeedback, feedback) {
+		t.sendMessage(chatID, t.tr(chatID, "thread.not_found"))
+		return
+	}
+	t.loadFeedbackDetails(feedback)
+
+	messages, err := t.database.GetFeedbackMessages(feedback.ID)
+	if err != nil {
+		t.logger.Error("Failed to get feedback messages: ", err)
+	}
+	history, err := t.database.GetStatusHistory(feedback.ID)
+	if err != nil {
+		t.logger.Error("Failed to get status history: ", err)
+	}
+	escalations, err := t.database.GetEscalations(feedback.ID)
+	if err != nil {
+		t.logger.Error("Failed to get escalations: ", err)
+	}
+
+	lang := t.lang(chatID)
+	// Без права на персональные данные автор маскируется, а идентификаторы в тексте и переписке скрыты
+	redact := func(text string) string { return text }
+	showPersonal := t.canSeeIdentifiers(from.ID)
+	if !showPersonal {
+		redact = func(text string) string { return RedactPII(lang, text) }
+		feedback = redactFeedback(lang, feedback)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(feedbackText(lang, feedback, detailMessageLimit, showPersonal))
+	sb.WriteString("\n" + Translate(lang, "inbox.updated", inTimezone(feedback.UpdatedAt).Format("02.01.2006 15:04")))
+	sb.WriteString("\n\n" + statusHistoryText(lang, history))
+	if len(escalations) > 0 {
+		sb.WriteString("\n\n" + escalationText(lang, escalations))
+	}
+	sb.WriteString("\n\n" + Translate(lang, "inbox.history"))
+	if len(messages) == 0 {
+		sb.WriteString("\n" + Translate(lang, "thread.empty"))
+	}
+	for _, m := range messages {
+		author := Translate(lang, "thread.author.patient")
+		if m.Direction == MessageFromStaff {
+			author = Translate(lang, "thread.author.staff")
+		}
+		sb.WriteString(fmt.Sprintf("\n\n%s · %s\n%s", author, inTimezone(m.CreatedAt).Format("02.01.2006 15:04"), redact(m.Text)))
+	}
+
+	encoded := filter.encode()
+	var rows [][]tgbotapi.InlineKeyboardButton
+	// Наблюдатель видит обращение, но менять статус и отвечать не может.
+	// Кнопки предлагают только переходы, допустимые из текущего статуса.
+	if t.canAccessFeedback(from.ID, PermHandleFeedback, feedback) {
+		var actions []tgbotapi.InlineKeyboardButton
+		for _, status := range statusTransitions[feedback.Status] {
+			actions = append(actions, tgbotapi.NewInlineKeyboardButtonData(
+				statusDisplayName(lang, status),
+				fmt.Sprintf("inbox:s:%s:%d:%d:%s", inboxStatusCode(status), feedback.ID, page, encoded),
+			))
+			if len(actions) == 2 {
+				rows = append(rows, actions)
+				actions = nil
+			}
+		}
+		if len(actions) > 0 {
+			rows = append(rows, actions)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "thread.button.reply"), "staffreply:"+strconv.FormatInt(feedback.ID, 10)),
+			tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "button.assign"), fmt.Sprintf("inbox:a:%d", feedback.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(Translate(lang, "button.back_to_list"), fmt.Sprintf("inbox:l:%d:%s", page, encoded)),
+	))
+
+	t.sendOrEdit(chatID, messageID, truncateRunes(sb.String(), 4000), tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// setInboxStatus меняет статус обращения из подробного просмотра и перерисовывает его
+func (t *TelegramBot) setInboxStatus(callback *tgbotapi.CallbackQuery, id int64, code string, filter inboxFilter, page int) {
+	chatID := callback.Message.Chat.ID
+	status, ok := inboxStatusCodes[code]
+	if !ok {
+		return
+	}
+
+	feedback, err := t.database.GetFeedback(id)
+	if err != nil || feedback == nil {
+		t.logger.Error("Failed to get feedback: ", err)
+		t.bot.Request(tgbotapi.NewCallback(callback.ID, t.tr(chatID, "error.generic")))
+		return
+	}
+	if !t.canAccessFeedback(callback.From.ID, PermHandleFeedback, feedback) {
+		t.bot.Request(tgbotapi.NewCallback(callback.ID, t.tr(chatID, "access.denied")))
+		return
+	}
+
+	if err := t.changeFeedbackStatus(feedback, status, "", callback.From, status == StatusInProgress); err != nil {
+		if errors.Is(err, ErrInvalidTransition) {
+			t.bot.Request(tgbotapi.NewCallback(callback.ID, t.tr(chatID, "status.transition_denied")))
+			t.showFeedbackDetail(chatID, callback.From, id, filter, page, callback.Message.MessageID)
+			return
+		}
+		t.logger.Error("Failed to change feedback status: ", err)
+		t.bot.Request(tgbotapi.NewCallback(callback.ID, t.tr(chatID, "error.generic")))
+		return
+	}
+
+	t.bot.Request(tgbotapi.NewCallback(callback.ID, t.tr(chatID, "inbox.status_changed", statusDisplayName(t.lang(chatID), status))))
+	t.showFeedbackDetail(chatID, callback.From, id, filter, page, callback.Message.MessageID)
+}
+
+// inboxFilterSummary описывает текущие фильтры списка
+func (t *TelegramBot) inboxFilterSummary(lang string, filter inboxFilter) string {
+	all := Translate(lang, "inbox.all")
+
+	feedbackType := all
+	if filter.feedbackType() != "" {
+		feedbackType = feedbackTypeLabel(lang, filter.feedbackType())
+	}
+
+	status := all
+	if filter.Status == inboxOpen {
+		status = Translate(lang, "inbox.status.open")
+	} else if s, ok := inboxStatusCodes[filter.Status]; ok {
+		status = statusDisplayName(lang, s)
+	}
+
+	period := all
+	from, fromOK := inboxDateLabel(filter.From)
+	to, toOK := inboxDateLabel(filter.To)
+	switch {
+	case fromOK && toOK:
+		period = Translate(lang, "inbox.period.range", from, to)
+	case fromOK:
+		period = Translate(lang, "inbox.period.from", from)
+	case toOK:
+		period = Translate(lang, "inbox.period.to", to)
+	}
+
+	department := all
+	if filter.DepartmentID != 0 {
+		if d, err := t.database.GetDepartment(filter.DepartmentID); err == nil && d != nil {
+			department = d.Name
+		}
+	}
+
+	summary := Translate(lang, "inbox.filters", feedbackType, status, period, department)
+	if filter.AssigneeID != 0 {
+		summary += "\n" + Translate(lang, "inbox.filter_assignee", t.staffName(filter.AssigneeID))
+	}
+	return summary
+}
+
+func inboxDateLabel(value string) (string, bool) {
+	date, err := time.Parse(inboxDateFormat, value)
+	if err != nil {
+		return "", false
+	}
+	return date.Format("02.01.2006"), true
+}
+
+// inboxLine описывает одно обращение в списке /inbox; идентификаторы в начале текста скрыты всегда,
+// полный текст открывается в подробном просмотре
+func inboxLine(lang string, number int, feedback *Feedback) string {
+	text := strings.Join(strings.Fields(RedactPII(lang, feedback.Message)), " ")
+	return Translate(lang, "inbox.item",
+		number,
+		ticketLabel(feedback),
+		feedbackTypeLabel(lang, feedback.Type),
+		inTimezone(feedback.CreatedAt).Format("02.01.2006 15:04"),
+		statusDisplayName(lang, feedback.Status),
+		truncateRunes(text, 80),
+	)
+}
+
+// sendOrEdit редактирует сообщение messageID, а если это не удалось или messageID == 0 - отправляет новое
+func (t *TelegramBot) sendOrEdit(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) {
+	if messageID != 0 {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard)
+		if _, err := t.bot.Send(edit); err == nil {
+			return
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	t.bot.Send(msg)
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Направления сообщений переписки по обращению
+const (
+	MessageFromStaff   = "staff"
+	MessageFromPatient = "patient"
+)
+
+// FeedbackMessage - сообщение переписки сотрудников с автором обращения.
+// RecipientHash и TelegramMessageID указывают на копию сообщения в чате получателя:
+// по ним находится обращение, когда получатель отвечает на сообщение через reply.
+// Чат пациента хранится только в виде HMAC с ключом ANONYMITY_SECRET: без секрета по хэшу автора не найти.
+type FeedbackMessage struct {
+	ID                int64
+	FeedbackID        int64
+	Direction         string
+	AuthorID          int64 // сотрудник; для сообщений пациента не хранится
+	Text              string
+	RecipientHash     string
+	TelegramMessageID int
+	CreatedAt         time.Time
+}
+
+func (d *Database) GetFeedback(id int64) (*Feedback, error) {
+	feedback, err := scanFeedback(d.db.QueryRow(feedbackSelect+"WHERE f.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	return feedback, nil
+}
+
+func (d *Database) GetFeedbackByTicket(ticketCode string) (*Feedback, error) {
+	feedback, err := scanFeedback(d.db.QueryRow(feedbackSelect+"WHERE f.ticket_code = ?", ticketCode))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback by ticket: %w", err)
+	}
+	return feedback, nil
+}
+
+// SaveFeedbackMessage сохраняет сообщение переписки и отмечает обращение как обновленное
+func (d *Database) SaveFeedbackMessage(message *FeedbackMessage) error {
+	query := `
+	INSERT INTO feedback_messages (feedback_id, direction, author_id, text, recipient_hash, telegram_message_id)
+	VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := d.db.Exec(query,
+		message.FeedbackID,
+		message.Direction,
+		nullInt64(message.AuthorID),
+		message.Text,
+		nullString(message.RecipientHash),
+		nullInt64(int64(message.TelegramMessageID)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save feedback message: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	message.ID = id
+
+	if _, err := d.db.Exec("UPDATE feedback SET updated_at = CURRENT_TIMESTAMP WHERE id = ?", message.FeedbackID); err != nil {
+		return fmt.Errorf("failed to touch feedback: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) GetFeedbackMessages(feedbackID int64) ([]*FeedbackMessage, error) {
+	query := `
+	SELECT id, feedback_id, direction, COALESCE(author_id, 0), text, COALESCE(recipient_hash, ''),
+		COALESCE(telegram_message_id, 0), created_at
+	FROM feedback_messages
+	WHERE feedback_id = ?
+	ORDER BY id ASC
+	`
+
+	rows, err := d.db.Query(query, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feedback messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*FeedbackMessage
+	for rows.Next() {
+		message := &FeedbackMessage{}
+		err := rows.Scan(
+			&message.ID,
+			&message.FeedbackID,
+			&message.Direction,
+			&message.AuthorID,
+			&message.Text,
+			&message.RecipientHash,
+			&message.TelegramMessageID,
+			&message.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feedback message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// FindFeedbackMessage ищет сообщение, которое бот доставил в чат получателя
+func (d *Database) FindFeedbackMessage(recipientHash string, telegramMessageID int) (*FeedbackMessage, error) {
+	query := `
+	SELECT id, feedback_id, direction, COALESCE(author_id, 0), text, created_at
+	FROM feedback_messages
+	WHERE recipient_hash = ? AND telegram_message_id = ?
+	`
+
+	message := &FeedbackMessage{RecipientHash: recipientHash, TelegramMessageID: telegramMessageID}
+	err := d.db.QueryRow(query, recipientHash, telegramMessageID).Scan(
+		&message.ID,
+		&message.FeedbackID,
+		&message.Direction,
+		&message.AuthorID,
+		&message.Text,
+		&message.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find feedback message: %w", err)
+	}
+	return message, nil
+}
+
+// staffChatID - чат или супергруппа сотрудников (ADMIN_CHAT_ID), куда бот публикует
+// карточки обращений и пересылает ответы пациентов; по умолчанию - личный чат администратора
+func staffChatID() int64 {
+	if chatID := getEnvAsInt64("ADMIN_CHAT_ID", 0); chatID != 0 {
+		return chatID
+	}
+	return getEnvAsInt64("ADMIN_USER_ID", 0)
+}
+
+// findFeedbackByTicket ищет обращение по коду ("K7QM-3XPA") или по номеру ("#123")
+func (t *TelegramBot) findFeedbackByTicket(ticket string) (*Feedback, error) {
+	return t.database.FindFeedback(ticket)
+}
+
+// ownsFeedback проверяет, что пользователь - автор обращения, в том числе анонимного
+func (t *TelegramBot) ownsFeedback(feedback *Feedback, userID int64) bool {
+	if !feedback.Anonymous {
+		return feedback.UserID == userID
+	}
+
+	identity, err := t.database.GetFeedbackIdentityByToken(feedback.ReplyToken)
+	if err != nil {
+		t.logger.Error("Failed to get feedback identity: ", err)
+		return false
+	}
+	return identity != nil && identity.UserHash == t.vault.UserHash(userID)
+}
+
+// handleReplyCommand обрабатывает /reply <номер обращения> [текст] от сотрудника
+func (t *TelegramBot) handleReplyCommand(message *tgbotapi.Message, state *UserState) {
+	chatID := message.Chat.ID
+	ticket, text, _ := strings.Cut(strings.TrimSpace(message.CommandArguments()), " ")
+	if ticket == "" {
+		t.sendMessage(chatID, t.tr(chatID, "thread.reply_usage"))
+		return
+	}
+
+	feedback, err := t.findFeedbackByTicket(ticket)
+	if err != nil {
+		t.logger.Error("Failed to find feedback: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+	if feedback == nil || !t.canAccessFeedback(message.From.ID, PermHandleFeedback, feedback) {
+		t.sendMessage(chatID, t.tr(chatID, "thread.not_found"))
+		return
+	}
+
+	if text = strings.TrimSpace(text); text == "" {
+		t.startReply(chatID, state, feedback, MessageFromStaff)
+		return
+	}
+	t.sendStaffReply(chatID, message.From, feedback, text)
+}
+
+// handleThreadCommand показывает переписку по обращению: /thread <номер обращения>
+func (t *TelegramBot) handleThreadCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	ticket := strings.TrimSpace(message.CommandArguments())
+	if ticket == "" {
+		t.sendMessage(chatID, t.tr(chatID, "thread.usage"))
+		return
+	}
+
+	feedback, err := t.findFeedbackByTicket(ticket)
+	if err != nil {
+		t.logger.Error("Failed to find feedback: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+	if feedback == nil || !t.canAccessFeedback(message.From.ID, PermViewFeedback, feedback) {
+		t.sendMessage(chatID, t.tr(chatID, "thread.not_found"))
+		return
+	}
+
+	messages, err := t.database.GetFeedbackMessages(feedback.ID)
+	if err != nil {
+		t.logger.Error("Failed to get feedback messages: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+
+	// Без права на персональные данные идентификаторы в переписке скрыты
+	lang := t.lang(chatID)
+	redact := func(text string) string { return text }
+	if !t.canSeeIdentifiers(message.From.ID) {
+		redact = func(text string) string { return RedactPII(lang, text) }
+	}
+
+	var sb strings.Builder
+	sb.WriteString(t.tr(chatID, "thread.title", ticketLabel(feedback)))
+	sb.WriteString("\n\n" + truncateRunes(redact(feedback.Message), 500))
+	if len(messages) == 0 {
+		sb.WriteString("\n\n" + t.tr(chatID, "thread.empty"))
+	}
+	for _, m := range messages {
+		author := t.tr(chatID, "thread.author.patient")
+		if m.Direction == MessageFromStaff {
+			author = t.tr(chatID, "thread.author.staff")
+		}
+		sb.WriteString(fmt.Sprintf("\n\n%s · %s\n%s", author, inTimezone(m.CreatedAt).Format("02.01.2006 15:04"), redact(m.Text)))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, truncateRunes(sb.String(), 4000))
+	// Наблюдатель видит переписку, но отвечать не может
+	if t.canAccessFeedback(message.From.ID, PermHandleFeedback, feedback) {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "thread.button.reply"), "staffreply:"+strconv.FormatInt(feedback.ID, 10)),
+			),
+		)
+	}
+	t.bot.Send(msg)
+}
+
+// handleReplyButton обрабатывает кнопки reply:<id> (пациент) и staffreply:<id> (сотрудник)
+func (t *TelegramBot) handleReplyButton(chatID int64, from *tgbotapi.User, state *UserState, value, direction string) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		t.sendMainMenu(chatID, from.ID)
+		return
+	}
+
+	feedback, err := t.database.GetFeedback(id)
+	if err != nil {
+		t.logger.Error("Failed to get feedback: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+	if feedback == nil || (direction == MessageFromPatient && !t.ownsFeedback(feedback, from.ID)) {
+		t.sendMessage(chatID, t.tr(chatID, "thread.not_found"))
+		return
+	}
+	if direction == MessageFromStaff && !t.canAccessFeedback(from.ID, PermHandleFeedback, feedback) {
+		t.sendMessage(chatID, t.tr(chatID, "access.denied"))
+		return
+	}
+
+	t.startReply(chatID, state, feedback, direction)
+}
+
+// startReply ждет следующее сообщение пользователя как ответ по обращению
+func (t *TelegramBot) startReply(chatID int64, state *UserState, feedback *Feedback, direction string) {
+	state.Reset()
+	state.State = StateWaitingForReply
+	state.Data["reply_feedback_id"] = strconv.FormatInt(feedback.ID, 10)
+	state.Data["reply_as"] = direction
+
+	// В группе бот видит только ответы на свои сообщения, поэтому просим ответить на это
+	msg := tgbotapi.NewMessage(chatID, t.tr(chatID, "thread.ask", ticketLabel(feedback)))
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+	t.bot.Send(msg)
+}
+
+// handleReplyInput принимает текст ответа после нажатия кнопки «Ответить»
+func (t *TelegramBot) handleReplyInput(message *tgbotapi.Message, state *UserState) {
+	chatID := message.Chat.ID
+	text := strings.TrimSpace(message.Text)
+	if text == "" {
+		t.sendMessage(chatID, t.tr(chatID, "thread.text_required"))
+		return
+	}
+
+	id, _ := strconv.ParseInt(state.Data["reply_feedback_id"], 10, 64)
+	direction := state.Data["reply_as"]
+	state.Reset()
+
+	feedback, err := t.database.GetFeedback(id)
+	if err != nil {
+		t.logger.Error("Failed to get feedback: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+	if feedback == nil {
+		t.sendMessage(chatID, t.tr(chatID, "thread.not_found"))
+		return
+	}
+
+	if direction == MessageFromStaff && t.canAccessFeedback(message.From.ID, PermHandleFeedback, feedback) {
+		t.sendStaffReply(chatID, message.From, feedback, text)
+		return
+	}
+	if direction == MessageFromPatient && t.ownsFeedback(feedback, message.From.ID) {
+		t.relayPatientMessage(chatID, feedback, text)
+		return
+	}
+	t.sendMessage(chatID, t.tr(chatID, "thread.not_found"))
+}
+
+// handleThreadReply обрабатывает ответ через reply на сообщение, которое доставил бот.
+// Возвращает false, если сообщение не относится к переписке по обращению.
+func (t *TelegramBot) handleThreadReply(message *tgbotapi.Message, state *UserState) bool {
+	if message.ReplyToMessage == nil || message.ReplyToMessage.From == nil || message.ReplyToMessage.From.ID != t.bot.Self.ID {
+		return false
+	}
+	text := strings.TrimSpace(message.Text)
+	if text == "" {
+		return false
+	}
+
+	chatID := message.Chat.ID
+	original, err := t.database.FindFeedbackMessage(t.vault.UserHash(chatID), message.ReplyToMessage.MessageID)
+	if err != nil {
+		t.logger.Error("Failed to find feedback message: ", err)
+		return false
+	}
+	if original == nil {
+		return t.handleCardReply(message, text)
+	}
+
+	feedback, err := t.database.GetFeedback(original.FeedbackID)
+	if err != nil || feedback == nil {
+		t.logger.Error("Failed to get feedback for thread reply: ", err)
+		return false
+	}
+
+	// На сообщение сотрудника отвечает пациент, на сообщение пациента - сотрудник
+	switch {
+	case original.Direction == MessageFromStaff && t.ownsFeedback(feedback, message.From.ID):
+		t.relayPatientMessage(chatID, feedback, text)
+	case original.Direction == MessageFromPatient && t.canAccessFeedback(message.From.ID, PermHandleFeedback, feedback):
+		t.sendStaffReply(chatID, message.From, feedback, text)
+	default:
+		return false
+	}
+	return true
+}
+
+// sendStaffReply доставляет ответ сотрудника автору обращения и сохраняет его в переписке
+func (t *TelegramBot) sendStaffReply(chatID int64, staff *tgbotapi.User, feedback *Feedback, text string) {
+	var recipientHash string
+	sent, err := t.SendToPatient(feedback, func(patientChatID int64) tgbotapi.MessageConfig {
+		recipientHash = t.vault.UserHash(patientChatID)
+		msg := tgbotapi.NewMessage(patientChatID, t.tr(patientChatID, "thread.staff_message", ticketLabel(feedback), text))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(t.tr(patientChatID, "thread.button.reply"), "reply:"+strconv.FormatInt(feedback.ID, 10)),
+			),
+		)
+		return msg
+	})
+	if err != nil {
+		t.logger.Error("Failed to deliver staff reply: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "thread.delivery_failed"))
+		return
+	}
+
+	record := &FeedbackMessage{
+		FeedbackID:        feedback.ID,
+		Direction:         MessageFromStaff,
+		AuthorID:          staff.ID,
+		Text:              text,
+		RecipientHash:     recipientHash,
+		TelegramMessageID: sent.MessageID,
+	}
+	if err := t.database.SaveFeedbackMessage(record); err != nil {
+		t.logger.Error("Failed to save staff reply: ", err)
+	}
+
+	t.sendMessage(chatID, t.tr(chatID, "thread.sent_to_patient", ticketLabel(feedback)))
+}
+
+// relayPatientMessage пересылает ответ пациента сотрудникам и сохраняет его в переписке
+func (t *TelegramBot) relayPatientMessage(chatID int64, feedback *Feedback, text string) {
+	record := &FeedbackMessage{
+		FeedbackID: feedback.ID,
+		Direction:  MessageFromPatient,
+		Text:       text,
+	}
+
+	if staffChat := staffChatID(); staffChat != 0 {
+		msg := tgbotapi.NewMessage(staffChat, t.tr(staffChat, "thread.patient_message", ticketLabel(feedback), RedactPII(t.lang(staffChat), text)))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(t.tr(staffChat, "thread.button.reply"), "staffreply:"+strconv.FormatInt(feedback.ID, 10)),
+			),
+		)
+		if sent, err := t.bot.Send(msg); err != nil {
+			t.logger.Error("Failed to relay patient message: ", err)
+		} else {
+			record.RecipientHash = t.vault.UserHash(staffChat)
+			record.TelegramMessageID = sent.MessageID
+		}
+	}
+
+	if err := t.database.SaveFeedbackMessage(record); err != nil {
+		t.logger.Error("Failed to save patient message: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.save"))
+		return
+	}
+
+	t.sendMessage(chatID, t.tr(chatID, "thread.sent_to_staff", ticketLabel(feedback)))
+}
+
+// handleCardReply отправляет пациенту ответ, написанный через reply на карточку обращения
+func (t *TelegramBot) handleCardReply(message *tgbotapi.Message, text string) bool {
+	chatID := message.Chat.ID
+	feedback, err := t.database.GetFeedbackByCard(chatID, message.ReplyToMessage.MessageID)
+	if err != nil {
+		t.logger.Error("Failed to get feedback by card: ", err)
+		return false
+	}
+	if feedback == nil || !t.canAccessFeedback(message.From.ID, PermHandleFeedback, feedback) {
+		return false
+	}
+
+	t.sendStaffReply(chatID, message.From, feedback, text)
+	return true
+}
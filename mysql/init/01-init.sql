@@ -4,6 +4,16 @@ CREATE DATABASE IF NOT EXISTS hospital_feedback CHARACTER SET utf8mb4 COLLATE ut
 -- Используем базу данных
 USE hospital_feedback;
 
+-- Создаем таблицу отделений
+CREATE TABLE IF NOT EXISTS departments (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
 -- Создаем таблицу feedback
 CREATE TABLE IF NOT EXISTS feedback (
     id BIGINT AUTO_INCREMENT PRIMARY KEY,
@@ -13,13 +23,210 @@ CREATE TABLE IF NOT EXISTS feedback (
     last_name VARCHAR(255),
     message TEXT NOT NULL,
     type ENUM('complaint', 'review') NOT NULL DEFAULT 'complaint',
-    status ENUM('new', 'processed', 'sent') DEFAULT 'new',
+    status ENUM('new', 'acknowledged', 'in_progress', 'awaiting_patient', 'resolved', 'rejected', 'reopened') DEFAULT 'new',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    department_id BIGINT NULL,
+    location VARCHAR(64) NULL,
+    is_anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+    reply_token VARCHAR(64) NULL,
+    phone VARCHAR(32) NULL,
+    rating TINYINT NULL,
+    ticket_code VARCHAR(16) NULL,
+    assignee_id BIGINT NULL,
+    assignee_name VARCHAR(255) NULL,
+    card_chat_id BIGINT NULL,
+    card_message_id BIGINT NULL,
+    due_at TIMESTAMP NULL DEFAULT NULL,
     INDEX idx_user_id (user_id),
     INDEX idx_type (type),
     INDEX idx_status (status),
-    INDEX idx_created_at (created_at)
+    INDEX idx_created_at (created_at),
+    INDEX idx_department_id (department_id),
+    UNIQUE INDEX idx_reply_token (reply_token),
+    UNIQUE INDEX idx_ticket_code (ticket_code),
+    INDEX idx_assignee_id (assignee_id),
+    INDEX idx_due_at (due_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем таблицу вложений к обращениям
+CREATE TABLE IF NOT EXISTS feedback_attachments (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    feedback_id BIGINT NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    file_id VARCHAR(255) NOT NULL,
+    file_unique_id VARCHAR(255) NOT NULL,
+    file_name VARCHAR(255),
+    mime_type VARCHAR(255),
+    size BIGINT NOT NULL DEFAULT 0,
+    storage_path VARCHAR(512) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_feedback_id (feedback_id),
+    INDEX idx_storage_path (storage_path(191)),
+    FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем таблицу связи анонимных обращений с авторами.
+-- Ее читает только бот для доставки ответов; доступ к ней можно ограничить отдельными GRANT.
+CREATE TABLE IF NOT EXISTS feedback_identities (
+    feedback_id BIGINT PRIMARY KEY,
+    reply_token VARCHAR(64) NOT NULL UNIQUE,
+    user_hash CHAR(64) NOT NULL,
+    user_id BIGINT NULL,
+    chat_id BIGINT NULL,
+    chat_id_sealed VARBINARY(64) NULL,
+    username VARCHAR(255) NULL,
+    first_name VARCHAR(255) NULL,
+    last_name VARCHAR(255) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_hash (user_hash),
+    FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем таблицу состояний диалога
+CREATE TABLE IF NOT EXISTS user_states (
+    user_id BIGINT PRIMARY KEY,
+    state VARCHAR(64) NOT NULL,
+    data TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем таблицу выбранных пользователями языков интерфейса
+CREATE TABLE IF NOT EXISTS user_languages (
+    user_id BIGINT PRIMARY KEY,
+    language VARCHAR(8) NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем таблицу ответов на дополнительные вопросы анкеты
+CREATE TABLE IF NOT EXISTS feedback_answers (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    feedback_id BIGINT NOT NULL,
+    step_id VARCHAR(64) NOT NULL,
+    question TEXT NOT NULL,
+    value TEXT NOT NULL,
+    label TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_feedback_id (feedback_id),
+    INDEX idx_step_id (step_id),
+    FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем таблицу версий анкеты
+CREATE TABLE IF NOT EXISTS form_definitions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    definition MEDIUMTEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_active (active)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем таблицу переписки сотрудников с авторами обращений
+CREATE TABLE IF NOT EXISTS feedback_messages (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    feedback_id BIGINT NOT NULL,
+    direction ENUM('staff', 'patient') NOT NULL,
+    author_id BIGINT NULL,
+    text TEXT NOT NULL,
+    recipient_hash CHAR(64) NULL,
+    telegram_message_id BIGINT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_feedback_id (feedback_id),
+    INDEX idx_recipient_message (recipient_hash, telegram_message_id),
+    FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем таблицу истории статусов обращений
+CREATE TABLE IF NOT EXISTS feedback_status_history (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    feedback_id BIGINT NOT NULL,
+    from_status VARCHAR(32) NOT NULL,
+    to_status VARCHAR(32) NOT NULL,
+    actor_id BIGINT NULL,
+    actor_name VARCHAR(255) NULL,
+    source VARCHAR(16) NOT NULL,
+    comment TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_feedback_id (feedback_id),
+    INDEX idx_created_at (created_at),
+    FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем таблицу очереди автоназначения ответственных
+CREATE TABLE IF NOT EXISTS assignment_rotation (
+    department_id BIGINT PRIMARY KEY,
+    last_user_id BIGINT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем таблицу политик SLA
+CREATE TABLE IF NOT EXISTS sla_policies (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    feedback_type VARCHAR(16) NOT NULL DEFAULT '',
+    department_id BIGINT NOT NULL DEFAULT 0,
+    hours INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_scope (feedback_type, department_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем календарь праздничных дней
+CREATE TABLE IF NOT EXISTS holidays (
+    day DATE PRIMARY KEY,
+    name VARCHAR(255) NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем журнал эскалаций
+CREATE TABLE IF NOT EXISTS feedback_escalations (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    feedback_id BIGINT NOT NULL,
+    level INT NOT NULL,
+    role VARCHAR(32) NOT NULL,
+    recipients TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_feedback_level (feedback_id, level),
+    FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем журнал отправленных сводок
+CREATE TABLE IF NOT EXISTS digest_log (
+    kind VARCHAR(16) NOT NULL,
+    day DATE NOT NULL,
+    recipients TEXT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (kind, day)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем журнал отправленных обращений для антиспама (пользователь - хеш, текст - отпечаток simhash)
+CREATE TABLE IF NOT EXISTS feedback_submissions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_hash CHAR(64) NOT NULL,
+    fingerprint BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    INDEX idx_user_created (user_hash, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем счетчики отклоненных антиспамом обращений
+CREATE TABLE IF NOT EXISTS spam_rejections (
+    day DATE NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    count INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, reason)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Создаем таблицу сотрудников с ролями
+CREATE TABLE IF NOT EXISTS admins (
+    user_id BIGINT PRIMARY KEY,
+    role ENUM('super_admin', 'handler', 'viewer', 'department_head') NOT NULL,
+    department_id BIGINT NULL,
+    name VARCHAR(255) NULL,
+    email VARCHAR(255) NULL,
+    added_by BIGINT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_role (role),
+    INDEX idx_department_id (department_id)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
 -- Создаем пользователя
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Размер плаката в PNG: A4 при 150 dpi
+const (
+	posterWidth  = 1240
+	posterHeight = 1754
+)
+
+// Poster - содержимое одного плаката с QR-кодом
+type Poster struct {
+	Payload StartPayload
+	Caption string
+	Link    string
+}
+
+// PosterRenderer рисует фирменные плакаты с QR-кодом без внешних сервисов
+type PosterRenderer struct {
+	botUsername string
+	title       string
+	subtitle    string
+	accent      color.RGBA
+	font        *sfnt.Font
+}
+
+// loadFontFromEnv загружает шрифт из POSTER_FONT_PATH или встроенный Go Regular; общий для плакатов и графиков
+func loadFontFromEnv() (*sfnt.Font, error) {
+	fontData := goregular.TTF
+	if path := getEnv("POSTER_FONT_PATH", ""); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read poster font: %w", err)
+		}
+		fontData = data
+	}
+
+	f, err := opentype.Parse(fontData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse poster font: %w", err)
+	}
+	return f, nil
+}
+
+func NewPosterRendererFromEnv(botUsername string) (*PosterRenderer, error) {
+	f, err := loadFontFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	accent, err := parseHexColor(getEnv("POSTER_COLOR", "#1E6FB8"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &PosterRenderer{
+		botUsername: botUsername,
+		title:       getEnv("POSTER_TITLE", "Аурухананың кері байланыс жүйесі"),
+		subtitle:    getEnv("POSTER_SUBTITLE", "Шағым немесе пікір қалдыру үшін QR-кодты сканерлеңіз"),
+		accent:      accent,
+		font:        f,
+	}, nil
+}
+
+// NewPoster формирует плакат для отделения и/или палаты
+func (r *PosterRenderer) NewPoster(department *Department, ward string) *Poster {
+	payload := StartPayload{Ward: ward}
+	var labels []string
+	if department != nil {
+		payload.DepartmentCode = department.Code
+		labels = append(labels, department.Name)
+	}
+	if ward != "" {
+		labels = append(labels, fmt.Sprintf("№%s палата", ward))
+	}
+
+	return &Poster{
+		Payload: payload,
+		Caption: strings.Join(labels, " · "),
+		Link:    botLink(r.botUsername, payload),
+	}
+}
+
+func (r *PosterRenderer) qrBitmap(link string) ([][]bool, error) {
+	code, err := qrcode.New(link, qrcode.High)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode qr code: %w", err)
+	}
+	return code.Bitmap(), nil
+}
+
+// RenderPNG рисует плакат в PNG
+func (r *PosterRenderer) RenderPNG(w io.Writer, poster *Poster) error {
+	bitmap, err := r.qrBitmap(poster.Link)
+	if err != nil {
+		return err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, posterWidth, posterHeight))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	// Шапка в фирменном цвете
+	draw.Draw(img, image.Rect(0, 0, posterWidth, 300), &image.Uniform{r.accent}, image.Point{}, draw.Src)
+	if err := r.drawText(img, r.title, 64, color.White, 130, posterWidth-160); err != nil {
+		return err
+	}
+
+	if err := r.drawText(img, r.subtitle, 40, color.Black, 380, posterWidth-160); err != nil {
+		return err
+	}
+
+	// QR-код по центру
+	modules := len(bitmap)
+	scale := 900 / modules
+	size := modules * scale
+	left := (posterWidth - size) / 2
+	top := 470
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				rect := image.Rect(left+x*scale, top+y*scale, left+(x+1)*scale, top+(y+1)*scale)
+				draw.Draw(img, rect, image.Black, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	if poster.Caption != "" {
+		if err := r.drawText(img, poster.Caption, 56, r.accent, top+size+110, posterWidth-160); err != nil {
+			return err
+		}
+	}
+
+	// Подвал с адресом бота
+	draw.Draw(img, image.Rect(0, posterHeight-120, posterWidth, posterHeight), &image.Uniform{r.accent}, image.Point{}, draw.Src)
+	if err := r.drawText(img, "Telegram: @"+r.botUsername, 40, color.White, posterHeight-45, posterWidth); err != nil {
+		return err
+	}
+
+	return png.Encode(w, img)
+}
+
+// drawText выводит текст по центру с переносом по словам; baseline - базовая линия первой строки
+func (r *PosterRenderer) drawText(img draw.Image, text string, size float64, c color.Color, baseline, maxWidth int) error {
+	face, err := opentype.NewFace(r.font, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return fmt.Errorf("failed to create font face: %w", err)
+	}
+	defer face.Close()
+
+	drawer := &font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face}
+	lineHeight := int(size * 1.3)
+	text = fallbackGlyphs(r.font, text)
+
+	for i, line := range wrapText(drawer, text, maxWidth) {
+		width := drawer.MeasureString(line).Ceil()
+		drawer.Dot = fixed.P((img.Bounds().Dx()-width)/2, baseline+i*lineHeight)
+		drawer.DrawString(line)
+	}
+	return nil
+}
+
+// kazakhFallback заменяет казахские буквы близкими русскими, если их нет в шрифте
+var kazakhFallback = map[rune]rune{
+	'Ә': 'А', 'ә': 'а', 'Ғ': 'Г', 'ғ': 'г', 'Қ': 'К', 'қ': 'к', 'Ң': 'Н', 'ң': 'н',
+	'Ө': 'О', 'ө': 'о', 'Ұ': 'У', 'ұ': 'у', 'Ү': 'У', 'ү': 'у', 'Һ': 'Х', 'һ': 'х',
+}
+
+func fallbackGlyphs(f *sfnt.Font, text string) string {
+	var buf sfnt.Buffer
+	return strings.Map(func(ch rune) rune {
+		if replacement, ok := kazakhFallback[ch]; ok {
+			if index, err := f.GlyphIndex(&buf, ch); err != nil || index == 0 {
+				return replacement
+			}
+		}
+		return ch
+	}, text)
+}
+
+func wrapText(drawer *font.Drawer, text string, maxWidth int) []string {
+	var lines []string
+	var current string
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if current != "" && drawer.MeasureString(candidate).Ceil() > maxWidth {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		current = candidate
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// RenderSVG рисует плакат в SVG тех же пропорций, что и PNG
+func (r *PosterRenderer) RenderSVG(w io.Writer, poster *Poster) error {
+	bitmap, err := r.qrBitmap(poster.Link)
+	if err != nil {
+		return err
+	}
+
+	accent := fmt.Sprintf("#%02X%02X%02X", r.accent.R, r.accent.G, r.accent.B)
+	modules := len(bitmap)
+	scale := 900 / modules
+	size := modules * scale
+	left := (posterWidth - size) / 2
+	top := 470
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="210mm" height="297mm" font-family="Arial, Helvetica, sans-serif" text-anchor="middle">`, posterWidth, posterHeight)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#FFFFFF"/>`, posterWidth, posterHeight)
+	fmt.Fprintf(&buf, `<rect width="%d" height="300" fill="%s"/>`, posterWidth, accent)
+	fmt.Fprintf(&buf, `<text x="%d" y="170" font-size="64" fill="#FFFFFF">%s</text>`, posterWidth/2, html.EscapeString(r.title))
+	fmt.Fprintf(&buf, `<text x="%d" y="380" font-size="40" fill="#000000">%s</text>`, posterWidth/2, html.EscapeString(r.subtitle))
+
+	// Каждая строка QR-кода рисуется отрезками подряд идущих темных модулей
+	fmt.Fprintf(&buf, `<g fill="#000000" shape-rendering="crispEdges">`)
+	for y, row := range bitmap {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d"/>`, left+start*scale, top+y*scale, (x-start)*scale, scale)
+		}
+	}
+	buf.WriteString(`</g>`)
+
+	if poster.Caption != "" {
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" font-size="56" fill="%s">%s</text>`, posterWidth/2, top+size+110, accent, html.EscapeString(poster.Caption))
+	}
+	fmt.Fprintf(&buf, `<rect y="%d" width="%d" height="120" fill="%s"/>`, posterHeight-120, posterWidth, accent)
+	fmt.Fprintf(&buf, `<text x="%d" y="%d" font-size="40" fill="#FFFFFF">Telegram: @%s</text>`, posterWidth/2, posterHeight-45, html.EscapeString(r.botUsername))
+	buf.WriteString(`</svg>`)
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// RenderSheet формирует HTML-страницу для печати: по одному плакату на лист
+func (r *PosterRenderer) RenderSheet(w io.Writer, posters []*Poster) error {
+	var buf bytes.Buffer
+	buf.WriteString(`<!DOCTYPE html><html><head><meta charset="utf-8"><title>QR</title><style>` +
+		`@page{size:A4;margin:0}body{margin:0}` +
+		`.page{width:210mm;height:297mm;page-break-after:always;overflow:hidden}` +
+		`.page:last-child{page-break-after:auto}svg{display:block}` +
+		`</style></head><body>`)
+
+	for _, poster := range posters {
+		buf.WriteString(`<div class="page">`)
+		if err := r.RenderSVG(&buf, poster); err != nil {
+			return err
+		}
+		buf.WriteString(`</div>`)
+	}
+	buf.WriteString(`</body></html>`)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// maxSheetWards - сколько палат можно напечатать на одном листе /qr/sheet
+const maxSheetWards = 500
+
+// parseWardList разбирает список палат вида "101-120,201,305A".
+// Возвращает ошибку, если палат больше maxSheetWards.
+func parseWardList(value string) ([]string, error) {
+	var wards []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if from, to, ok := strings.Cut(part, "-"); ok {
+			start, err1 := strconv.Atoi(from)
+			end, err2 := strconv.Atoi(to)
+			if err1 == nil && err2 == nil && start <= end {
+				if end-start >= maxSheetWards-len(wards) {
+					return nil, fmt.Errorf("too many wards, at most %d", maxSheetWards)
+				}
+				for ward := start; ward <= end; ward++ {
+					wards = append(wards, strconv.Itoa(ward))
+				}
+				continue
+			}
+		}
+		if wardPattern.MatchString(part) {
+			if len(wards) >= maxSheetWards {
+				return nil, fmt.Errorf("too many wards, at most %d", maxSheetWards)
+			}
+			wards = append(wards, part)
+		}
+	}
+	return wards, nil
+}
+
+func parseHexColor(value string) (color.RGBA, error) {
+	value = strings.TrimPrefix(value, "#")
+	if len(value) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid color: %s", value)
+	}
+	rgb, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color: %s", value)
+	}
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 255}, nil
+}
+
+// posterHandler отдает плакат для отделения и/или палаты: /qr/poster?dept=cardio&ward=12&format=png
+func (a *App) posterHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var department *Department
+	if code := query.Get("dept"); code != "" {
+		dep, err := a.database.GetDepartmentByCode(code)
+		if err != nil {
+			a.logger.Error("Failed to get department: ", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		if dep == nil {
+			http.Error(w, "department not found", http.StatusNotFound)
+			return
+		}
+		department = dep
+	}
+
+	ward := query.Get("ward")
+	if ward != "" && !wardPattern.MatchString(ward) {
+		http.Error(w, "invalid ward", http.StatusBadRequest)
+		return
+	}
+
+	poster := a.posters.NewPoster(department, ward)
+
+	var err error
+	switch query.Get("format") {
+	case "svg":
+		w.Header().Set("Content-Type", "image/svg+xml")
+		err = a.posters.RenderSVG(w, poster)
+	case "", "png":
+		var buf bytes.Buffer
+		if err = a.posters.RenderPNG(&buf, poster); err == nil {
+			w.Header().Set("Content-Type", "image/png")
+			_, err = w.Write(buf.Bytes())
+		}
+	default:
+		http.Error(w, "unknown format", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		a.logger.Error("Failed to render poster: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// posterSheetHandler отдает лист для печати со всеми отделениями и палатами: /qr/sheet?wards=101-120
+func (a *App) posterSheetHandler(w http.ResponseWriter, r *http.Request) {
+	if !a.authorizeAPI(w, r) {
+		return
+	}
+
+	wardList := r.URL.Query().Get("wards")
+	if wardList == "" {
+		wardList = getEnv("POSTER_WARDS", "")
+	}
+	wards, err := parseWardList(wardList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	departments, err := a.database.ListDepartments(true)
+	if err != nil {
+		a.logger.Error("Failed to list departments: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	var posters []*Poster
+	for _, department := range departments {
+		posters = append(posters, a.posters.NewPoster(department, ""))
+	}
+	for _, ward := range wards {
+		posters = append(posters, a.posters.NewPoster(nil, ward))
+	}
+	if len(posters) == 0 {
+		posters = append(posters, a.posters.NewPoster(nil, ""))
+	}
+
+	var buf bytes.Buffer
+	if err := a.posters.RenderSheet(&buf, posters); err != nil {
+		a.logger.Error("Failed to render poster sheet: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWardList(t *testing.T) {
+	wards, err := parseWardList(" 101-103, 205A,,bad ward, 7")
+	if err != nil {
+		t.Fatalf("parseWardList returned error: %v", err)
+	}
+	if want := []string{"101", "102", "103", "205A", "7"}; !reflect.DeepEqual(wards, want) {
+		t.Fatalf("wards = %v, want %v", wards, want)
+	}
+
+	if wards, err := parseWardList("1-500"); err != nil || len(wards) != maxSheetWards {
+		t.Fatalf("parseWardList(1-500) = %d wards, %v", len(wards), err)
+	}
+	for _, value := range []string{"1-10000000", "1-500,501", "1-250,300-550"} {
+		if _, err := parseWardList(value); err == nil {
+			t.Fatalf("parseWardList(%q) accepted more than %d wards", value, maxSheetWards)
+		}
+	}
+}
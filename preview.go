@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// previewMessageLimit - сколько символов текста обращения показывать в предпросмотре,
+// чтобы сообщение уложилось в лимит Telegram
+const previewMessageLimit = 3000
+
+// showPreview показывает собранное обращение и ждет подтверждения отправки
+func (t *TelegramBot) showPreview(chatID int64, state *UserState) {
+	state.State = StateWaitingForConfirm
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.submit"), "confirm:submit"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.edit_text"), "confirm:edit"),
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.change_type"), "confirm:type"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.cancel"), "confirm:cancel"),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, t.previewText(t.lang(chatID), state))
+	msg.ReplyMarkup = keyboard
+	t.bot.Send(msg)
+}
+
+// previewText формирует текст предпросмотра из состояния диалога
+func (t *TelegramBot) previewText(lang string, state *UserState) string {
+	var sb strings.Builder
+	sb.WriteString(Translate(lang, "preview.title") + "\n\n")
+	sb.WriteString(Translate(lang, "preview.type", feedbackTypeLabel(lang, state.Data["type"])) + "\n")
+
+	if state.Data["anonymous"] == "1" {
+		sb.WriteString(Translate(lang, "preview.anonymous") + "\n")
+	}
+	if rating, ok := parseRating(state.Data["rating"]); ok && state.Data["type"] == "review" {
+		sb.WriteString(Translate(lang, "preview.rating", ratingStars(rating)) + "\n")
+	}
+	if departmentID, err := strconv.ParseInt(state.Data["department_id"], 10, 64); err == nil {
+		if department, err := t.database.GetDepartment(departmentID); err == nil && department != nil {
+			sb.WriteString(Translate(lang, "preview.department", department.Name) + "\n")
+		}
+	}
+	if location := state.Data["location"]; location != "" {
+		sb.WriteString("📍 " + locationDisplayName(lang, location) + "\n")
+	}
+	for _, answer := range t.form().Answers(state.Data, lang) {
+		sb.WriteString(fmt.Sprintf("• %s %s\n", answer.Question, answer.Label))
+	}
+	if phone := state.Data["phone"]; phone != "" && state.Data["anonymous"] != "1" {
+		sb.WriteString(Translate(lang, "preview.phone", phone) + "\n")
+	}
+	if attachments := pendingAttachments(state); len(attachments) > 0 {
+		sb.WriteString(Translate(lang, "preview.files", len(attachments)) + "\n")
+	}
+
+	sb.WriteString("\n" + Translate(lang, "preview.message") + "\n" + truncateRunes(state.Data["message"], previewMessageLimit))
+	sb.WriteString("\n\n" + Translate(lang, "preview.footer"))
+	return sb.String()
+}
+
+// handleConfirmation обрабатывает кнопки предпросмотра confirm:<действие>
+func (t *TelegramBot) handleConfirmation(callback *tgbotapi.CallbackQuery, state *UserState, action string) {
+	chatID := callback.Message.Chat.ID
+
+	if state.State != StateWaitingForConfirm {
+		t.sendMainMenu(chatID, callback.From.ID)
+		return
+	}
+
+	// Убираем кнопки со старого предпросмотра, чтобы их нельзя было нажать повторно
+	t.bot.Send(tgbotapi.NewEditMessageReplyMarkup(chatID, callback.Message.MessageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+
+	switch action {
+	case "submit":
+		t.submitFeedback(chatID, callback.From, state, state.Data["message"])
+	case "edit":
+		step := t.form().StepByKind(StepMessage)
+		delete(state.Data, "message")
+		unmarkStepVisited(state, step.ID)
+		state.Data["editing"] = "1"
+		t.enterStep(chatID, callback.From, state, t.form().First())
+	case "type":
+		if state.Data["type"] == "review" {
+			state.Data["type"] = "complaint"
+		} else {
+			state.Data["type"] = "review"
+		}
+		t.sendMessage(chatID, t.tr(chatID, "preview.type_changed", feedbackTypeLabel(t.lang(chatID), state.Data["type"])))
+		// Новый тип может включить шаги анкеты, которые еще не задавались
+		state.Data["editing"] = "1"
+		t.enterStep(chatID, callback.From, state, t.form().First())
+	case "cancel":
+		t.cancelFeedback(chatID, callback.From.ID, state)
+	default:
+		t.showPreview(chatID, state)
+	}
+}
+
+// cancelFeedback прерывает заполнение обращения из любого состояния
+func (t *TelegramBot) cancelFeedback(chatID, userID int64, state *UserState) {
+	text := t.tr(chatID, "cancel.nothing")
+	if state.State != StateStart {
+		text = t.tr(chatID, "cancel.done")
+	}
+
+	t.discardPendingAttachments(state)
+	state.Reset()
+	// Клавиатура шага с номером телефона могла остаться на экране
+	t.removeReplyKeyboard(chatID, text)
+	t.sendMainMenu(chatID, userID)
+}
+
+// feedbackTypeLabel - название типа обращения с иконкой для кнопок и предпросмотра
+func feedbackTypeLabel(lang, feedbackType string) string {
+	switch feedbackType {
+	case "complaint":
+		return "📝 " + getTypeDisplayName(lang, feedbackType)
+	case "review":
+		return "⭐ " + getTypeDisplayName(lang, feedbackType)
+	default:
+		return feedbackType
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Оценка отзыва - от 1 до 5 звезд
+const (
+	minRating = 1
+	maxRating = 5
+)
+
+// RatingStats - распределение и средняя оценка отзывов, а также динамика:
+// последние RATING_TREND_DAYS дней сравниваются с таким же периодом перед ними
+type RatingStats struct {
+	Count        int            `json:"count"`
+	Sum          int            `json:"sum"`
+	Distribution [maxRating]int `json:"distribution"`
+
+	RecentCount   int `json:"recent_count"`
+	RecentSum     int `json:"recent_sum"`
+	PreviousCount int `json:"previous_count"`
+	PreviousSum   int `json:"previous_sum"`
+}
+
+func (s *RatingStats) add(rating, count int, period ratingPeriod) {
+	if rating < minRating || rating > maxRating {
+		return
+	}
+	s.Count += count
+	s.Sum += rating * count
+	s.Distribution[rating-1] += count
+
+	switch period {
+	case ratingPeriodRecent:
+		s.RecentCount += count
+		s.RecentSum += rating * count
+	case ratingPeriodPrevious:
+		s.PreviousCount += count
+		s.PreviousSum += rating * count
+	}
+}
+
+func (s *RatingStats) Average() float64 {
+	return average(s.Sum, s.Count)
+}
+
+func (s *RatingStats) RecentAverage() float64 {
+	return average(s.RecentSum, s.RecentCount)
+}
+
+func (s *RatingStats) PreviousAverage() float64 {
+	return average(s.PreviousSum, s.PreviousCount)
+}
+
+// Trend - изменение средней оценки за последний период; false, если сравнивать не с чем
+func (s *RatingStats) Trend() (float64, bool) {
+	if s.RecentCount == 0 || s.PreviousCount == 0 {
+		return 0, false
+	}
+	return s.RecentAverage() - s.PreviousAverage(), true
+}
+
+func average(sum, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(sum) / float64(count)
+}
+
+type ratingPeriod int
+
+const (
+	ratingPeriodOlder ratingPeriod = iota
+	ratingPeriodRecent
+	ratingPeriodPrevious
+)
+
+// ratingTrendWindow - длина периода для сравнения средних оценок
+func ratingTrendWindow() time.Duration {
+	return time.Duration(getEnvAsInt("RATING_TREND_DAYS", 30)) * 24 * time.Hour
+}
+
+// GetRatingStats собирает статистику оценок в целом и по отделениям (ключ 0 - без отделения);
+// departmentID != 0 ограничивает ее одним отделением, assigneeID != 0 - одним ответственным
+func (d *Database) GetRatingStats(departmentID, assigneeID int64) (*RatingStats, map[int64]*RatingStats, error) {
+	now := time.Now().UTC()
+	window := ratingTrendWindow()
+
+	query := `
+	SELECT
+		COALESCE(department_id, 0),
+		rating,
+		CASE
+			WHEN created_at >= ? THEN 1
+			WHEN created_at >= ? THEN 2
+			ELSE 0
+		END AS period,
+		COUNT(*)
+	FROM feedback
+	WHERE type = 'review' AND rating IS NOT NULL AND (? = 0 OR department_id = ?) AND (? = 0 OR assignee_id = ?)
+	GROUP BY 1, 2, 3
+	`
+
+	rows, err := d.db.Query(query, now.Add(-window), now.Add(-2*window), departmentID, departmentID, assigneeID, assigneeID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get rating stats: %w", err)
+	}
+	defer rows.Close()
+
+	overall := &RatingStats{}
+	byDepartment := make(map[int64]*RatingStats)
+	for rows.Next() {
+		var departmentID int64
+		var rating, period, count int
+		if err := rows.Scan(&departmentID, &rating, &period, &count); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan rating stats: %w", err)
+		}
+
+		overall.add(rating, count, ratingPeriod(period))
+		if byDepartment[departmentID] == nil {
+			byDepartment[departmentID] = &RatingStats{}
+		}
+		byDepartment[departmentID].add(rating, count, ratingPeriod(period))
+	}
+
+	return overall, byDepartment, nil
+}
+
+// askRating предлагает оценить больницу звездами; шаг задается только для отзывов
+func (t *TelegramBot) askRating(chatID int64, state *UserState) bool {
+	if state.Data["type"] != "review" {
+		return false
+	}
+
+	var stars []tgbotapi.InlineKeyboardButton
+	for rating := minRating; rating <= maxRating; rating++ {
+		stars = append(stars, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%d ⭐", rating), fmt.Sprintf("rate:%d", rating)))
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		stars,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.skip"), "rate:skip"),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, t.tr(chatID, "rating.ask"))
+	msg.ReplyMarkup = keyboard
+	t.bot.Send(msg)
+	return true
+}
+
+// handleRatingSelection обрабатывает нажатие rate:<1-5> или rate:skip
+func (t *TelegramBot) handleRatingSelection(chatID int64, from *tgbotapi.User, state *UserState, value string) {
+	if state.State != StateWaitingForRating {
+		t.sendMainMenu(chatID, from.ID)
+		return
+	}
+
+	if value == "skip" {
+		delete(state.Data, "rating")
+		t.advanceForm(chatID, from, state)
+		return
+	}
+
+	rating, ok := parseRating(value)
+	if !ok {
+		t.askRating(chatID, state)
+		return
+	}
+	state.Data["rating"] = strconv.Itoa(rating)
+	t.advanceForm(chatID, from, state)
+}
+
+// handleRatingInput принимает оценку, написанную цифрой
+func (t *TelegramBot) handleRatingInput(message *tgbotapi.Message, state *UserState) {
+	rating, ok := parseRating(message.Text)
+	if !ok {
+		t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "rating.invalid"))
+		t.askRating(message.Chat.ID, state)
+		return
+	}
+
+	state.Data["rating"] = strconv.Itoa(rating)
+	t.advanceForm(message.Chat.ID, message.From, state)
+}
+
+func parseRating(value string) (int, bool) {
+	rating, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || rating < minRating || rating > maxRating {
+		return 0, false
+	}
+	return rating, true
+}
+
+// ratingStars рисует оценку звездами, например ★★★★☆
+func ratingStars(rating int) string {
+	if rating < minRating || rating > maxRating {
+		return ""
+	}
+	return strings.Repeat("★", rating) + strings.Repeat("☆", maxRating-rating)
+}
+
+// trendArrow показывает направление изменения средней оценки
+func trendArrow(delta float64) string {
+	switch {
+	case delta >= 0.05:
+		return "↑"
+	case delta <= -0.05:
+		return "↓"
+	default:
+		return "→"
+	}
+}
+
+// formatRatingStats описывает оценки для /stats
+func (t *TelegramBot) formatRatingStats(chatID int64, stats *RatingStats) string {
+	if stats == nil || stats.Count == 0 {
+		return t.tr(chatID, "rating.stats.empty")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(t.tr(chatID, "rating.stats.average", stats.Average(), stats.Count))
+	for rating := maxRating; rating >= minRating; rating-- {
+		sb.WriteString(fmt.Sprintf("\n%s %d", ratingStars(rating), stats.Distribution[rating-1]))
+	}
+
+	days := int(ratingTrendWindow().Hours() / 24)
+	if delta, ok := stats.Trend(); ok {
+		sb.WriteString("\n" + t.tr(chatID, "rating.stats.trend", days, stats.RecentAverage(), stats.PreviousAverage(), trendArrow(delta), delta))
+	} else if stats.RecentCount > 0 {
+		sb.WriteString("\n" + t.tr(chatID, "rating.stats.recent", days, stats.RecentAverage()))
+	}
+	return sb.String()
+}
+
+// formatDepartmentRating - краткая оценка отделения для строки в /stats
+func formatDepartmentRating(stats *RatingStats) string {
+	if stats == nil || stats.Count == 0 {
+		return ""
+	}
+	text := fmt.Sprintf(" — ★ %.1f", stats.Average())
+	if delta, ok := stats.Trend(); ok {
+		text += " " + trendArrow(delta)
+	}
+	return text
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Виды персональных идентификаторов, которые скрываются в уведомлениях
+const (
+	PIIIIN   = "iin"
+	PIICard  = "card"
+	PIIPhone = "phone"
+	PIIEmail = "email"
+)
+
+var (
+	piiEmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`)
+	// Последовательность цифр с пробелами, дефисами и скобками между ними: 8 (701) 123-45-67, 4400 4301 2345 6789
+	piiNumberPattern = regexp.MustCompile(`\+?\(?\d(?:[ \-()]{0,2}\d)*\)?`)
+)
+
+// redactionEnabled - скрывать ли идентификаторы (REDACT_PII=false отключает)
+func redactionEnabled() bool {
+	return getEnv("REDACT_PII", "true") != "false"
+}
+
+// RedactPII заменяет ИИН, номера карт, телефонов и email в тексте пациента на пометки вида «[ИИН скрыт]».
+// Номер карты принимается только с верной контрольной суммой Луна, ИИН - с верным контрольным разрядом,
+// поэтому даты, номера палат и суммы остаются как есть.
+func RedactPII(lang, text string) string {
+	if text == "" || !redactionEnabled() {
+		return text
+	}
+
+	text = piiEmailPattern.ReplaceAllStringFunc(text, func(string) string {
+		return Translate(lang, "redact."+PIIEmail)
+	})
+	return piiNumberPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if masked, ok := redactNumber(lang, match); ok {
+			return masked
+		}
+		return redactNumberSpans(lang, match)
+	})
+}
+
+// redactNumberSpans ищет идентификаторы в нескольких числах через пробел, например
+// "палата 12 8 701 123 45 67": слева направо пробуется самый длинный подряд идущий
+// набор групп, поэтому телефон или карта, записанные с пробелами, скрываются целиком
+func redactNumberSpans(lang, match string) string {
+	parts := strings.Split(match, " ")
+	if len(parts) == 1 {
+		return match
+	}
+
+	var result []string
+	for i := 0; i < len(parts); {
+		end := 0
+		if parts[i] != "" {
+			for j := len(parts); j > i; j-- {
+				if parts[j-1] == "" {
+					continue
+				}
+				if masked, ok := redactNumber(lang, strings.Join(parts[i:j], " ")); ok {
+					result = append(result, masked)
+					end = j
+					break
+				}
+			}
+		}
+		if end == 0 {
+			result = append(result, parts[i])
+			end = i + 1
+		}
+		i = end
+	}
+	return strings.Join(result, " ")
+}
+
+// redactNumber определяет, является ли число идентификатором, и возвращает пометку для него
+func redactNumber(lang, match string) (string, bool) {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, match)
+
+	switch kind := classifyNumber(digits, strings.HasPrefix(match, "+")); kind {
+	case PIIIIN:
+		return Translate(lang, "redact."+kind), true
+	case PIICard:
+		return Translate(lang, "redact."+kind, digits[len(digits)-4:]), true
+	case PIIPhone:
+		return Translate(lang, "redact."+kind, digits[len(digits)-2:]), true
+	}
+	return "", false
+}
+
+// classifyNumber - вид идентификатора по цифрам числа; пустая строка - обычное число
+func classifyNumber(digits string, international bool) string {
+	switch {
+	case len(digits) == 12 && validIIN(digits):
+		return PIIIIN
+	case len(digits) >= 13 && len(digits) <= 19 && validLuhn(digits):
+		return PIICard
+	// Казахстанские и российские номера: +7 / 8 и 10 цифр или 10 цифр мобильного без кода страны
+	case len(digits) == 11 && (digits[0] == '7' || digits[0] == '8'):
+		return PIIPhone
+	case len(digits) == 10 && digits[0] == '7' && !international:
+		return PIIPhone
+	case international && len(digits) >= 10 && len(digits) <= 15:
+		return PIIPhone
+	}
+	return ""
+}
+
+// validIIN проверяет контрольный разряд ИИН: сумма первых 11 цифр с весами 1..11 по модулю 11,
+// при остатке 10 - повторно с весами 3..11, 1, 2; остаток 10 во втором проходе означает неверный ИИН
+func validIIN(digits string) bool {
+	if len(digits) != 12 {
+		return false
+	}
+	checksum := func(weights []int) int {
+		sum := 0
+		for i, weight := range weights {
+			sum += int(digits[i]-'0') * weight
+		}
+		return sum % 11
+	}
+
+	control := checksum([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11})
+	if control == 10 {
+		control = checksum([]int{3, 4, 5, 6, 7, 8, 9, 10, 11, 1, 2})
+		if control == 10 {
+			return false
+		}
+	}
+	return control == int(digits[11]-'0')
+}
+
+// validLuhn проверяет номер банковской карты по алгоритму Луна
+func validLuhn(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := int(digits[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// redactFeedback возвращает копию обращения со скрытыми идентификаторами в тексте и ответах анкеты.
+// Исходный текст остается в базе и виден сотрудникам с PermViewPersonalData.
+func redactFeedback(lang string, feedback *Feedback) *Feedback {
+	redacted := *feedback
+	redacted.Message = RedactPII(lang, feedback.Message)
+	redacted.Answers = make([]*FeedbackAnswer, len(feedback.Answers))
+	for i, answer := range feedback.Answers {
+		copied := *answer
+		copied.Label = RedactPII(lang, answer.Label)
+		redacted.Answers[i] = &copied
+	}
+	return &redacted
+}
+
+// canSeeIdentifiers - может ли сотрудник видеть текст обращения без скрытия идентификаторов
+func (t *TelegramBot) canSeeIdentifiers(userID int64) bool {
+	return t.authorize(userID, PermViewPersonalData) != nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import "testing"
+
+func TestValidIIN(t *testing.T) {
+	tests := []struct {
+		iin  string
+		want bool
+	}{
+		{"900101300007", true},
+		{"851205401236", true},
+		{"900101300008", false},
+		// Первый проход дает остаток 10, контрольный разряд берется из второго прохода
+		{"900101300811", true},
+		{"900101300813", false},
+		// Остаток 10 в обоих проходах - такой ИИН не выдается
+		{"900101300800", false},
+		{"90010130000", false},
+		{"9001013000070", false},
+	}
+
+	for _, tt := range tests {
+		if got := validIIN(tt.iin); got != tt.want {
+			t.Errorf("validIIN(%q) = %v, want %v", tt.iin, got, tt.want)
+		}
+	}
+}
+
+func TestValidLuhn(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"4400430123456789", true},
+		{"4111111111111111", true},
+		{"5555555555554444", true},
+		{"4400430123456780", false},
+		{"4111111111111112", false},
+	}
+
+	for _, tt := range tests {
+		if got := validLuhn(tt.number); got != tt.want {
+			t.Errorf("validLuhn(%q) = %v, want %v", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestRedactPII(t *testing.T) {
+	t.Setenv("REDACT_PII", "true")
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			"iin",
+			"Мой ИИН 900101300007, прошу проверить",
+			"Мой ИИН [ИИН скрыт], прошу проверить",
+		},
+		{
+			"invalid iin stays",
+			"Номер направления 900101300008",
+			"Номер направления 900101300008",
+		},
+		{
+			"card with spaces",
+			"Списали деньги с карты 4400 4301 2345 6789 дважды",
+			"Списали деньги с карты [карта •••• 6789] дважды",
+		},
+		{
+			"card failing luhn stays",
+			"Номер полиса 4400 4301 2345 6780",
+			"Номер полиса 4400 4301 2345 6780",
+		},
+		{
+			"local phone",
+			"Перезвоните на 8 (701) 123-45-67",
+			"Перезвоните на [телефон …67]",
+		},
+		{
+			"international phone",
+			"Мой номер +7 701 123 45 67, звоните вечером",
+			"Мой номер [телефон …67], звоните вечером",
+		},
+		{
+			"mobile without country code",
+			"тел 7011234567",
+			"тел [телефон …67]",
+		},
+		{
+			"email",
+			"Пишите на ivanova.a@mail.kz",
+			"Пишите на [email скрыт]",
+		},
+		{
+			"ward number next to phone",
+			"палата 12 87011234567",
+			"палата 12 [телефон …67]",
+		},
+		{
+			"spaced phone after ward number",
+			"палата 12 8 701 123 45 67",
+			"палата 12 [телефон …67]",
+		},
+		{
+			"spaced international phone after number",
+			"кабинет 305 +7 701 123 45 67 вечером",
+			"кабинет 305 [телефон …67] вечером",
+		},
+		{
+			"spaced card after number",
+			"чек 2 4400 4301 2345 6789",
+			"чек 2 [карта •••• 6789]",
+		},
+		{
+			"spaced iin after number",
+			"палата 7 900101 300007",
+			"палата 7 [ИИН скрыт]",
+		},
+		{
+			"dates and amounts stay",
+			"Был на приеме 12.03.2026, заплатил 15000 тенге, кабинет 305",
+			"Был на приеме 12.03.2026, заплатил 15000 тенге, кабинет 305",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactPII(LangRussian, tt.text); got != tt.want {
+				t.Fatalf("RedactPII(%q) =\n%q, want\n%q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactPIIDisabled(t *testing.T) {
+	t.Setenv("REDACT_PII", "false")
+
+	text := "ИИН 900101300007, телефон 87011234567"
+	if got := RedactPII(LangRussian, text); got != text {
+		t.Fatalf("RedactPII with REDACT_PII=false = %q, want text unchanged", got)
+	}
+}
+
+func TestRedactFeedbackKeepsOriginal(t *testing.T) {
+	t.Setenv("REDACT_PII", "true")
+
+	original := &Feedback{
+		Message: "Звоните 87011234567",
+		Answers: []*FeedbackAnswer{{Label: "ИИН 900101300007"}},
+	}
+	redacted := redactFeedback(LangEnglish, original)
+
+	if redacted.Message != "Звоните [phone …67]" || redacted.Answers[0].Label != "ИИН [IIN hidden]" {
+		t.Fatalf("redacted = %q / %q", redacted.Message, redacted.Answers[0].Label)
+	}
+	if original.Message != "Звоните 87011234567" || original.Answers[0].Label != "ИИН 900101300007" {
+		t.Fatalf("original feedback was modified: %q / %q", original.Message, original.Answers[0].Label)
+	}
+}
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SLAPolicy - срок ответа на обращение в рабочих часах.
+// Пустой тип и нулевое отделение означают «любой тип» и «любое отделение».
+type SLAPolicy struct {
+	ID             int64     `json:"id"`
+	FeedbackType   string    `json:"feedback_type,omitempty"`
+	DepartmentID   int64     `json:"department_id,omitempty"`
+	DepartmentName string    `json:"department_name,omitempty"`
+	Hours          int       `json:"hours"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Holiday - нерабочий день, который не учитывается в сроке ответа
+type Holiday struct {
+	Day  time.Time `json:"day"`
+	Name string    `json:"name,omitempty"`
+}
+
+const holidayLayout = "2006-01-02"
+
+func (d *Database) ListSLAPolicies() ([]*SLAPolicy, error) {
+	query := `
+	SELECT p.id, p.feedback_type, p.department_id, COALESCE(dep.name, ''), p.hours, p.created_at
+	FROM sla_policies p
+	LEFT JOIN departments dep ON dep.id = p.department_id
+	ORDER BY p.department_id ASC, p.feedback_type ASC
+	`
+
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sla policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*SLAPolicy
+	for rows.Next() {
+		policy := &SLAPolicy{}
+		err := rows.Scan(
+			&policy.ID,
+			&policy.FeedbackType,
+			&policy.DepartmentID,
+			&policy.DepartmentName,
+			&policy.Hours,
+			&policy.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sla policy: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// SaveSLAPolicy добавляет политику или меняет срок существующей для того же типа и отделения
+func (d *Database) SaveSLAPolicy(policy *SLAPolicy) error {
+	query := `
+	INSERT INTO sla_policies (feedback_type, department_id, hours)
+	VALUES (?, ?, ?)
+	ON DUPLICATE KEY UPDATE hours = VALUES(hours)
+	`
+
+	if _, err := d.db.Exec(query, policy.FeedbackType, policy.DepartmentID, policy.Hours); err != nil {
+		return fmt.Errorf("failed to save sla policy: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) RemoveSLAPolicy(feedbackType string, departmentID int64) (bool, error) {
+	result, err := d.db.Exec(`DELETE FROM sla_policies WHERE feedback_type = ? AND department_id = ?`, feedbackType, departmentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove sla policy: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ListHolidays возвращает нерабочие дни начиная с from
+func (d *Database) ListHolidays(from time.Time) ([]*Holiday, error) {
+	rows, err := d.db.Query(`SELECT day, COALESCE(name, '') FROM holidays WHERE day >= ? ORDER BY day ASC`, from.Format(holidayLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query holidays: %w", err)
+	}
+	defer rows.Close()
+
+	var holidays []*Holiday
+	for rows.Next() {
+		holiday := &Holiday{}
+		if err := rows.Scan(&holiday.Day, &holiday.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan holiday: %w", err)
+		}
+		holidays = append(holidays, holiday)
+	}
+
+	return holidays, nil
+}
+
+func (d *Database) SaveHoliday(holiday *Holiday) error {
+	query := `
+	INSERT INTO holidays (day, name)
+	VALUES (?, ?)
+	ON DUPLICATE KEY UPDATE name = VALUES(name)
+	`
+
+	if _, err := d.db.Exec(query, holiday.Day.Format(holidayLayout), nullString(holiday.Name)); err != nil {
+		return fmt.Errorf("failed to save holiday: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) RemoveHoliday(day time.Time) (bool, error) {
+	result, err := d.db.Exec(`DELETE FROM holidays WHERE day = ?`, day.Format(holidayLayout))
+	if err != nil {
+		return false, fmt.Errorf("failed to remove holiday: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (d *Database) SetFeedbackDeadline(id int64, dueAt time.Time) error {
+	if _, err := d.db.Exec(`UPDATE feedback SET due_at = ? WHERE id = ?`, dueAt.UTC(), id); err != nil {
+		return fmt.Errorf("failed to set feedback deadline: %w", err)
+	}
+	return nil
+}
+
+// slaHours - срок ответа в рабочих часах для обращения: подходящая политика с наибольшей
+// точностью (тип и отделение, затем отделение, затем тип), иначе SLA_COMPLAINT_HOURS / SLA_REVIEW_HOURS.
+// 0 - срок не контролируется.
+func slaHours(policies []*SLAPolicy, feedbackType string, departmentID int64) int {
+	best, bestScore := -1, -1
+	for _, policy := range policies {
+		if policy.FeedbackType != "" && policy.FeedbackType != feedbackType {
+			continue
+		}
+		if policy.DepartmentID != 0 && policy.DepartmentID != departmentID {
+			continue
+		}
+
+		score := 0
+		if policy.DepartmentID != 0 {
+			score += 2
+		}
+		if policy.FeedbackType != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = policy.Hours, score
+		}
+	}
+	if best >= 0 {
+		return best
+	}
+
+	if feedbackType == "complaint" {
+		return getEnvAsInt("SLA_COMPLAINT_HOURS", 40)
+	}
+	return getEnvAsInt("SLA_REVIEW_HOURS", 0)
+}
+
+// BusinessCalendar - рабочие часы и дни для расчета сроков (BUSINESS_HOURS, BUSINESS_DAYS и таблица holidays)
+type BusinessCalendar struct {
+	loc      *time.Location
+	start    time.Duration // начало рабочего дня от полуночи
+	end      time.Duration // конец рабочего дня от полуночи
+	days     map[time.Weekday]bool
+	holidays map[string]bool
+}
+
+// NewBusinessCalendarFromEnv читает BUSINESS_HOURS=09:00-18:00 и BUSINESS_DAYS=1,2,3,4,5 (1 - понедельник)
+func NewBusinessCalendarFromEnv(holidays []*Holiday) *BusinessCalendar {
+	calendar := &BusinessCalendar{
+		loc:      nowInTimezone().Location(),
+		start:    9 * time.Hour,
+		end:      18 * time.Hour,
+		days:     make(map[time.Weekday]bool),
+		holidays: make(map[string]bool),
+	}
+
+	if from, to, ok := strings.Cut(getEnv("BUSINESS_HOURS", "09:00-18:00"), "-"); ok {
+		start, startErr := parseClock(from)
+		end, endErr := parseClock(to)
+		if startErr == nil && endErr == nil && start < end {
+			calendar.start, calendar.end = start, end
+		}
+	}
+
+	for _, day := range strings.Split(getEnv("BUSINESS_DAYS", "1,2,3,4,5"), ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(day)); err == nil && n >= 1 && n <= 7 {
+			calendar.days[time.Weekday(n%7)] = true
+		}
+	}
+
+	for _, holiday := range holidays {
+		calendar.holidays[holiday.Day.Format(holidayLayout)] = true
+	}
+	return calendar
+}
+
+// parseClock разбирает время вида "09:00" в смещение от полуночи
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (c *BusinessCalendar) isWorkingDay(day time.Time) bool {
+	return c.days[day.Weekday()] && !c.holidays[day.Format(holidayLayout)]
+}
+
+// AddBusinessHours отсчитывает hours рабочих часов от момента start
+func (c *BusinessCalendar) AddBusinessHours(start time.Time, hours int) time.Time {
+	remaining := time.Duration(hours) * time.Hour
+	if len(c.days) == 0 {
+		return start.Add(remaining)
+	}
+
+	cursor := start.In(c.loc)
+	// Ограничиваем поиск, чтобы календарь без рабочих дней не зациклил расчет
+	for i := 0; i < 3660 && remaining > 0; i++ {
+		midnight := time.Date(cursor.Year(), cursor.Month(), cursor.Day(), 0, 0, 0, 0, c.loc)
+		dayStart, dayEnd := midnight.Add(c.start), midnight.Add(c.end)
+
+		if !c.isWorkingDay(cursor) || !cursor.Before(dayEnd) {
+			cursor = midnight.AddDate(0, 0, 1)
+			continue
+		}
+		if cursor.Before(dayStart) {
+			cursor = dayStart
+		}
+
+		available := dayEnd.Sub(cursor)
+		if remaining <= available {
+			return cursor.Add(remaining)
+		}
+		remaining -= available
+		cursor = midnight.AddDate(0, 0, 1)
+	}
+	return cursor
+}
+
+// FeedbackDeadline рассчитывает срок ответа на обращение, поданное в момент from; нулевое время - без срока
+func (d *Database) FeedbackDeadline(feedback *Feedback, from time.Time) (time.Time, error) {
+	policies, err := d.ListSLAPolicies()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if slaHours(policies, feedback.Type, feedback.DepartmentID) <= 0 {
+		return time.Time{}, nil
+	}
+
+	holidays, err := d.ListHolidays(from.AddDate(0, 0, -1))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return feedbackDeadline(policies, NewBusinessCalendarFromEnv(holidays), feedback, from), nil
+}
+
+// feedbackDeadline рассчитывает срок по уже загруженным политикам и календарю; нулевое время - без срока
+func feedbackDeadline(policies []*SLAPolicy, calendar *BusinessCalendar, feedback *Feedback, from time.Time) time.Time {
+	hours := slaHours(policies, feedback.Type, feedback.DepartmentID)
+	if hours <= 0 {
+		return time.Time{}
+	}
+	return calendar.AddBusinessHours(from, hours)
+}
+
+// slaScope строит SQL-условие для обращений, срок ответа на которые контролируется (slaHours > 0).
+// Пустая строка - срок не контролируется ни для одного обращения.
+func slaScope(policies []*SLAPolicy) (string, []interface{}) {
+	var departments []int64
+	seen := make(map[int64]bool)
+	for _, policy := range policies {
+		if policy.DepartmentID != 0 && !seen[policy.DepartmentID] {
+			seen[policy.DepartmentID] = true
+			departments = append(departments, policy.DepartmentID)
+		}
+	}
+	sort.Slice(departments, func(i, j int) bool { return departments[i] < departments[j] })
+
+	var conditions []string
+	var args []interface{}
+	for _, feedbackType := range []string{"complaint", "review"} {
+		// Отделения, у которых срок контролируется не так, как по умолчанию для этого типа
+		general := slaHours(policies, feedbackType, 0) > 0
+		var exceptions []interface{}
+		for _, departmentID := range departments {
+			if (slaHours(policies, feedbackType, departmentID) > 0) != general {
+				exceptions = append(exceptions, departmentID)
+			}
+		}
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(exceptions)), ", ")
+		switch {
+		case general && len(exceptions) == 0:
+			conditions = append(conditions, "f.type = ?")
+		case general:
+			conditions = append(conditions, "(f.type = ? AND COALESCE(f.department_id, 0) NOT IN ("+placeholders+"))")
+		case len(exceptions) > 0:
+			conditions = append(conditions, "(f.type = ? AND f.department_id IN ("+placeholders+"))")
+		default:
+			continue
+		}
+		args = append(args, feedbackType)
+		args = append(args, exceptions...)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// parseSLAScope разбирает тип ("complaint", "review" или "*") и код отделения ("*" - любое)
+func (t *TelegramBot) parseSLAScope(feedbackType, departmentCode string) (string, int64, bool) {
+	switch feedbackType {
+	case "*":
+		feedbackType = ""
+	case "complaint", "review":
+	default:
+		return "", 0, false
+	}
+
+	if departmentCode == "*" {
+		return feedbackType, 0, true
+	}
+	department, err := t.database.GetDepartmentByCode(departmentCode)
+	if err != nil {
+		t.logger.Error("Failed to get department: ", err)
+	}
+	if department == nil {
+		return "", 0, false
+	}
+	return feedbackType, department.ID, true
+}
+
+// handleSLACommand обрабатывает /sla, /sla_set, /sla_remove, /holiday_add и /holiday_remove
+func (t *TelegramBot) handleSLACommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+
+	switch message.Command() {
+	case "sla":
+		t.showSLA(chatID)
+	case "sla_set":
+		if len(args) != 3 {
+			t.sendMessage(chatID, t.tr(chatID, "sla.set_usage"))
+			return
+		}
+		feedbackType, departmentID, ok := t.parseSLAScope(args[0], args[1])
+		hours, err := strconv.Atoi(args[2])
+		if !ok || err != nil || hours < 0 {
+			t.sendMessage(chatID, t.tr(chatID, "sla.set_usage"))
+			return
+		}
+
+		policy := &SLAPolicy{FeedbackType: feedbackType, DepartmentID: departmentID, Hours: hours}
+		if err := t.database.SaveSLAPolicy(policy); err != nil {
+			t.logger.Error("Failed to save sla policy: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "error.save"))
+			return
+		}
+		t.sendMessage(chatID, t.tr(chatID, "sla.saved"))
+	case "sla_remove":
+		if len(args) != 2 {
+			t.sendMessage(chatID, t.tr(chatID, "sla.remove_usage"))
+			return
+		}
+		feedbackType, departmentID, ok := t.parseSLAScope(args[0], args[1])
+		if !ok {
+			t.sendMessage(chatID, t.tr(chatID, "sla.remove_usage"))
+			return
+		}
+
+		found, err := t.database.RemoveSLAPolicy(feedbackType, departmentID)
+		if err != nil {
+			t.logger.Error("Failed to remove sla policy: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+			return
+		}
+		if !found {
+			t.sendMessage(chatID, t.tr(chatID, "sla.not_found"))
+			return
+		}
+		t.sendMessage(chatID, t.tr(chatID, "sla.removed"))
+	case "holiday_add":
+		if len(args) < 1 {
+			t.sendMessage(chatID, t.tr(chatID, "sla.holiday_usage"))
+			return
+		}
+		day, err := time.Parse(holidayLayout, args[0])
+		if err != nil {
+			t.sendMessage(chatID, t.tr(chatID, "sla.holiday_usage"))
+			return
+		}
+
+		if err := t.database.SaveHoliday(&Holiday{Day: day, Name: strings.Join(args[1:], " ")}); err != nil {
+			t.logger.Error("Failed to save holiday: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "error.save"))
+			return
+		}
+		t.sendMessage(chatID, t.tr(chatID, "sla.holiday_saved", day.Format("02.01.2006")))
+	case "holiday_remove":
+		if len(args) != 1 {
+			t.sendMessage(chatID, t.tr(chatID, "sla.holiday_usage"))
+			return
+		}
+		day, err := time.Parse(holidayLayout, args[0])
+		if err != nil {
+			t.sendMessage(chatID, t.tr(chatID, "sla.holiday_usage"))
+			return
+		}
+
+		found, err := t.database.RemoveHoliday(day)
+		if err != nil {
+			t.logger.Error("Failed to remove holiday: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+			return
+		}
+		if !found {
+			t.sendMessage(chatID, t.tr(chatID, "sla.not_found"))
+			return
+		}
+		t.sendMessage(chatID, t.tr(chatID, "sla.holiday_removed"))
+	}
+}
+
+// showSLA показывает сроки ответа, рабочее время, ближайшие праздники и цепочку эскалации
+func (t *TelegramBot) showSLA(chatID int64) {
+	policies, err := t.database.ListSLAPolicies()
+	if err != nil {
+		t.logger.Error("Failed to list sla policies: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+	holidays, err := t.database.ListHolidays(nowInTimezone())
+	if err != nil {
+		t.logger.Error("Failed to list holidays: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+
+	lang := t.lang(chatID)
+	all := Translate(lang, "inbox.all")
+	var sb strings.Builder
+	sb.WriteString(Translate(lang, "sla.title") + "\n")
+	sb.WriteString(Translate(lang, "sla.default",
+		getEnvAsInt("SLA_COMPLAINT_HOURS", 40), getEnvAsInt("SLA_REVIEW_HOURS", 0)) + "\n")
+	for _, policy := range policies {
+		feedbackType, department := all, all
+		if policy.FeedbackType != "" {
+			feedbackType = getTypeDisplayName(lang, policy.FeedbackType)
+		}
+		if policy.DepartmentID != 0 {
+			department = policy.DepartmentName
+		}
+		sb.WriteString(fmt.Sprintf("• %s / %s: %s\n", feedbackType, department, Translate(lang, "sla.hours", policy.Hours)))
+	}
+
+	sb.WriteString("\n" + Translate(lang, "sla.business_hours",
+		getEnv("BUSINESS_HOURS", "09:00-18:00"), getEnv("BUSINESS_DAYS", "1,2,3,4,5")) + "\n")
+
+	sb.WriteString("\n" + Translate(lang, "sla.holidays") + "\n")
+	if len(holidays) == 0 {
+		sb.WriteString(Translate(lang, "sla.holidays_empty") + "\n")
+	}
+	for _, holiday := range holidays {
+		sb.WriteString(fmt.Sprintf("• %s %s\n", holiday.Day.Format("02.01.2006"), holiday.Name))
+	}
+
+	sb.WriteString("\n" + Translate(lang, "sla.chain") + "\n")
+	for i, step := range escalationChain() {
+		sb.WriteString(fmt.Sprintf("%d. %s (%s)\n", i+1, Translate(lang, "sla.role."+step.Role), formatEscalationOffset(step.Offset)))
+	}
+
+	sb.WriteString("\n" + Translate(lang, "sla.hint"))
+	t.sendMessage(chatID, truncateRunes(sb.String(), 4000))
+}
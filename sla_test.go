@@ -0,0 +1,155 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAddBusinessHours(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.January, day, hour, minute, 0, 0, loc)
+	}
+
+	workdays := map[time.Weekday]bool{
+		time.Monday: true, time.Tuesday: true, time.Wednesday: true, time.Thursday: true, time.Friday: true,
+	}
+	calendar := &BusinessCalendar{
+		loc:   loc,
+		start: 9 * time.Hour,
+		end:   18 * time.Hour,
+		days:  workdays,
+		// 7 января 2026 - среда
+		holidays: map[string]bool{"2026-01-07": true},
+	}
+
+	// 5 января 2026 - понедельник
+	tests := []struct {
+		name  string
+		start time.Time
+		hours int
+		want  time.Time
+	}{
+		{"within one day", at(5, 10, 0), 4, at(5, 14, 0)},
+		{"ends exactly at close", at(5, 9, 0), 9, at(5, 18, 0)},
+		{"carries over to next day", at(5, 16, 0), 4, at(6, 11, 0)},
+		{"before opening starts at opening", at(5, 7, 30), 1, at(5, 10, 0)},
+		{"after closing starts next morning", at(5, 20, 0), 1, at(6, 10, 0)},
+		{"skips holiday", at(6, 17, 0), 2, at(8, 10, 0)},
+		{"skips weekend", at(9, 17, 0), 2, at(12, 10, 0)},
+		{"starts on weekend", at(10, 12, 0), 1, at(12, 10, 0)},
+		{"several days", at(5, 9, 0), 40, at(12, 13, 0)},
+		{"keeps minutes", at(5, 17, 45), 1, at(6, 9, 45)},
+		{"zero hours", at(5, 12, 0), 0, at(5, 12, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calendar.AddBusinessHours(tt.start, tt.hours); !got.Equal(tt.want) {
+				t.Fatalf("AddBusinessHours(%s, %d) = %s, want %s", tt.start, tt.hours, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddBusinessHoursWithoutWorkingDays(t *testing.T) {
+	start := time.Date(2026, time.January, 5, 10, 0, 0, 0, time.UTC)
+
+	empty := &BusinessCalendar{loc: time.UTC, start: 9 * time.Hour, end: 18 * time.Hour}
+	if got := empty.AddBusinessHours(start, 5); !got.Equal(start.Add(5 * time.Hour)) {
+		t.Fatalf("calendar without working days = %s, want plain %s", got, start.Add(5*time.Hour))
+	}
+
+	// Все рабочие дни - праздники: расчет должен остановиться, а не зациклиться
+	holidays := make(map[string]bool)
+	for day := start; day.Year() < 2040; day = day.AddDate(0, 0, 1) {
+		holidays[day.Format(holidayLayout)] = true
+	}
+	blocked := &BusinessCalendar{
+		loc:      time.UTC,
+		start:    9 * time.Hour,
+		end:      18 * time.Hour,
+		days:     map[time.Weekday]bool{time.Monday: true},
+		holidays: holidays,
+	}
+	if got := blocked.AddBusinessHours(start, 1); !got.After(start) {
+		t.Fatalf("blocked calendar returned %s, want a time after start", got)
+	}
+}
+
+func TestNewBusinessCalendarFromEnv(t *testing.T) {
+	tests := []struct {
+		hours, days      string
+		wantStart        time.Duration
+		wantEnd          time.Duration
+		wantSaturday     bool
+		wantSunday       bool
+		wantMondayWorked bool
+	}{
+		{"08:30-17:00", "1,2,3,4,5,6", 8*time.Hour + 30*time.Minute, 17 * time.Hour, true, false, true},
+		{"18:00-09:00", "7", 9 * time.Hour, 18 * time.Hour, false, true, false},
+		{"garbage", "1, 2, x, 9", 9 * time.Hour, 18 * time.Hour, false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hours+"/"+tt.days, func(t *testing.T) {
+			t.Setenv("BUSINESS_HOURS", tt.hours)
+			t.Setenv("BUSINESS_DAYS", tt.days)
+
+			calendar := NewBusinessCalendarFromEnv(nil)
+			if calendar.start != tt.wantStart || calendar.end != tt.wantEnd {
+				t.Fatalf("hours = %s-%s, want %s-%s", calendar.start, calendar.end, tt.wantStart, tt.wantEnd)
+			}
+			if calendar.days[time.Saturday] != tt.wantSaturday || calendar.days[time.Sunday] != tt.wantSunday ||
+				calendar.days[time.Monday] != tt.wantMondayWorked {
+				t.Fatalf("days = %v", calendar.days)
+			}
+		})
+	}
+}
+
+func TestSLAScope(t *testing.T) {
+	t.Setenv("SLA_COMPLAINT_HOURS", "40")
+	t.Setenv("SLA_REVIEW_HOURS", "0")
+
+	tests := []struct {
+		name     string
+		policies []*SLAPolicy
+		want     string
+		wantArgs []interface{}
+	}{
+		{"defaults", nil, "(f.type = ?)", []interface{}{"complaint"}},
+		{
+			"department without sla and department with review sla",
+			[]*SLAPolicy{
+				{FeedbackType: "complaint", DepartmentID: 3, Hours: 0},
+				{DepartmentID: 5, Hours: 8},
+			},
+			"((f.type = ? AND COALESCE(f.department_id, 0) NOT IN (?)) OR (f.type = ? AND f.department_id IN (?)))",
+			[]interface{}{"complaint", int64(3), "review", int64(5)},
+		},
+		{"sla disabled", []*SLAPolicy{{Hours: 0}}, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args := slaScope(tt.policies)
+			if got != tt.want || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("slaScope = %q %v, want %q %v", got, args, tt.want, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestEscalationChainSortedByOffset(t *testing.T) {
+	t.Setenv("SLA_ESCALATION_CHAIN", "chief_physician:24h,assignee:-4h,department_head:0h")
+
+	var roles []string
+	for _, step := range escalationChain() {
+		roles = append(roles, step.Role)
+	}
+	if want := []string{EscalateAssignee, EscalateDepartmentHead, EscalateChiefPhysician}; !reflect.DeepEqual(roles, want) {
+		t.Fatalf("chain roles = %v, want %v", roles, want)
+	}
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Состояния диалога с пользователем
+const (
+	StateStart                 = "start"
+	StateWaitingForType        = "waiting_for_type"
+	StateWaitingForAnonymity   = "waiting_for_anonymity"
+	StateWaitingForDepartment  = "waiting_for_department"
+	StateWaitingForMessage     = "waiting_for_message"
+	StateWaitingForContact     = "waiting_for_contact"
+	StateWaitingForAnswer      = "waiting_for_answer"
+	StateWaitingForConfirm     = "waiting_for_confirm"
+	StateWaitingForRating      = "waiting_for_rating"
+	StateWaitingForReply       = "waiting_for_reply"
+	StateWaitingForStatsPeriod = "waiting_for_stats_period"
+)
+
+type UserState struct {
+	State     string
+	Data      map[string]string
+	UpdatedAt time.Time
+}
+
+func newUserState() *UserState {
+	return &UserState{
+		State: StateStart,
+		Data:  make(map[string]string),
+	}
+}
+
+// Reset возвращает состояние в начало диалога
+func (s *UserState) Reset() {
+	s.State = StateStart
+	s.Data = make(map[string]string)
+}
+
+// StateStore хранит состояние диалога пользователя между сообщениями.
+// Get возвращает nil, если состояния нет или срок его жизни истек.
+// PurgeExpired удаляет устаревшие состояния и возвращает их, чтобы можно было убрать связанные файлы.
+type StateStore interface {
+	Get(userID int64) (*UserState, error)
+	Save(userID int64, state *UserState) error
+	Delete(userID int64) error
+	PurgeExpired() ([]*UserState, error)
+}
+
+// StateTTL определяет, сколько живет каждое состояние диалога.
+// Устаревшее состояние не должно перехватывать сообщения спустя дни.
+type StateTTL struct {
+	defaultTTL time.Duration
+	perState   map[string]time.Duration
+}
+
+func NewStateTTLFromEnv() *StateTTL {
+	ttl := &StateTTL{
+		defaultTTL: getEnvAsDuration("STATE_TTL_DEFAULT", time.Hour),
+		perState:   make(map[string]time.Duration),
+	}
+
+	defaults := map[string]time.Duration{
+		StateWaitingForType:        time.Hour,
+		StateWaitingForAnonymity:   time.Hour,
+		StateWaitingForDepartment:  time.Hour,
+		StateWaitingForMessage:     12 * time.Hour,
+		StateWaitingForContact:     12 * time.Hour,
+		StateWaitingForAnswer:      12 * time.Hour,
+		StateWaitingForConfirm:     12 * time.Hour,
+		StateWaitingForRating:      1 * time.Hour,
+		StateWaitingForReply:       12 * time.Hour,
+		StateWaitingForStatsPeriod: time.Hour,
+	}
+	for state, def := range defaults {
+		ttl.perState[state] = getEnvAsDuration("STATE_TTL_"+strings.ToUpper(state), def)
+	}
+
+	return ttl
+}
+
+func (t *StateTTL) For(state string) time.Duration {
+	if d, ok := t.perState[state]; ok {
+		return d
+	}
+	return t.defaultTTL
+}
+
+// MemoryStateStore хранит состояния в памяти процесса (для тестов и локального запуска)
+type MemoryStateStore struct {
+	mu      sync.Mutex
+	ttl     *StateTTL
+	entries map[int64]memoryStateEntry
+}
+
+type memoryStateEntry struct {
+	state     string
+	data      map[string]string
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+func NewMemoryStateStore(ttl *StateTTL) *MemoryStateStore {
+	return &MemoryStateStore{
+		ttl:     ttl,
+		entries: make(map[int64]memoryStateEntry),
+	}
+}
+
+func (m *MemoryStateStore) Get(userID int64) (*UserState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[userID]
+	if !ok {
+		return nil, nil
+	}
+	// Устаревшее состояние удаляет PurgeExpired, чтобы его вложения тоже были убраны
+	if time.Now().After(entry.expiresAt) {
+		return nil, nil
+	}
+
+	return &UserState{
+		State:     entry.state,
+		Data:      copyStateData(entry.data),
+		UpdatedAt: entry.updatedAt,
+	}, nil
+}
+
+func (m *MemoryStateStore) Save(userID int64, state *UserState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.entries[userID] = memoryStateEntry{
+		state:     state.State,
+		data:      copyStateData(state.Data),
+		updatedAt: now,
+		expiresAt: now.Add(m.ttl.For(state.State)),
+	}
+	return nil
+}
+
+func (m *MemoryStateStore) Delete(userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, userID)
+	return nil
+}
+
+func (m *MemoryStateStore) PurgeExpired() ([]*UserState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var expired []*UserState
+	for userID, entry := range m.entries {
+		if now.After(entry.expiresAt) {
+			expired = append(expired, &UserState{State: entry.state, Data: entry.data, UpdatedAt: entry.updatedAt})
+			delete(m.entries, userID)
+		}
+	}
+	return expired, nil
+}
+
+// MySQLStateStore хранит состояния в таблице user_states, чтобы они переживали перезапуски
+type MySQLStateStore struct {
+	db  *sql.DB
+	ttl *StateTTL
+}
+
+func NewMySQLStateStore(database *Database, ttl *StateTTL) *MySQLStateStore {
+	return &MySQLStateStore{
+		db:  database.db,
+		ttl: ttl,
+	}
+}
+
+func (s *MySQLStateStore) Get(userID int64) (*UserState, error) {
+	query := `
+	SELECT state, data, updated_at
+	FROM user_states
+	WHERE user_id = ? AND expires_at > ?
+	`
+
+	var rawData string
+	state := &UserState{}
+	err := s.db.QueryRow(query, userID, time.Now().UTC()).Scan(&state.State, &rawData, &state.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user state: %w", err)
+	}
+
+	state.Data = make(map[string]string)
+	if rawData != "" {
+		if err := json.Unmarshal([]byte(rawData), &state.Data); err != nil {
+			return nil, fmt.Errorf("failed to decode user state: %w", err)
+		}
+	}
+
+	return state, nil
+}
+
+func (s *MySQLStateStore) Save(userID int64, state *UserState) error {
+	rawData, err := json.Marshal(state.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode user state: %w", err)
+	}
+
+	now := time.Now().UTC()
+	query := `
+	INSERT INTO user_states (user_id, state, data, updated_at, expires_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE state = VALUES(state), data = VALUES(data),
+		updated_at = VALUES(updated_at), expires_at = VALUES(expires_at)
+	`
+
+	_, err = s.db.Exec(query, userID, state.State, string(rawData), now, now.Add(s.ttl.For(state.State)))
+	if err != nil {
+		return fmt.Errorf("failed to save user state: %w", err)
+	}
+	return nil
+}
+
+func (s *MySQLStateStore) Delete(userID int64) error {
+	if _, err := s.db.Exec(`DELETE FROM user_states WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete user state: %w", err)
+	}
+	return nil
+}
+
+func (s *MySQLStateStore) PurgeExpired() ([]*UserState, error) {
+	// Строки блокируются до удаления: пользователь, продолживший диалог между выборкой
+	// и удалением, не должен потерять состояние и вложения
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT user_id, state, data, updated_at FROM user_states WHERE expires_at <= ? FOR UPDATE`, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired user states: %w", err)
+	}
+	defer rows.Close()
+
+	var expired []*UserState
+	var userIDs []interface{}
+	for rows.Next() {
+		var userID int64
+		var rawData string
+		state := &UserState{Data: make(map[string]string)}
+		if err := rows.Scan(&userID, &state.State, &rawData, &state.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan expired user state: %w", err)
+		}
+		if rawData != "" {
+			// Поврежденные данные не мешают удалить остальные состояния
+			json.Unmarshal([]byte(rawData), &state.Data)
+		}
+		expired = append(expired, state)
+		userIDs = append(userIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read expired user states: %w", err)
+	}
+	rows.Close()
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `DELETE FROM user_states WHERE user_id IN (?` + strings.Repeat(", ?", len(userIDs)-1) + `)`
+	if _, err := tx.Exec(query, userIDs...); err != nil {
+		return nil, fmt.Errorf("failed to purge expired user states: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit user states purge: %w", err)
+	}
+	return expired, nil
+}
+
+// NewStateStoreFromEnv выбирает хранилище состояний по STATE_STORE (mysql или memory)
+func NewStateStoreFromEnv(database *Database) StateStore {
+	ttl := NewStateTTLFromEnv()
+	if getEnv("STATE_STORE", "mysql") == "memory" {
+		return NewMemoryStateStore(ttl)
+	}
+	return NewMySQLStateStore(database, ttl)
+}
+
+func copyStateData(data map[string]string) map[string]string {
+	result := make(map[string]string, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+	return result
+}
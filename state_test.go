@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStateStoreTTL(t *testing.T) {
+	ttl := &StateTTL{
+		defaultTTL: time.Hour,
+		perState: map[string]time.Duration{
+			StateWaitingForType:    time.Millisecond,
+			StateWaitingForMessage: time.Hour,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		state   string
+		wantNil bool
+	}{
+		{"short ttl expires", StateWaitingForType, true},
+		{"long ttl survives", StateWaitingForMessage, false},
+		{"unknown state uses default ttl", "custom_state", false},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStateStore(ttl)
+			userID := int64(i + 1)
+			state := newUserState()
+			state.State = tt.state
+			state.Data["message"] = "текст"
+			if err := store.Save(userID, state); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+
+			time.Sleep(5 * time.Millisecond)
+
+			got, err := store.Get(userID)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if (got == nil) != tt.wantNil {
+				t.Fatalf("Get returned %v, want nil = %v", got, tt.wantNil)
+			}
+			if got != nil && (got.State != tt.state || got.Data["message"] != "текст") {
+				t.Fatalf("Get returned %+v, want state %q with saved data", got, tt.state)
+			}
+
+			expired, err := store.PurgeExpired()
+			if err != nil {
+				t.Fatalf("PurgeExpired: %v", err)
+			}
+			if (len(expired) == 1) != tt.wantNil {
+				t.Fatalf("PurgeExpired returned %d states, want expired = %v", len(expired), tt.wantNil)
+			}
+			if tt.wantNil && expired[0].Data["message"] != "текст" {
+				t.Fatalf("expired state lost its data: %+v", expired[0])
+			}
+		})
+	}
+}
+
+func TestMemoryStateStoreCopiesData(t *testing.T) {
+	store := NewMemoryStateStore(&StateTTL{defaultTTL: time.Hour})
+	state := newUserState()
+	state.Data["step"] = "message"
+	if err := store.Save(1, state); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	state.Data["step"] = "changed"
+	got, _ := store.Get(1)
+	got.Data["step"] = "changed again"
+
+	again, _ := store.Get(1)
+	if again.Data["step"] != "message" {
+		t.Fatalf("stored data was modified through a returned map: %q", again.Data["step"])
+	}
+}
@@ -0,0 +1,562 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Периоды статистики для кнопок /stats
+const (
+	statsToday  = "today"
+	statsWeek   = "7d"
+	statsMonth  = "30d"
+	statsAll    = "all"
+	statsCustom = "custom"
+)
+
+// PeriodStats - статистика обращений, созданных за период [From, To)
+type PeriodStats struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	ByType       map[string]int     `json:"by_type"`
+	ByStatus     map[string]int     `json:"by_status"`
+	ByDepartment []*DepartmentStats `json:"by_department"`
+	ByHour       [24]int            `json:"by_hour"` // по часу создания в часовом поясе TIMEZONE
+	ByDay        []*DailyStats      `json:"by_day"`
+
+	// Медианы считаются по обращениям периода, на которые уже ответили или которые уже решены
+	MedianFirstResponse time.Duration `json:"median_first_response"`
+	FirstResponseCount  int           `json:"first_response_count"`
+	MedianResolution    time.Duration `json:"median_resolution"`
+	ResolutionCount     int           `json:"resolution_count"`
+}
+
+// DailyStats - число жалоб и отзывов за день (дата в часовом поясе TIMEZONE)
+type DailyStats struct {
+	Day        string `json:"day"` // 2006-01-02
+	Complaints int    `json:"complaints"`
+	Reviews    int    `json:"reviews"`
+}
+
+func (s *PeriodStats) Total() int {
+	total := 0
+	for _, count := range s.ByType {
+		total += count
+	}
+	return total
+}
+
+// statsPeriod - выбранный период и такой же период перед ним для сравнения
+type statsPeriod struct {
+	Name string
+	From time.Time
+	To   time.Time
+	Days int
+}
+
+func (p statsPeriod) previous() (time.Time, time.Time) {
+	return p.From.AddDate(0, 0, -p.Days), p.To.AddDate(0, 0, -p.Days)
+}
+
+// newStatsPeriod - период "сегодня", "7 дней" или "30 дней", заканчивающийся сейчас
+func newStatsPeriod(name string) (statsPeriod, bool) {
+	now := nowInTimezone()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	days := map[string]int{statsToday: 1, statsWeek: 7, statsMonth: 30}[name]
+	if days == 0 {
+		return statsPeriod{}, false
+	}
+	return statsPeriod{Name: name, From: today.AddDate(0, 0, 1-days), To: now, Days: days}, true
+}
+
+// maxStatsPeriodDays - самый длинный период по датам: график строится по дням
+const maxStatsPeriodDays = 366
+
+// customStatsPeriod - период по датам вида 2026-01-31 или 31.01.2026, обе даты включительно.
+// Период длиннее maxStatsPeriodDays отклоняется.
+func customStatsPeriod(fromValue, toValue string) (statsPeriod, bool) {
+	fromDate, ok := parseInboxDate(fromValue)
+	if !ok {
+		return statsPeriod{}, false
+	}
+	toDate, ok := parseInboxDate(toValue)
+	if !ok {
+		return statsPeriod{}, false
+	}
+
+	loc := nowInTimezone().Location()
+	from, _ := time.ParseInLocation(inboxDateFormat, fromDate, loc)
+	to, _ := time.ParseInLocation(inboxDateFormat, toDate, loc)
+	if to.Before(from) {
+		from, to = to, from
+	}
+	to = to.AddDate(0, 0, 1)
+
+	days := int(to.Sub(from).Hours()/24 + 0.5)
+	if days > maxStatsPeriodDays {
+		return statsPeriod{}, false
+	}
+	return statsPeriod{Name: statsCustom, From: from, To: to, Days: days}, true
+}
+
+// statsScope - условие выборки обращений периода с ограничением по отделению и ответственному
+func statsScope(from, to time.Time, departmentID, assigneeID int64) (string, []interface{}) {
+	where := `f.created_at >= ? AND f.created_at < ? AND (? = 0 OR f.department_id = ?) AND (? = 0 OR f.assignee_id = ?)`
+	return where, []interface{}{from.UTC(), to.UTC(), departmentID, departmentID, assigneeID, assigneeID}
+}
+
+// GetPeriodStats собирает статистику обращений за период: по типам, статусам, отделениям,
+// часам суток и медианное время до первого ответа и до решения
+func (d *Database) GetPeriodStats(from, to time.Time, departmentID, assigneeID int64) (*PeriodStats, error) {
+	stats := &PeriodStats{
+		From:     from,
+		To:       to,
+		ByType:   make(map[string]int),
+		ByStatus: make(map[string]int),
+	}
+	where, args := statsScope(from, to, departmentID, assigneeID)
+
+	rows, err := d.db.Query(`SELECT f.type, f.status, COUNT(*) FROM feedback f WHERE `+where+` GROUP BY f.type, f.status`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get period stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var feedbackType, status string
+		var count int
+		if err := rows.Scan(&feedbackType, &status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan period stats: %w", err)
+		}
+		stats.ByType[feedbackType] += count
+		stats.ByStatus[status] += count
+	}
+
+	departmentQuery := `
+	SELECT
+		COALESCE(f.department_id, 0),
+		COALESCE(dep.name, ''),
+		SUM(f.type = 'complaint'),
+		SUM(f.type = 'review')
+	FROM feedback f
+	LEFT JOIN departments dep ON dep.id = f.department_id
+	WHERE ` + where + `
+	GROUP BY f.department_id, dep.name
+	ORDER BY COUNT(*) DESC
+	`
+
+	depRows, err := d.db.Query(departmentQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get period department stats: %w", err)
+	}
+	defer depRows.Close()
+
+	for depRows.Next() {
+		departmentStats := &DepartmentStats{}
+		err := depRows.Scan(
+			&departmentStats.DepartmentID,
+			&departmentStats.Name,
+			&departmentStats.Complaints,
+			&departmentStats.Reviews,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan period department stats: %w", err)
+		}
+		stats.ByDepartment = append(stats.ByDepartment, departmentStats)
+	}
+
+	// Время в базе хранится в UTC, час суток считаем в часовом поясе больницы
+	_, offset := nowInTimezone().Zone()
+	hourRows, err := d.db.Query(`SELECT HOUR(DATE_ADD(f.created_at, INTERVAL ? SECOND)), COUNT(*) FROM feedback f WHERE `+where+` GROUP BY 1`,
+		append([]interface{}{offset}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hourly stats: %w", err)
+	}
+	defer hourRows.Close()
+
+	for hourRows.Next() {
+		var hour, count int
+		if err := hourRows.Scan(&hour, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan hourly stats: %w", err)
+		}
+		if hour >= 0 && hour < len(stats.ByHour) {
+			stats.ByHour[hour] = count
+		}
+	}
+
+	dayRows, err := d.db.Query(`
+	SELECT DATE_FORMAT(DATE_ADD(f.created_at, INTERVAL ? SECOND), '%Y-%m-%d') AS day,
+		SUM(f.type = 'complaint'), SUM(f.type = 'review')
+	FROM feedback f
+	WHERE `+where+`
+	GROUP BY day
+	ORDER BY day ASC
+	`, append([]interface{}{offset}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily stats: %w", err)
+	}
+	defer dayRows.Close()
+
+	for dayRows.Next() {
+		day := &DailyStats{}
+		if err := dayRows.Scan(&day.Day, &day.Complaints, &day.Reviews); err != nil {
+			return nil, fmt.Errorf("failed to scan daily stats: %w", err)
+		}
+		stats.ByDay = append(stats.ByDay, day)
+	}
+
+	// Первый ответ - первое сообщение сотрудника автору или первая смена статуса, что раньше
+	firstResponseQuery := `
+	SELECT TIMESTAMPDIFF(SECOND, f.created_at, LEAST(COALESCE(m.first_at, h.first_at), COALESCE(h.first_at, m.first_at)))
+	FROM feedback f
+	LEFT JOIN (
+		SELECT feedback_id, MIN(created_at) AS first_at FROM feedback_messages WHERE direction = 'staff' GROUP BY feedback_id
+	) m ON m.feedback_id = f.id
+	LEFT JOIN (
+		SELECT feedback_id, MIN(created_at) AS first_at FROM feedback_status_history GROUP BY feedback_id
+	) h ON h.feedback_id = f.id
+	WHERE ` + where + ` AND (m.first_at IS NOT NULL OR h.first_at IS NOT NULL)
+	`
+	stats.MedianFirstResponse, stats.FirstResponseCount, err = d.medianSeconds(firstResponseQuery, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get first response times: %w", err)
+	}
+
+	// Решение - первый перевод обращения в resolved или rejected
+	resolutionQuery := `
+	SELECT TIMESTAMPDIFF(SECOND, f.created_at, r.resolved_at)
+	FROM feedback f
+	JOIN (
+		SELECT feedback_id, MIN(created_at) AS resolved_at FROM feedback_status_history
+		WHERE to_status IN ('resolved', 'rejected') GROUP BY feedback_id
+	) r ON r.feedback_id = f.id
+	WHERE ` + where
+	stats.MedianResolution, stats.ResolutionCount, err = d.medianSeconds(resolutionQuery, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resolution times: %w", err)
+	}
+
+	return stats, nil
+}
+
+// medianSeconds выполняет запрос, возвращающий длительности в секундах, и считает их медиану
+func (d *Database) medianSeconds(query string, args []interface{}) (time.Duration, int, error) {
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+
+	var values []int64
+	for rows.Next() {
+		var seconds int64
+		if err := rows.Scan(&seconds); err != nil {
+			return 0, 0, err
+		}
+		if seconds < 0 {
+			seconds = 0
+		}
+		values = append(values, seconds)
+	}
+	return median(values), len(values), nil
+}
+
+// median - медиана длительностей в секундах; для четного числа значений - среднее двух средних
+func median(values []int64) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+	mid := len(values) / 2
+	seconds := values[mid]
+	if len(values)%2 == 0 {
+		seconds = (values[mid-1] + values[mid]) / 2
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// formatStatsDuration - длительность в днях и часах, часах и минутах или минутах
+func formatStatsDuration(lang string, d time.Duration) string {
+	minutes := int(d.Minutes())
+	switch {
+	case minutes >= 24*60:
+		return Translate(lang, "stats.duration.days", minutes/(24*60), minutes%(24*60)/60)
+	case minutes >= 60:
+		return Translate(lang, "stats.duration.hours", minutes/60, minutes%60)
+	default:
+		return Translate(lang, "stats.duration.minutes", minutes)
+	}
+}
+
+// formatStatsDelta - изменение к предыдущему периоду: "▲ +5 / +20%", "▼ -3", "="
+func formatStatsDelta(current, previous int) string {
+	delta := current - previous
+	if delta == 0 {
+		return "="
+	}
+
+	arrow := "▲ +"
+	if delta < 0 {
+		arrow = "▼ "
+	}
+	text := arrow + strconv.Itoa(delta)
+	if previous > 0 {
+		text += fmt.Sprintf(" / %+d%%", delta*100/previous)
+	}
+	return text
+}
+
+// formatStatsMedian - медиана периода и значение предыдущего периода
+func formatStatsMedian(lang string, current time.Duration, currentCount int, previous time.Duration, previousCount int) string {
+	if currentCount == 0 {
+		return Translate(lang, "stats.no_data")
+	}
+	text := formatStatsDuration(lang, current)
+	if previousCount > 0 {
+		text += " " + Translate(lang, "stats.was", formatStatsDuration(lang, previous))
+	}
+	return text
+}
+
+// statsHourBars - гистограмма по часам суток; часы без обращений пропускаются
+func statsHourBars(byHour [24]int) string {
+	peak := 0
+	for _, count := range byHour {
+		if count > peak {
+			peak = count
+		}
+	}
+	if peak == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for hour, count := range byHour {
+		if count == 0 {
+			continue
+		}
+		width := (count*10 + peak - 1) / peak
+		sb.WriteString(fmt.Sprintf("\n%02d:00 %s %d", hour, strings.Repeat("▇", width), count))
+	}
+	return sb.String()
+}
+
+// statsKeyboard - кнопки выбора периода (callback stats:<период>:<ответственный>), для периода с датами - и кнопка графиков
+func (t *TelegramBot) statsKeyboard(chatID int64, selected string, assigneeID int64, charts *statsPeriod) tgbotapi.InlineKeyboardMarkup {
+	button := func(period string) tgbotapi.InlineKeyboardButton {
+		label := t.tr(chatID, "stats.period."+period)
+		if period == selected {
+			label = "✅ " + label
+		}
+		return tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("stats:%s:%d", period, assigneeID))
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(button(statsToday), button(statsWeek), button(statsMonth)),
+		tgbotapi.NewInlineKeyboardRow(button(statsCustom), button(statsAll)),
+	}
+	if charts != nil {
+		// stats:g:<с>:<по>:<ответственный> - даты включительно, укладывается в 64 байта callback data
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "stats.charts"),
+			fmt.Sprintf("stats:g:%s:%s:%d", charts.From.Format(inboxDateFormat), charts.To.Add(-time.Second).Format(inboxDateFormat), assigneeID))))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.main_menu"), "back_to_menu"),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// showPeriodStats показывает статистику за период в сравнении с таким же периодом перед ним;
+// заведующему отделением - только по его отделению
+func (t *TelegramBot) showPeriodStats(chatID int64, messageID int, admin *Admin, period statsPeriod, assigneeID int64) {
+	scope := admin.ScopeDepartmentID()
+	current, err := t.database.GetPeriodStats(period.From, period.To, scope, assigneeID)
+	if err != nil {
+		t.logger.Error("Failed to get period stats: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "stats.error"))
+		return
+	}
+	previousFrom, previousTo := period.previous()
+	previous, err := t.database.GetPeriodStats(previousFrom, previousTo, scope, assigneeID)
+	if err != nil {
+		t.logger.Error("Failed to get period stats: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "stats.error"))
+		return
+	}
+
+	lang := t.lang(chatID)
+	var sb strings.Builder
+	sb.WriteString(Translate(lang, "stats.period.title", Translate(lang, "stats.period."+period.Name),
+		period.From.Format("02.01.2006"), period.To.Add(-time.Second).Format("02.01.2006")))
+	if scope != 0 {
+		if department, err := t.database.GetDepartment(scope); err == nil && department != nil {
+			sb.WriteString("\n" + Translate(lang, "stats.scope", department.Name))
+		}
+	}
+	if assigneeID != 0 {
+		sb.WriteString("\n" + Translate(lang, "stats.assignee", t.staffName(assigneeID)))
+	}
+
+	sb.WriteString("\n\n" + Translate(lang, "stats.period.total", current.Total(), formatStatsDelta(current.Total(), previous.Total()), previous.Total()))
+	sb.WriteString("\n" + Translate(lang, "stats.period.complaints", current.ByType["complaint"],
+		formatStatsDelta(current.ByType["complaint"], previous.ByType["complaint"])))
+	sb.WriteString("\n" + Translate(lang, "stats.period.reviews", current.ByType["review"],
+		formatStatsDelta(current.ByType["review"], previous.ByType["review"])))
+
+	sb.WriteString("\n\n" + Translate(lang, "stats.median_first_response", formatStatsMedian(lang,
+		current.MedianFirstResponse, current.FirstResponseCount, previous.MedianFirstResponse, previous.FirstResponseCount)))
+	sb.WriteString("\n" + Translate(lang, "stats.median_resolution", formatStatsMedian(lang,
+		current.MedianResolution, current.ResolutionCount, previous.MedianResolution, previous.ResolutionCount)))
+
+	if current.Total() > 0 {
+		sb.WriteString("\n\n" + Translate(lang, "stats.by_status"))
+		for _, status := range append(append([]string{}, openStatuses...), StatusResolved, StatusRejected) {
+			if count := current.ByStatus[status]; count > 0 {
+				sb.WriteString(fmt.Sprintf("\n• %s: %d", statusDisplayName(lang, status), count))
+			}
+		}
+
+		sb.WriteString("\n\n" + Translate(lang, "stats.by_department"))
+		for _, department := range current.ByDepartment {
+			name := department.Name
+			if name == "" {
+				name = Translate(lang, "stats.not_specified")
+			}
+			sb.WriteString(fmt.Sprintf("\n• %s: %d (📝 %d / ⭐ %d)", name, department.Total(), department.Complaints, department.Reviews))
+		}
+
+		sb.WriteString("\n\n" + Translate(lang, "stats.by_hour") + statsHourBars(current.ByHour))
+	}
+
+	t.sendOrEdit(chatID, messageID, truncateRunes(sb.String(), 4000), t.statsKeyboard(chatID, period.Name, assigneeID, &period))
+}
+
+// handleStatsCallback обрабатывает кнопки периода stats:<период>:<ответственный>
+// и кнопку графиков stats:g:<с>:<по>:<ответственный>
+func (t *TelegramBot) handleStatsCallback(callback *tgbotapi.CallbackQuery, state *UserState, payload string) {
+	chatID := callback.Message.Chat.ID
+	admin := t.authorize(callback.From.ID, PermViewStats)
+	if admin == nil {
+		t.bot.Request(tgbotapi.NewCallback(callback.ID, t.tr(chatID, "access.stats_denied")))
+		return
+	}
+	t.bot.Request(tgbotapi.NewCallback(callback.ID, ""))
+
+	parts := strings.Split(payload, ":")
+	assigneeID, _ := strconv.ParseInt(parts[len(parts)-1], 10, 64)
+
+	switch name := parts[0]; name {
+	case "g":
+		if len(parts) != 4 {
+			return
+		}
+		period, ok := customStatsPeriod(parts[1], parts[2])
+		if !ok {
+			return
+		}
+		t.sendStatsCharts(chatID, admin, period, assigneeID)
+	case statsAll:
+		t.handleStats(chatID, callback.Message.MessageID, admin, assigneeID)
+	case statsCustom:
+		state.Reset()
+		state.State = StateWaitingForStatsPeriod
+		state.Data["stats_assignee"] = strconv.FormatInt(assigneeID, 10)
+
+		msg := tgbotapi.NewMessage(chatID, t.tr(chatID, "stats.period.ask"))
+		msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+		t.bot.Send(msg)
+	default:
+		period, ok := newStatsPeriod(name)
+		if !ok {
+			return
+		}
+		t.showPeriodStats(chatID, callback.Message.MessageID, admin, period, assigneeID)
+	}
+}
+
+// sendStatsCharts отправляет PNG-графики периода: динамику по дням, статусы и отделения
+func (t *TelegramBot) sendStatsCharts(chatID int64, admin *Admin, period statsPeriod, assigneeID int64) {
+	scope := admin.ScopeDepartmentID()
+	stats, err := t.database.GetPeriodStats(period.From, period.To, scope, assigneeID)
+	if err != nil {
+		t.logger.Error("Failed to get period stats: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "stats.error"))
+		return
+	}
+
+	var subtitle string
+	if scope != 0 {
+		if department, err := t.database.GetDepartment(scope); err == nil && department != nil {
+			subtitle = department.Name
+		}
+	}
+	if assigneeID != 0 {
+		if subtitle != "" {
+			subtitle += " · "
+		}
+		subtitle += t.staffName(assigneeID)
+	}
+
+	lang := t.lang(chatID)
+	for _, kind := range chartKinds {
+		var buf bytes.Buffer
+		if err := t.charts.Render(&buf, kind, lang, stats, subtitle); err != nil {
+			t.logger.Error("Failed to render chart: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "stats.charts_error"))
+			return
+		}
+
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: kind + ".png", Bytes: buf.Bytes()})
+		photo.Caption = Translate(lang, "chart."+kind)
+		if _, err := t.bot.Send(photo); err != nil {
+			t.logger.Error("Failed to send chart: ", err)
+			return
+		}
+	}
+}
+
+// handleStatsPeriodInput принимает произвольный период "с по" после нажатия кнопки «Период»
+func (t *TelegramBot) handleStatsPeriodInput(message *tgbotapi.Message, state *UserState) {
+	chatID := message.Chat.ID
+	admin := t.authorize(message.From.ID, PermViewStats)
+	if admin == nil {
+		state.Reset()
+		t.sendMessage(chatID, t.tr(chatID, "access.stats_denied"))
+		return
+	}
+
+	// Понимаем "2026-01-01 2026-01-31", "01.01.2026 - 31.01.2026" и одну дату
+	var dates []string
+	for _, field := range strings.Fields(strings.NewReplacer("—", " ", "–", " ").Replace(message.Text)) {
+		if field != "-" {
+			dates = append(dates, field)
+		}
+	}
+	if len(dates) == 1 {
+		dates = append(dates, dates[0])
+	}
+	var period statsPeriod
+	ok := len(dates) == 2
+	if ok {
+		period, ok = customStatsPeriod(dates[0], dates[1])
+	}
+	if !ok {
+		msg := tgbotapi.NewMessage(chatID, t.tr(chatID, "stats.period.invalid", maxStatsPeriodDays))
+		msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+		t.bot.Send(msg)
+		return
+	}
+
+	assigneeID, _ := strconv.ParseInt(state.Data["stats_assignee"], 10, 64)
+	state.Reset()
+	t.showPeriodStats(chatID, 0, admin, period, assigneeID)
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Статусы обращения
+const (
+	StatusNew             = "new"
+	StatusAcknowledged    = "acknowledged"
+	StatusInProgress      = "in_progress"
+	StatusAwaitingPatient = "awaiting_patient"
+	StatusResolved        = "resolved"
+	StatusRejected        = "rejected"
+	StatusReopened        = "reopened"
+)
+
+// Откуда изменен статус
+const (
+	StatusSourceBot  = "bot"
+	StatusSourceHTTP = "http"
+	StatusSourceCLI  = "cli"
+)
+
+// ErrInvalidTransition - переход между статусами не предусмотрен жизненным циклом обращения
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// statusTransitions - допустимые переходы статусов. Это единственное место, где они заданы:
+// бот, HTTP API и командная строка меняют статус только через ChangeFeedbackStatus.
+var statusTransitions = map[string][]string{
+	StatusNew:             {StatusAcknowledged, StatusInProgress, StatusResolved, StatusRejected},
+	StatusAcknowledged:    {StatusInProgress, StatusAwaitingPatient, StatusResolved, StatusRejected},
+	StatusInProgress:      {StatusAwaitingPatient, StatusResolved, StatusRejected},
+	StatusAwaitingPatient: {StatusInProgress, StatusResolved, StatusRejected},
+	StatusResolved:        {StatusReopened},
+	StatusRejected:        {StatusReopened},
+	StatusReopened:        {StatusAcknowledged, StatusInProgress, StatusAwaitingPatient, StatusResolved, StatusRejected},
+}
+
+// openStatuses - статусы обращений, по которым еще ожидается работа
+var openStatuses = []string{StatusNew, StatusAcknowledged, StatusInProgress, StatusAwaitingPatient, StatusReopened}
+
+func isSupportedStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+func canTransition(from, to string) bool {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange - запись истории статусов обращения
+type StatusChange struct {
+	ID         int64     `json:"id"`
+	FeedbackID int64     `json:"feedback_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ActorID    int64     `json:"actor_id,omitempty"`
+	ActorName  string    `json:"actor_name,omitempty"`
+	Source     string    `json:"source"` // bot, http или cli
+	Comment    string    `json:"comment,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ChangeFeedbackStatus проверяет переход и меняет статус, записывая его в историю.
+// Текущий статус записывается в change.FromStatus.
+func (d *Database) ChangeFeedbackStatus(change *StatusChange) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current string
+	feedback := &Feedback{ID: change.FeedbackID}
+	var departmentID sql.NullInt64
+	err = tx.QueryRow(`SELECT status, type, department_id FROM feedback WHERE id = ? FOR UPDATE`, change.FeedbackID).
+		Scan(&current, &feedback.Type, &departmentID)
+	if err != nil {
+		return fmt.Errorf("failed to get feedback status: %w", err)
+	}
+	if !canTransition(current, change.ToStatus) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, current, change.ToStatus)
+	}
+
+	if _, err := tx.Exec(`UPDATE feedback SET status = ? WHERE id = ?`, change.ToStatus, change.FeedbackID); err != nil {
+		return fmt.Errorf("failed to update feedback status: %w", err)
+	}
+
+	// Переоткрытое обращение получает новый срок ответа, и цепочка эскалации начинается заново
+	if change.ToStatus == StatusReopened {
+		feedback.DepartmentID = departmentID.Int64
+		dueAt, err := d.FeedbackDeadline(feedback, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to calculate feedback deadline: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE feedback SET due_at = ? WHERE id = ?`, nullTime(dueAt), change.FeedbackID); err != nil {
+			return fmt.Errorf("failed to set feedback deadline: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM feedback_escalations WHERE feedback_id = ?`, change.FeedbackID); err != nil {
+			return fmt.Errorf("failed to reset escalations: %w", err)
+		}
+	}
+
+	query := `
+	INSERT INTO feedback_status_history (feedback_id, from_status, to_status, actor_id, actor_name, source, comment)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := tx.Exec(query,
+		change.FeedbackID,
+		current,
+		change.ToStatus,
+		nullInt64(change.ActorID),
+		nullString(change.ActorName),
+		change.Source,
+		nullString(change.Comment),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save status history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit status change: %w", err)
+	}
+
+	change.FromStatus = current
+	change.ID, _ = result.LastInsertId()
+	change.CreatedAt = time.Now()
+	return nil
+}
+
+func (d *Database) GetStatusHistory(feedbackID int64) ([]*StatusChange, error) {
+	query := `
+	SELECT id, feedback_id, from_status, to_status, COALESCE(actor_id, 0), COALESCE(actor_name, ''),
+		source, COALESCE(comment, ''), created_at
+	FROM feedback_status_history
+	WHERE feedback_id = ?
+	ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := d.db.Query(query, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []*StatusChange
+	for rows.Next() {
+		change := &StatusChange{}
+		err := rows.Scan(
+			&change.ID,
+			&change.FeedbackID,
+			&change.FromStatus,
+			&change.ToStatus,
+			&change.ActorID,
+			&change.ActorName,
+			&change.Source,
+			&change.Comment,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan status change: %w", err)
+		}
+		history = append(history, change)
+	}
+
+	return history, nil
+}
+
+// FindFeedback ищет обращение по коду ("K7QM-3XPA") или по номеру ("#123")
+func (d *Database) FindFeedback(ref string) (*Feedback, error) {
+	ref = strings.ToUpper(strings.TrimSpace(ref))
+	if id, err := strconv.ParseInt(strings.TrimPrefix(ref, "#"), 10, 64); err == nil {
+		return d.GetFeedback(id)
+	}
+	return d.GetFeedbackByTicket(ref)
+}
+
+// handleStatusCommand обрабатывает /status <номер обращения> <статус> [комментарий]
+func (t *TelegramBot) handleStatusCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+	if len(args) < 2 || !isSupportedStatus(strings.ToLower(args[1])) {
+		t.sendMessage(chatID, t.tr(chatID, "status.usage"))
+		return
+	}
+
+	feedback, err := t.findFeedbackByTicket(args[0])
+	if err != nil {
+		t.logger.Error("Failed to find feedback: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+	if feedback == nil || !t.canAccessFeedback(message.From.ID, PermHandleFeedback, feedback) {
+		t.sendMessage(chatID, t.tr(chatID, "thread.not_found"))
+		return
+	}
+
+	previous := feedback.Status
+	status := strings.ToLower(args[1])
+	comment := strings.Join(args[2:], " ")
+	if err := t.changeFeedbackStatus(feedback, status, comment, message.From, status == StatusInProgress); err != nil {
+		if errors.Is(err, ErrInvalidTransition) {
+			t.sendMessage(chatID, t.tr(chatID, "status.transition_denied"))
+			return
+		}
+		t.logger.Error("Failed to change feedback status: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+
+	lang := t.lang(chatID)
+	t.sendMessage(chatID, Translate(lang, "status.changed", ticketLabel(feedback),
+		statusDisplayName(lang, previous), statusDisplayName(lang, status)))
+}
+
+// statusHistoryText описывает историю статусов обращения для сотрудников
+func statusHistoryText(lang string, history []*StatusChange) string {
+	var sb strings.Builder
+	sb.WriteString(Translate(lang, "status.history"))
+	if len(history) == 0 {
+		sb.WriteString("\n" + Translate(lang, "status.history_empty"))
+	}
+	for _, change := range history {
+		actor := change.ActorName
+		if actor == "" {
+			actor = change.Source
+		}
+		sb.WriteString(fmt.Sprintf("\n%s · %s → %s · %s",
+			inTimezone(change.CreatedAt).Format("02.01.2006 15:04"),
+			statusDisplayName(lang, change.FromStatus),
+			statusDisplayName(lang, change.ToStatus),
+			actor,
+		))
+		if change.Comment != "" {
+			sb.WriteString("\n   💬 " + change.Comment)
+		}
+	}
+	return sb.String()
+}
+
+// authorizeAPI проверяет заголовок Authorization: Bearer <API_TOKEN>.
+// Без API_TOKEN в окружении HTTP API изменения данных выключен.
+func (a *App) authorizeAPI(w http.ResponseWriter, r *http.Request) bool {
+	token := getEnv("API_TOKEN", "")
+	if token == "" {
+		http.Error(w, "api disabled", http.StatusNotFound)
+		return false
+	}
+
+	provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// statusRequest - тело запроса POST /feedback/status
+type statusRequest struct {
+	Ticket  string `json:"ticket"` // код обращения или номер "#123"
+	Status  string `json:"status"`
+	Comment string `json:"comment"`
+	Actor   string `json:"actor"`
+}
+
+// statusHandler меняет статус обращения: POST /feedback/status
+// {"ticket": "K7QM-3XPA", "status": "resolved", "comment": "...", "actor": "Иванова"}
+func (a *App) statusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !a.authorizeAPI(w, r) {
+		return
+	}
+
+	var req statusRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.Ticket == "" || !isSupportedStatus(req.Status) {
+		http.Error(w, "ticket and a valid status are required", http.StatusBadRequest)
+		return
+	}
+
+	feedback, err := a.database.FindFeedback(req.Ticket)
+	if err != nil {
+		a.logger.Error("Failed to find feedback: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if feedback == nil {
+		http.Error(w, "feedback not found", http.StatusNotFound)
+		return
+	}
+
+	change := &StatusChange{
+		FeedbackID: feedback.ID,
+		ToStatus:   req.Status,
+		ActorName:  req.Actor,
+		Source:     StatusSourceHTTP,
+		Comment:    req.Comment,
+	}
+	if err := a.database.ChangeFeedbackStatus(change); err != nil {
+		if errors.Is(err, ErrInvalidTransition) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		a.logger.Error("Failed to change feedback status: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	// Карточка в чате сотрудников должна показывать новый статус
+	feedback.Status = change.ToStatus
+	a.bot.loadFeedbackDetails(feedback)
+	a.bot.refreshFeedbackCard(feedback)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(change)
+}
+
+// statusHistoryHandler отдает историю статусов обращения: GET /feedback/history?ticket=K7QM-3XPA
+func (a *App) statusHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if !a.authorizeAPI(w, r) {
+		return
+	}
+
+	feedback, err := a.database.FindFeedback(r.URL.Query().Get("ticket"))
+	if err != nil {
+		a.logger.Error("Failed to find feedback: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if feedback == nil {
+		http.Error(w, "feedback not found", http.StatusNotFound)
+		return
+	}
+
+	history, err := a.database.GetStatusHistory(feedback.ID)
+	if err != nil {
+		a.logger.Error("Failed to get status history: ", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if history == nil {
+		history = []*StatusChange{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ticket":  ticketLabel(feedback),
+		"status":  feedback.Status,
+		"history": history,
+	})
+}
@@ -0,0 +1,79 @@
+package main
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{StatusNew, StatusAcknowledged, true},
+		{StatusNew, StatusInProgress, true},
+		{StatusNew, StatusResolved, true},
+		{StatusNew, StatusRejected, true},
+		{StatusNew, StatusAwaitingPatient, false},
+		{StatusNew, StatusReopened, false},
+		{StatusNew, StatusNew, false},
+		{StatusAcknowledged, StatusAwaitingPatient, true},
+		{StatusAcknowledged, StatusNew, false},
+		{StatusInProgress, StatusAwaitingPatient, true},
+		{StatusInProgress, StatusAcknowledged, false},
+		{StatusAwaitingPatient, StatusInProgress, true},
+		{StatusAwaitingPatient, StatusReopened, false},
+		{StatusResolved, StatusReopened, true},
+		{StatusResolved, StatusInProgress, false},
+		{StatusResolved, StatusRejected, false},
+		{StatusRejected, StatusReopened, true},
+		{StatusRejected, StatusResolved, false},
+		{StatusReopened, StatusInProgress, true},
+		{StatusReopened, StatusNew, false},
+		{"processed", StatusResolved, false},
+		{StatusNew, "processed", false},
+	}
+
+	for _, tt := range tests {
+		if got := canTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("canTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestStatusTransitionsAreClosed(t *testing.T) {
+	for from, targets := range statusTransitions {
+		for _, to := range targets {
+			if !isSupportedStatus(to) {
+				t.Errorf("transition %s -> %s leads to an unknown status", from, to)
+			}
+			if to == from {
+				t.Errorf("status %s transitions to itself", from)
+			}
+		}
+	}
+
+	open := make(map[string]bool)
+	for _, status := range openStatuses {
+		open[status] = true
+		if !isSupportedStatus(status) {
+			t.Errorf("open status %s is not supported", status)
+		}
+	}
+	// Закрытое обращение можно только переоткрыть
+	for _, closed := range []string{StatusResolved, StatusRejected} {
+		if open[closed] {
+			t.Errorf("closed status %s is listed as open", closed)
+		}
+		if targets := statusTransitions[closed]; len(targets) != 1 || targets[0] != StatusReopened {
+			t.Errorf("closed status %s transitions to %v, want only %s", closed, targets, StatusReopened)
+		}
+	}
+}
+
+func TestStatusDisplayNamesTranslated(t *testing.T) {
+	for status := range statusTransitions {
+		for _, lang := range supportedLanguages {
+			if name := statusDisplayName(lang, status); name == status {
+				t.Errorf("status %s has no %s translation", status, lang)
+			}
+		}
+	}
+}
@@ -1,28 +1,38 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
-	"time"
+	"sync"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/sirupsen/logrus"
 )
 
-type UserState struct {
-	State string
-	Data  map[string]string
-}
-
 type TelegramBot struct {
-	bot      *tgbotapi.BotAPI
-	database *Database
-	email    *EmailService
-	logger   *logrus.Logger
-	users    map[int64]*UserState
+	bot         *tgbotapi.BotAPI
+	database    *Database
+	email       *EmailService
+	logger      *logrus.Logger
+	states      StateStore
+	attachments AttachmentStorage
+	vault       *IdentityVault
+	charts      *ChartRenderer
+	antispam    AntispamConfig
+	dispatcher  *UpdateDispatcher
+
+	formMu     sync.RWMutex
+	formEngine *FormEngine
+
+	// languages - кэш выбранных языков пользователей, см. forgetIdleLanguages
+	langMu    sync.RWMutex
+	languages map[int64]cachedLanguage
 }
 
-func NewTelegramBot(database *Database, email *EmailService, logger *logrus.Logger) (*TelegramBot, error) {
+func NewTelegramBot(database *Database, email *EmailService, states StateStore, attachments AttachmentStorage, vault *IdentityVault, charts *ChartRenderer, logger *logrus.Logger) (*TelegramBot, error) {
 	token := getEnv("TELEGRAM_BOT_TOKEN", "")
 	if token == "" {
 		return nil, fmt.Errorf("TELEGRAM_BOT_TOKEN is not set")
@@ -33,13 +43,33 @@ func NewTelegramBot(database *Database, email *EmailService, logger *logrus.Logg
 		return nil, fmt.Errorf("failed to create bot: %w", err)
 	}
 
-	return &TelegramBot{
-		bot:      bot,
-		database: database,
-		email:    email,
-		logger:   logger,
-		users:    make(map[int64]*UserState),
-	}, nil
+	t := &TelegramBot{
+		bot:         bot,
+		database:    database,
+		email:       email,
+		logger:      logger,
+		states:      states,
+		attachments: attachments,
+		vault:       vault,
+		charts:      charts,
+		antispam:    antispamConfigFromEnv(),
+		languages:   make(map[int64]cachedLanguage),
+	}
+
+	source, err := t.reloadForm()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load form definition: %w", err)
+	}
+	logger.Info("Form definition loaded from ", source)
+
+	t.dispatcher = NewUpdateDispatcher(
+		getEnvAsInt("BOT_WORKERS", 8),
+		getEnvAsInt("BOT_WORKER_QUEUE_SIZE", 100),
+		t.handleUpdate,
+		logger,
+	)
+
+	return t, nil
 }
 
 func (t *TelegramBot) Start() error {
@@ -50,11 +80,12 @@ func (t *TelegramBot) Start() error {
 
 	updates := t.bot.GetUpdatesChan(u)
 
+	// Досылаем карточки обращений, сохраненных без карточки
+	go t.postPendingCards()
+
 	for update := range updates {
-		if update.Message != nil {
-			t.handleMessage(update.Message)
-		} else if update.CallbackQuery != nil {
-			t.handleCallbackQuery(update.CallbackQuery)
+		if err := t.dispatcher.Dispatch(update); err != nil {
+			t.logger.Warn("Update dropped: ", err)
 		}
 	}
 
@@ -62,19 +93,25 @@ func (t *TelegramBot) Start() error {
 }
 
 func (t *TelegramBot) Stop() error {
+	t.bot.StopReceivingUpdates()
+	t.dispatcher.Stop()
 	return nil
 }
 
+// handleUpdate обрабатывает одно обновление; вызывается воркерами диспетчера
+func (t *TelegramBot) handleUpdate(update tgbotapi.Update) {
+	if update.Message != nil && update.Message.From != nil {
+		t.handleMessage(update.Message)
+	} else if update.CallbackQuery != nil {
+		t.handleCallbackQuery(update.CallbackQuery)
+	}
+}
+
 func (t *TelegramBot) handleMessage(message *tgbotapi.Message) {
 	userID := message.From.ID
-	state, exists := t.users[userID]
-	if !exists {
-		state = &UserState{
-			State: "start",
-			Data:  make(map[string]string),
-		}
-		t.users[userID] = state
-	}
+	state := t.loadState(userID)
+	defer t.saveState(userID, state)
+	t.rememberLanguage(message.From)
 
 	// Обрабатываем команды
 	if message.IsCommand() {
@@ -82,193 +119,525 @@ func (t *TelegramBot) handleMessage(message *tgbotapi.Message) {
 		return
 	}
 
+	// Ответ через reply на сообщение переписки по обращению
+	if t.handleThreadReply(message, state) {
+		return
+	}
+
+	// В группах отвечаем только на команды и ответы по обращениям, остальная переписка не для бота
+	if !message.Chat.IsPrivate() && state.State != StateWaitingForReply && state.State != StateWaitingForStatsPeriod {
+		return
+	}
+
 	// Обрабатываем текст в зависимости от состояния
 	switch state.State {
-	case "waiting_for_type":
+	case StateWaitingForType:
 		t.handleTypeSelection(message, state)
-	case "waiting_for_message":
+	case StateWaitingForAnonymity:
+		t.askAnonymity(message.Chat.ID, state)
+	case StateWaitingForDepartment:
+		t.askDepartment(message.Chat.ID, state)
+	case StateWaitingForMessage:
 		t.handleMessageInput(message, state)
+	case StateWaitingForContact:
+		t.handleContactInput(message, state)
+	case StateWaitingForAnswer:
+		t.handleAnswerInput(message, state)
+	case StateWaitingForRating:
+		t.handleRatingInput(message, state)
+	case StateWaitingForReply:
+		t.handleReplyInput(message, state)
+	case StateWaitingForStatsPeriod:
+		t.handleStatsPeriodInput(message, state)
+	case StateWaitingForConfirm:
+		t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "choose_button"))
+		t.showPreview(message.Chat.ID, state)
 	default:
-		t.sendMainMenu(message.Chat.ID, "Әрекетті таңдаңыз:")
+		t.sendMainMenu(message.Chat.ID, message.From.ID)
+	}
+}
+
+// loadState достает состояние диалога из хранилища или начинает новый диалог
+func (t *TelegramBot) loadState(userID int64) *UserState {
+	state, err := t.states.Get(userID)
+	if err != nil {
+		t.logger.Error("Failed to load user state: ", err)
+	}
+	if state == nil {
+		state = newUserState()
+	}
+	return state
+}
+
+// saveState сохраняет состояние диалога; начальное состояние хранить не нужно
+func (t *TelegramBot) saveState(userID int64, state *UserState) {
+	var err error
+	if state.State == StateStart && len(state.Data) == 0 {
+		err = t.states.Delete(userID)
+	} else {
+		err = t.states.Save(userID, state)
+	}
+	if err != nil {
+		t.logger.Error("Failed to save user state: ", err)
 	}
 }
 
 func (t *TelegramBot) handleCommand(message *tgbotapi.Message, state *UserState) {
 	switch message.Command() {
 	case "start":
-		state.State = "start"
-		state.Data = make(map[string]string)
-		t.sendMainMenu(message.Chat.ID, "Қош келдіңіз! Әрекетті таңдаңыз:")
+		t.discardPendingAttachments(state)
+		state.Reset()
+		t.sendStartGreeting(message, state)
 	case "menu":
-		t.sendMainMenu(message.Chat.ID, "Басты мәзір:")
+		t.sendMainMenu(message.Chat.ID, message.From.ID)
+	case "cancel":
+		t.cancelFeedback(message.Chat.ID, message.From.ID, state)
+	case "language":
+		t.askLanguage(message.Chat.ID)
+	case "mystatus":
+		t.showMyStatus(message.Chat.ID, message.From, 0, 0)
 	case "stats":
-		if t.isAdmin(message.From.ID) {
-			t.handleStats(message.Chat.ID)
+		if admin := t.authorize(message.From.ID, PermViewStats); admin != nil {
+			t.handleStatsCommand(message, admin)
+		} else {
+			t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "access.stats_denied"))
+		}
+	case "antispam":
+		if t.authorize(message.From.ID, PermViewStats) != nil {
+			t.showAntispam(message.Chat.ID)
+		} else {
+			t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "access.denied"))
+		}
+	case "departments", "dept_add", "dept_remove":
+		if t.authorize(message.From.ID, PermManageDepartments) != nil {
+			t.handleDepartmentCommand(message)
+		} else {
+			t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "access.denied"))
+		}
+	case "inbox", "list", "mytickets":
+		if t.authorize(message.From.ID, PermViewFeedback) != nil {
+			t.handleInboxCommand(message)
+		} else {
+			t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "access.denied"))
+		}
+	case "export":
+		if admin := t.authorize(message.From.ID, PermViewFeedback); admin != nil {
+			t.handleExportCommand(message, admin)
+		} else {
+			t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "access.denied"))
+		}
+	case "assign":
+		if t.authorize(message.From.ID, PermHandleFeedback) != nil {
+			t.handleAssignCommand(message)
+		} else {
+			t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "access.denied"))
+		}
+	case "status":
+		if t.authorize(message.From.ID, PermHandleFeedback) != nil {
+			t.handleStatusCommand(message)
+		} else {
+			t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "access.denied"))
+		}
+	case "reply":
+		if t.authorize(message.From.ID, PermHandleFeedback) != nil {
+			t.handleReplyCommand(message, state)
+		} else {
+			t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "access.denied"))
+		}
+	case "thread":
+		if t.authorize(message.From.ID, PermViewFeedback) != nil {
+			t.handleThreadCommand(message)
+		} else {
+			t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "access.denied"))
+		}
+	case "form", "form_reload":
+		if t.authorize(message.From.ID, PermManageForms) != nil {
+			t.handleFormCommand(message)
+		} else {
+			t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "access.denied"))
+		}
+	case "sla", "sla_set", "sla_remove", "holiday_add", "holiday_remove":
+		if t.authorize(message.From.ID, PermManageSLA) != nil {
+			t.handleSLACommand(message)
+		} else {
+			t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "access.denied"))
+		}
+	case "admins", "admin_add", "admin_email", "admin_remove":
+		if t.authorize(message.From.ID, PermManageAdmins) != nil {
+			t.handleAdminCommand(message)
 		} else {
-			t.sendMessage(message.Chat.ID, "❌ Сізде статистикаға қолжетімділік жоқ")
+			t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "access.denied"))
 		}
 	default:
-		t.sendMainMenu(message.Chat.ID, "Жұмысты бастау үшін /start пәрменін пайдаланыңыз")
+		t.sendMainMenu(message.Chat.ID, message.From.ID)
 	}
 }
 
 func (t *TelegramBot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 	userID := callback.From.ID
-	state, exists := t.users[userID]
-	if !exists {
-		state = &UserState{
-			State: "start",
-			Data:  make(map[string]string),
-		}
-		t.users[userID] = state
-	}
+	state := t.loadState(userID)
+	defer t.saveState(userID, state)
+	t.rememberLanguage(callback.From)
 
 	data := callback.Data
+	if value, ok := strings.CutPrefix(data, "dept:"); ok {
+		t.handleDepartmentSelection(callback.Message.Chat.ID, callback.From, state, value)
+		return
+	}
+	if value, ok := strings.CutPrefix(data, "anon:"); ok {
+		t.handleAnonymitySelection(callback.Message.Chat.ID, callback.From, state, value)
+		return
+	}
+	if payload, ok := strings.CutPrefix(data, "form:"); ok {
+		t.handleFormCallback(callback.Message.Chat.ID, callback.From, state, payload)
+		return
+	}
+	if value, ok := strings.CutPrefix(data, "rate:"); ok {
+		t.handleRatingSelection(callback.Message.Chat.ID, callback.From, state, value)
+		return
+	}
+	if action, ok := strings.CutPrefix(data, "confirm:"); ok {
+		t.handleConfirmation(callback, state, action)
+		return
+	}
+	if value, ok := strings.CutPrefix(data, "reply:"); ok {
+		t.handleReplyButton(callback.Message.Chat.ID, callback.From, state, value, MessageFromPatient)
+		return
+	}
+	if value, ok := strings.CutPrefix(data, "staffreply:"); ok {
+		t.handleReplyButton(callback.Message.Chat.ID, callback.From, state, value, MessageFromStaff)
+		return
+	}
+	if payload, ok := strings.CutPrefix(data, "card:"); ok {
+		t.handleCardAction(callback, payload)
+		return
+	}
+	if payload, ok := strings.CutPrefix(data, "inbox:"); ok {
+		t.handleInboxCallback(callback, payload)
+		return
+	}
+	if payload, ok := strings.CutPrefix(data, "stats:"); ok {
+		t.handleStatsCallback(callback, state, payload)
+		return
+	}
+	if value, ok := strings.CutPrefix(data, "mystatus:"); ok {
+		t.handleMyStatusPage(callback, value)
+		return
+	}
+	if value, ok := strings.CutPrefix(data, "lang:"); ok {
+		t.handleLanguageSelection(callback.Message.Chat.ID, callback.From, state, value)
+		return
+	}
+
 	switch data {
-	case "complaint":
-		state.State = "waiting_for_message"
-		state.Data["type"] = "complaint"
-		t.sendMessage(callback.Message.Chat.ID, "📝 Өтініш, шағымыңызды толық сипаттаңыз. Біз оны мүмкіндігінше қысқа мерзімде қарастырамыз.")
-	case "review":
-		state.State = "waiting_for_message"
-		state.Data["type"] = "review"
-		t.sendMessage(callback.Message.Chat.ID, "⭐ Өтініш, пікіріңізді толық сипаттаңыз. Біз сіздің пікіріңізді жоғары бағалаймыз.")
+	case "complaint", "review":
+		t.startFeedback(callback.Message.Chat.ID, callback.From, state, data)
 	case "stats":
-		if t.isAdmin(userID) {
-			t.handleStats(callback.Message.Chat.ID)
+		if admin := t.authorize(userID, PermViewStats); admin != nil {
+			period, _ := newStatsPeriod(statsWeek)
+			t.showPeriodStats(callback.Message.Chat.ID, 0, admin, period, 0)
 		} else {
-			t.sendMessage(callback.Message.Chat.ID, "❌Сізде статистикаға қолжетімділік жоқ")
+			t.sendMessage(callback.Message.Chat.ID, t.tr(callback.Message.Chat.ID, "access.stats_denied"))
+		}
+	case "inbox":
+		t.showInbox(callback.Message.Chat.ID, callback.From, inboxFilter{Status: inboxOpen}, 0, 0)
+	case "mytickets":
+		t.showInbox(callback.Message.Chat.ID, callback.From, inboxFilter{Status: inboxOpen, AssigneeID: userID}, 0, 0)
+	case "submit_feedback":
+		if state.State == StateWaitingForMessage && state.Data["attachments"] != "" {
+			t.advanceForm(callback.Message.Chat.ID, callback.From, state)
+		} else {
+			t.sendMainMenu(callback.Message.Chat.ID, callback.From.ID)
 		}
 	case "new_request":
-		state.State = "start"
-		state.Data = make(map[string]string)
-		t.sendMainMenu(callback.Message.Chat.ID, "")
+		t.discardPendingAttachments(state)
+		state.Reset()
+		t.sendMainMenu(callback.Message.Chat.ID, callback.From.ID)
 	case "help":
 		t.sendHelp(callback.Message.Chat.ID)
+	case "language":
+		t.askLanguage(callback.Message.Chat.ID)
+	case "mystatus":
+		t.showMyStatus(callback.Message.Chat.ID, callback.From, 0, 0)
 	case "back_to_menu":
-		state.State = "start"
-		state.Data = make(map[string]string)
-		t.sendMainMenu(callback.Message.Chat.ID, "")
+		t.discardPendingAttachments(state)
+		state.Reset()
+		t.sendMainMenu(callback.Message.Chat.ID, callback.From.ID)
 	default:
-		t.sendMainMenu(callback.Message.Chat.ID, "")
+		t.sendMainMenu(callback.Message.Chat.ID, callback.From.ID)
 	}
 }
 
 func (t *TelegramBot) handleTypeSelection(message *tgbotapi.Message, state *UserState) {
-	text := strings.ToLower(strings.TrimSpace(message.Text))
-
-	switch text {
-	case "жалоба", "complaint":
-		state.State = "waiting_for_message"
-		state.Data["type"] = "complaint"
-		t.sendMessage(message.Chat.ID, "📝 Өтініш, шағымыңызды толық сипаттаңыз. Біз оны мүмкіндігінше қысқа мерзімде қарастырамыз.")
-	case "отзыв", "review":
-		state.State = "waiting_for_message"
-		state.Data["type"] = "review"
-		t.sendMessage(message.Chat.ID, "⭐ Өтініш, пікіріңізді толық сипаттаңыз. Біз сіздің пікіріңізді жоғары бағалаймыз.")
-	default:
-		t.sendMessage(message.Chat.ID, "Өтініш, ‘шағым’ немесе ‘пікір’ таңдаңыз")
+	if feedbackType := parseFeedbackType(message.Text); feedbackType != "" {
+		t.startFeedback(message.Chat.ID, message.From, state, feedbackType)
+		return
 	}
+	t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "type.invalid"))
 }
 
-func (t *TelegramBot) handleMessageInput(message *tgbotapi.Message, state *UserState) {
-	feedbackType := state.Data["type"]
+// parseFeedbackType распознает тип обращения, написанный словом на любом из языков бота
+func parseFeedbackType(text string) string {
+	text = strings.ToLower(strings.TrimSpace(text))
+	for _, feedbackType := range []string{"complaint", "review"} {
+		if text == feedbackType {
+			return feedbackType
+		}
+		for _, catalog := range catalogs {
+			if text == strings.ToLower(catalog["type."+feedbackType]) {
+				return feedbackType
+			}
+		}
+	}
+	return ""
+}
 
-	// Используем правильный часовой пояс
-	timezone := getEnv("TIMEZONE", "Asia/Almaty")
-
-	// Используем фиксированное смещение для Asia/Almaty (UTC+5)
-	var currentTime time.Time
-	if timezone == "Asia/Almaty" {
-		// Создаем фиксированное смещение UTC+5
-		loc := time.FixedZone("Asia/Almaty", 5*60*60) // +5 часов в секундах
-		currentTime = time.Now().In(loc)
+// askMessage просит пользователя описать обращение
+func (t *TelegramBot) askMessage(chatID int64, state *UserState) bool {
+	if state.Data["type"] == "review" {
+		t.sendMessage(chatID, t.tr(chatID, "message.ask.review"))
 	} else {
-		// Пытаемся загрузить часовой пояс
-		loc, err := time.LoadLocation(timezone)
-		if err != nil {
-			// Если не удалось загрузить часовой пояс, используем UTC
-			loc = time.UTC
+		t.sendMessage(chatID, t.tr(chatID, "message.ask.complaint"))
+	}
+	return true
+}
+
+func (t *TelegramBot) handleMessageInput(message *tgbotapi.Message, state *UserState) {
+	text := message.Text
+	if text == "" {
+		text = message.Caption
+	}
+
+	attachments := extractAttachments(message)
+	if len(attachments) == 0 && text == "" {
+		t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "message.empty"))
+		return
+	}
+
+	// Слишком короткий или длинный текст не сохраняем: пользователь может прислать его заново.
+	// С вложениями проверяется только максимальная длина.
+	hasAttachments := len(attachments) > 0 || len(pendingAttachments(state)) > 0
+	if rejection := t.antispam.checkLength(joinMessageText(state.Data["message"], text), hasAttachments); rejection != nil {
+		t.rejectSpam(message.Chat.ID, rejection)
+		return
+	}
+
+	if len(attachments) == 0 {
+		// Текст без вложений завершает обращение вместе с ранее присланными файлами
+		state.Data["message"] = joinMessageText(state.Data["message"], text)
+		t.advanceForm(message.Chat.ID, message.From, state)
+		return
+	}
+
+	// Вложения копим в состоянии, пока пользователь не подтвердит отправку:
+	// альбом из нескольких фото приходит отдельными сообщениями
+	pending := pendingAttachments(state)
+	for _, attachment := range attachments {
+		if err := t.downloadAttachment(attachment); err != nil {
+			if errors.Is(err, errAttachmentTooLarge) {
+				t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "attachment.too_large", attachmentMaxSizeMB()))
+				continue
+			}
+			t.logger.Error("Failed to download attachment: ", err)
+			t.sendMessage(message.Chat.ID, t.tr(message.Chat.ID, "attachment.failed"))
+			continue
 		}
-		currentTime = time.Now().In(loc)
+		pending = append(pending, attachment)
+	}
+	setPendingAttachments(state, pending)
+	state.Data["message"] = joinMessageText(state.Data["message"], text)
+
+	if len(pending) == 0 {
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(message.Chat.ID, "button.submit"), "submit_feedback"),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, t.tr(message.Chat.ID, "attachment.received", len(pending)))
+	msg.ReplyMarkup = keyboard
+	t.bot.Send(msg)
+}
+
+// submitFeedback сохраняет обращение вместе с накопленными вложениями и отправляет email
+func (t *TelegramBot) submitFeedback(chatID int64, from *tgbotapi.User, state *UserState, text string) {
+	feedbackType := state.Data["type"]
+
+	// Ограничения частоты и повторы проверяем еще раз перед сохранением:
+	// за время заполнения анкеты пользователь мог отправить обращение из другого чата
+	rejection := t.checkRateLimit(from.ID)
+	if rejection == nil {
+		rejection = t.checkDuplicate(from.ID, text)
+	}
+	if rejection != nil {
+		t.discardPendingAttachments(state)
+		state.Reset()
+		t.rejectSpam(chatID, rejection)
+		return
 	}
 
+	// Используем правильный часовой пояс
+	currentTime := nowInTimezone()
+
 	feedback := &Feedback{
-		UserID:    message.From.ID,
-		Username:  message.From.UserName,
-		FirstName: message.From.FirstName,
-		LastName:  message.From.LastName,
-		Message:   message.Text,
+		UserID:    from.ID,
+		Username:  from.UserName,
+		FirstName: from.FirstName,
+		LastName:  from.LastName,
+		Message:   text,
 		Type:      feedbackType,
-		Status:    "new",
+		Status:    StatusNew,
 		CreatedAt: currentTime, // Устанавливаем время в правильном часовом поясе
 	}
+	feedback.Location = state.Data["location"]
+	feedback.Phone = state.Data["phone"]
+	// Оценка остается только у отзыва: тип могли сменить в предпросмотре
+	if rating, ok := parseRating(state.Data["rating"]); ok && feedbackType == "review" {
+		feedback.Rating = rating
+	}
+	if state.Data["anonymous"] == "1" {
+		// Данные автора анонимного обращения в feedback не попадают
+		replyToken, err := newReplyToken()
+		if err != nil {
+			t.logger.Error("Failed to create reply token: ", err)
+			t.sendMessage(chatID, t.tr(chatID, "error.save"))
+			return
+		}
+		feedback.Anonymous = true
+		feedback.ReplyToken = replyToken
+		feedback.UserID = 0
+		feedback.Username = ""
+		feedback.FirstName = ""
+		feedback.LastName = ""
+		feedback.Phone = ""
+	}
+	if departmentID, err := strconv.ParseInt(state.Data["department_id"], 10, 64); err == nil {
+		feedback.DepartmentID = departmentID
+		if department, err := t.database.GetDepartment(departmentID); err == nil && department != nil {
+			feedback.DepartmentName = department.Name
+		}
+	}
 
-	// Сохраняем в базу данных
-	if err := t.database.SaveFeedback(feedback); err != nil {
+	// Рассчитываем срок ответа по политике SLA и сохраняем в базу данных
+	t.setDeadline(feedback)
+	identity, err := t.anonymousIdentity(feedback, from, chatID)
+	if err != nil {
+		t.logger.Error("Failed to prepare anonymous identity: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.save"))
+		return
+	}
+	if err := t.database.SaveFeedbackWithTicket(feedback, identity); err != nil {
 		t.logger.Error("Failed to save feedback: ", err)
-		t.sendMessage(message.Chat.ID, " Сақтау кезінде қате орын алды. Кейінірек қайталап көріңіз.")
+		t.sendMessage(chatID, t.tr(chatID, "error.save"))
 		return
 	}
 
+	t.recordSubmission(from.ID, text, feedback.Anonymous)
+
+	// Сохраняем ответы на дополнительные вопросы анкеты
+	for _, answer := range t.form().Answers(state.Data, staffLanguage()) {
+		answer.FeedbackID = feedback.ID
+		if err := t.database.SaveFeedbackAnswer(answer); err != nil {
+			t.logger.Error("Failed to save feedback answer: ", err)
+			continue
+		}
+		feedback.Answers = append(feedback.Answers, answer)
+	}
+
+	// Привязываем вложения к сохраненному обращению
+	for _, attachment := range pendingAttachments(state) {
+		attachment.FeedbackID = feedback.ID
+		if err := t.database.SaveAttachment(attachment); err != nil {
+			t.logger.Error("Failed to save attachment: ", err)
+			continue
+		}
+		feedback.Attachments = append(feedback.Attachments, attachment)
+	}
+
 	// Отправляем email
 	if err := t.email.SendFeedbackEmail(feedback); err != nil {
 		t.logger.Error("Failed to send email: ", err)
 	}
 
-	// Отправляем подтверждение пользователю с кнопками
-	responseText := fmt.Sprintf("✅ Сіздің %s сәтті жіберілді!\n\nБіз сіздің %s қарап, қажетті шараларды қабылдаймыз.\n\nТағы бір өтініш жібергіңіз келе ме?",
-		getTypeDisplayName(feedbackType), getTypeDisplayName(feedbackType))
+	// Назначаем ответственного и публикуем карточку обращения в чате сотрудников
+	t.autoAssign(feedback)
+	t.postFeedbackCard(feedback)
 
-	t.sendConfirmationMenu(message.Chat.ID, responseText)
+	// Отправляем подтверждение пользователю с кнопками
+	t.sendConfirmationMenu(chatID, t.tr(chatID, "feedback.sent."+feedbackType)+"\n\n"+t.tr(chatID, "feedback.ticket", feedback.TicketCode))
 
 	// Сбрасываем состояние
-	state.State = "start"
-	state.Data = make(map[string]string)
+	state.Reset()
 }
 
-func (t *TelegramBot) sendMainMenu(chatID int64, text string) {
-	// Проверяем, является ли пользователь администратором
-	isAdmin := t.isAdmin(chatID)
-
-	// Заголовок меню
-	menuText := "🏥 Аурухананың кері байланыс жүйесінің басты мәзірі\n\nӘрекетті таңдаңыз:"
-
-	var keyboard tgbotapi.InlineKeyboardMarkup
-
-	if isAdmin {
-		// Меню для администратора с кнопкой статистики
-		keyboard = tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("📝 Шағым жіберу", "complaint"),
-			),
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("⭐ Пікір қалдыру", "review"),
-			),
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("❓ Көмек", "help"),
-			),
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("📊 Статистика", "stats"),
-			),
-		)
-	} else {
-		// Меню для обычных пользователей без статистики
-		keyboard = tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("📝 Шағым жіберу", "complaint"),
-			),
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("⭐ Пікір қалдыру", "review"),
-			),
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("❓ Көмек", "help"),
-			),
-		)
-	}
-
-	msg := tgbotapi.NewMessage(chatID, menuText)
-	msg.ReplyMarkup = keyboard
+func pendingAttachments(state *UserState) []*Attachment {
+	var attachments []*Attachment
+	if raw := state.Data["attachments"]; raw != "" {
+		json.Unmarshal([]byte(raw), &attachments)
+	}
+	return attachments
+}
+
+func setPendingAttachments(state *UserState, attachments []*Attachment) {
+	if len(attachments) == 0 {
+		delete(state.Data, "attachments")
+		return
+	}
+	raw, _ := json.Marshal(attachments)
+	state.Data["attachments"] = string(raw)
+}
+
+func joinMessageText(current, addition string) string {
+	switch {
+	case current == "":
+		return addition
+	case addition == "":
+		return current
+	default:
+		return current + "\n\n" + addition
+	}
+}
+
+// sendMainMenu показывает главное меню; набор кнопок зависит от роли пользователя userID
+func (t *TelegramBot) sendMainMenu(chatID, userID int64) {
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.complaint"), "complaint"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.review"), "review"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.mystatus"), "mystatus"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.help"), "help"),
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.language"), "language"),
+		),
+	}
+
+	// Сотрудникам дополнительно показываем кнопки статистики и списка обращений
+	var staffRow []tgbotapi.InlineKeyboardButton
+	if t.authorize(userID, PermViewStats) != nil {
+		staffRow = append(staffRow, tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.stats"), "stats"))
+	}
+	if t.authorize(userID, PermViewFeedback) != nil {
+		staffRow = append(staffRow, tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.inbox"), "inbox"))
+	}
+	if len(staffRow) > 0 {
+		rows = append(rows, staffRow)
+	}
+	if t.authorize(userID, PermHandleFeedback) != nil {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.my_tickets"), "mytickets"),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, t.tr(chatID, "menu.title"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
 	t.bot.Send(msg)
 }
 
@@ -277,42 +646,99 @@ func (t *TelegramBot) sendMessage(chatID int64, text string) {
 	t.bot.Send(msg)
 }
 
-func (t *TelegramBot) handleStats(chatID int64) {
-	stats, err := t.database.GetFeedbackStats()
+// handleStatsCommand обрабатывает /stats [me|user_id] [today|7d|30d|all|дата с [дата по]] -
+// статистика за период (по умолчанию 7 дней), в целом или по ответственному
+func (t *TelegramBot) handleStatsCommand(message *tgbotapi.Message, admin *Admin) {
+	chatID := message.Chat.ID
+	var assigneeID int64
+	periodName := statsWeek
+	var dates []string
+	for _, arg := range strings.Fields(message.CommandArguments()) {
+		switch arg = strings.ToLower(arg); arg {
+		case "me":
+			assigneeID = message.From.ID
+		case statsToday, statsWeek, statsMonth, statsAll:
+			periodName = arg
+		default:
+			if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+				assigneeID = id
+				continue
+			}
+			dates = append(dates, arg)
+		}
+	}
+
+	if periodName == statsAll && len(dates) == 0 {
+		t.handleStats(chatID, 0, admin, assigneeID)
+		return
+	}
+
+	period, ok := newStatsPeriod(periodName)
+	switch len(dates) {
+	case 0:
+	case 1:
+		period, ok = customStatsPeriod(dates[0], dates[0])
+	case 2:
+		period, ok = customStatsPeriod(dates[0], dates[1])
+	default:
+		ok = false
+	}
+	if !ok {
+		t.sendMessage(chatID, t.tr(chatID, "stats.usage", maxStatsPeriodDays))
+		return
+	}
+	t.showPeriodStats(chatID, 0, admin, period, assigneeID)
+}
+
+// handleStats показывает статистику за все время; заведующему отделением - только по его отделению.
+// assigneeID != 0 ограничивает статистику обращениями одного ответственного.
+func (t *TelegramBot) handleStats(chatID int64, messageID int, admin *Admin, assigneeID int64) {
+	stats, err := t.database.GetFeedbackStats(admin.ScopeDepartmentID(), assigneeID)
 	if err != nil {
 		t.logger.Error("Failed to get stats: ", err)
-		t.sendMessage(chatID, "❌ Статистиканы алу кезінде қате орын алды")
+		t.sendMessage(chatID, t.tr(chatID, "stats.error"))
 		return
 	}
 
-	complaints := stats["complaint"]
-	reviews := stats["review"]
+	complaints := stats.ByType["complaint"]
+	reviews := stats.ByType["review"]
 	total := complaints + reviews
 
-	statsText := fmt.Sprintf("📊 Өтініштер статистикасы\n\n"+
-		"📝 Шағымдар: %d\n"+
-		"⭐ Пікірлер: %d\n"+
-		"📈 Барлығы: %d", complaints, reviews, total)
-
-	// Отправляем статистику с кнопкой возврата в главное меню
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Басты мәзір", "back_to_menu"),
-		),
-	)
+	statsText := t.tr(chatID, "stats.summary", complaints, reviews, total)
+	if scope := admin.ScopeDepartmentID(); scope != 0 {
+		if department, err := t.database.GetDepartment(scope); err == nil && department != nil {
+			statsText += "\n" + t.tr(chatID, "stats.scope", department.Name)
+		}
+	}
+	if assigneeID != 0 {
+		statsText += "\n" + t.tr(chatID, "stats.assignee", t.staffName(assigneeID))
+	}
+	statsText += "\n\n" + t.formatRatingStats(chatID, stats.Ratings)
+
+	if len(stats.ByDepartment) > 0 {
+		statsText += "\n\n" + t.tr(chatID, "stats.by_department")
+		for _, department := range stats.ByDepartment {
+			name := department.Name
+			if name == "" {
+				name = t.tr(chatID, "stats.not_specified")
+			}
+			statsText += fmt.Sprintf("\n• %s: %d (📝 %d / ⭐ %d)%s", name, department.Total(), department.Complaints, department.Reviews,
+				formatDepartmentRating(department.Ratings))
+		}
+	}
 
-	msg := tgbotapi.NewMessage(chatID, statsText)
-	msg.ReplyMarkup = keyboard
-	t.bot.Send(msg)
+	// Отправляем статистику с выбором периода и кнопкой возврата в главное меню
+	t.sendOrEdit(chatID, messageID, truncateRunes(statsText, 4000), t.statsKeyboard(chatID, statsAll, assigneeID, nil))
 }
 
 func (t *TelegramBot) sendConfirmationMenu(chatID int64, text string) {
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏥 Жаңа өтініш", "new_request"),
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.new_request"), "new_request"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("❓ Көмек", "help"),
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.mystatus"), "mystatus"),
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.help"), "help"),
 		),
 	)
 
@@ -322,26 +748,12 @@ func (t *TelegramBot) sendConfirmationMenu(chatID int64, text string) {
 }
 
 func (t *TelegramBot) sendHelp(chatID int64) {
-	helpText := `ℹ️ Көмек
-
-📝 Шағым жіберу үшін:
-1. "📝 Шағым жіберу" батырмасын шертіңіз
-2. Шағымыңызды толық сипаттаңыз
-3. Хабарламаны жіберіңіз
-
-⭐ Пікірді қалай қалдыруға болады:
-1. ⭐ Пікір қалдыру” батырмасын басыңыз
-2. Пікіріңізді толық сипаттаңыз
-3. Хабарламаны жіберіңіз
-
-📧 Сіздің өтінішіңіз әкімшілікке email арқылы жіберіледі..
-
-🔙 Басты мәзірге оралу үшін /start немесе /menu пәрменін пайдаланыңыз`
+	helpText := t.tr(chatID, "help.text")
 
 	// Отправляем помощь с кнопкой возврата в главное меню
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Басты мәзір", "back_to_menu"),
+			tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.main_menu"), "back_to_menu"),
 		),
 	)
 
@@ -350,18 +762,12 @@ func (t *TelegramBot) sendHelp(chatID int64) {
 	t.bot.Send(msg)
 }
 
-func getTypeDisplayName(feedbackType string) string {
+// getTypeDisplayName возвращает название типа обращения на языке lang
+func getTypeDisplayName(lang, feedbackType string) string {
 	switch feedbackType {
-	case "complaint":
-		return "шағым жіберу"
-	case "review":
-		return "Пікір қалдыру"
+	case "complaint", "review":
+		return Translate(lang, "type."+feedbackType)
 	default:
 		return feedbackType
 	}
 }
-
-func (t *TelegramBot) isAdmin(userID int64) bool {
-	adminID := getEnvAsInt("ADMIN_USER_ID", 0)
-	return userID == int64(adminID)
-}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ticketAlphabet - символы кода обращения без похожих друг на друга 0/O и 1/I/L
+const ticketAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// myStatusPageSize - сколько обращений показывать на одной странице /mystatus
+const myStatusPageSize = 5
+
+// ticketCodeAttempts - сколько раз генерировать код заново, если он совпал с уже выданным
+const ticketCodeAttempts = 5
+
+// errDuplicateTicketCode - код обращения уже выдан другому обращению
+var errDuplicateTicketCode = errors.New("ticket code already exists")
+
+// newTicketCode создает код обращения для пациента, например "K7QM-3XPA"
+func newTicketCode() (string, error) {
+	const length = 8
+	// 256 не делится на длину алфавита: байты из неполного последнего круга отбрасываются,
+	// иначе первые символы алфавита выпадали бы чаще остальных
+	limit := 256 - 256%len(ticketAlphabet)
+
+	code := make([]byte, 0, length+1)
+	buf := make([]byte, 2*length)
+	for len(code) < length+1 {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate ticket code: %w", err)
+		}
+		for _, b := range buf {
+			if int(b) >= limit || len(code) == length+1 {
+				continue
+			}
+			if len(code) == length/2 {
+				code = append(code, '-')
+			}
+			code = append(code, ticketAlphabet[int(b)%len(ticketAlphabet)])
+		}
+	}
+	return string(code), nil
+}
+
+// SaveFeedbackWithTicket выдает обращению код и сохраняет его.
+// Если код совпал с уже выданным (уникальный индекс idx_ticket_code), код генерируется заново.
+func (d *Database) SaveFeedbackWithTicket(feedback *Feedback, identity *FeedbackIdentity) error {
+	for attempt := 1; ; attempt++ {
+		code, err := newTicketCode()
+		if err != nil {
+			return err
+		}
+		feedback.TicketCode = code
+
+		err = d.SaveFeedback(feedback, identity)
+		if !errors.Is(err, errDuplicateTicketCode) || attempt == ticketCodeAttempts {
+			return err
+		}
+	}
+}
+
+// GetUserFeedbacks возвращает страницу обращений пользователя, новые сверху, и их общее число.
+// Анонимные обращения находятся по user_hash из feedback_identities.
+func (d *Database) GetUserFeedbacks(userID int64, userHash string, limit, offset int) ([]*Feedback, int, error) {
+	where := `
+	WHERE f.user_id = ? OR f.id IN (SELECT feedback_id FROM feedback_identities WHERE user_hash = ?)
+	`
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM feedback f" + where
+	if err := d.db.QueryRow(countQuery, userID, userHash).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count user feedbacks: %w", err)
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	query := feedbackSelect + where + `
+	ORDER BY f.created_at DESC, f.id DESC
+	LIMIT ? OFFSET ?
+	`
+
+	rows, err := d.db.Query(query, userID, userHash, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query user feedbacks: %w", err)
+	}
+	defer rows.Close()
+
+	var feedbacks []*Feedback
+	for rows.Next() {
+		feedback, err := scanFeedback(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		feedbacks = append(feedbacks, feedback)
+	}
+
+	return feedbacks, total, nil
+}
+
+// showMyStatus показывает пациенту его обращения со статусами.
+// messageID != 0 - страница листается кнопками, сообщение редактируется на месте.
+func (t *TelegramBot) showMyStatus(chatID int64, from *tgbotapi.User, page int, messageID int) {
+	if page < 0 {
+		page = 0
+	}
+
+	feedbacks, total, err := t.database.GetUserFeedbacks(from.ID, t.vault.UserHash(from.ID), myStatusPageSize, page*myStatusPageSize)
+	if err != nil {
+		t.logger.Error("Failed to get user feedbacks: ", err)
+		t.sendMessage(chatID, t.tr(chatID, "error.generic"))
+		return
+	}
+
+	lang := t.lang(chatID)
+	var text string
+	if total == 0 {
+		text = Translate(lang, "mystatus.empty")
+	} else {
+		pages := (total + myStatusPageSize - 1) / myStatusPageSize
+		var sb strings.Builder
+		sb.WriteString(Translate(lang, "mystatus.title", page+1, pages))
+		for _, feedback := range feedbacks {
+			sb.WriteString("\n\n" + myStatusLine(lang, feedback))
+		}
+		text = sb.String()
+	}
+
+	t.sendOrEdit(chatID, messageID, text, tgbotapi.NewInlineKeyboardMarkup(t.myStatusPager(chatID, page, total)...))
+}
+
+// myStatusPager - кнопки листания страниц /mystatus и возврата в меню
+func (t *TelegramBot) myStatusPager(chatID int64, page, total int) [][]tgbotapi.InlineKeyboardButton {
+	var pager []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		pager = append(pager, tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.prev"), "mystatus:"+strconv.Itoa(page-1)))
+	}
+	if (page+1)*myStatusPageSize < total {
+		pager = append(pager, tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.next"), "mystatus:"+strconv.Itoa(page+1)))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if len(pager) > 0 {
+		rows = append(rows, pager)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(t.tr(chatID, "button.main_menu"), "back_to_menu"),
+	))
+	return rows
+}
+
+// handleMyStatusPage обрабатывает кнопки mystatus:<страница>
+func (t *TelegramBot) handleMyStatusPage(callback *tgbotapi.CallbackQuery, value string) {
+	page, err := strconv.Atoi(value)
+	if err != nil {
+		page = 0
+	}
+	t.showMyStatus(callback.Message.Chat.ID, callback.From, page, callback.Message.MessageID)
+}
+
+// myStatusLine описывает одно обращение в списке /mystatus
+func myStatusLine(lang string, feedback *Feedback) string {
+	return Translate(lang, "mystatus.item",
+		ticketLabel(feedback),
+		getTypeDisplayName(lang, feedback.Type),
+		inTimezone(feedback.CreatedAt).Format("02.01.2006"),
+		statusDisplayName(lang, feedback.Status),
+		inTimezone(feedback.UpdatedAt).Format("02.01.2006 15:04"),
+	)
+}
+
+// ticketLabel - код обращения для показа людям; у старых обращений кода нет
+func ticketLabel(feedback *Feedback) string {
+	if feedback.TicketCode != "" {
+		return feedback.TicketCode
+	}
+	return "#" + strconv.FormatInt(feedback.ID, 10)
+}
+
+// statusDisplayName - понятное пациенту название статуса обращения
+func statusDisplayName(lang, status string) string {
+	key := "status." + status
+	if text := Translate(lang, key); text != key {
+		return text
+	}
+	return status
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestNewTicketCode(t *testing.T) {
+	format := regexp.MustCompile(`^[` + ticketAlphabet + `]{4}-[` + ticketAlphabet + `]{4}$`)
+	seen := make(map[string]bool)
+
+	for i := 0; i < 2000; i++ {
+		code, err := newTicketCode()
+		if err != nil {
+			t.Fatalf("newTicketCode: %v", err)
+		}
+		if !format.MatchString(code) {
+			t.Fatalf("code %q does not match XXXX-XXXX over the ticket alphabet", code)
+		}
+		if strings.ContainsAny(code, "01OIL") {
+			t.Fatalf("code %q contains an ambiguous character", code)
+		}
+		// FindFeedback считает числовую ссылку номером обращения, код не должен так разбираться
+		if _, err := strconv.ParseInt(strings.TrimPrefix(code, "#"), 10, 64); err == nil {
+			t.Fatalf("code %q parses as a feedback id", code)
+		}
+		if seen[code] {
+			t.Fatalf("duplicate code %q after %d codes", code, i)
+		}
+		seen[code] = true
+	}
+}
+
+func TestTicketLabel(t *testing.T) {
+	tests := []struct {
+		feedback *Feedback
+		want     string
+	}{
+		{&Feedback{ID: 42, TicketCode: "K7QM-3XPA"}, "K7QM-3XPA"},
+		{&Feedback{ID: 42}, "#42"},
+	}
+
+	for _, tt := range tests {
+		if got := ticketLabel(tt.feedback); got != tt.want {
+			t.Errorf("ticketLabel(%+v) = %q, want %q", tt.feedback, got, tt.want)
+		}
+	}
+}
+
+func TestIsDuplicateTicketCode(t *testing.T) {
+	duplicate := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'K7QM-3XPA' for key 'feedback.idx_ticket_code'"}
+	if !isDuplicateKey(fmt.Errorf("insert: %w", duplicate), "idx_ticket_code") {
+		t.Fatal("wrapped duplicate ticket code is not detected")
+	}
+
+	otherIndex := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'abc' for key 'feedback.idx_reply_token'"}
+	otherError := &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: idx_ticket_code"}
+	for _, err := range []error{otherIndex, otherError, errors.New("idx_ticket_code"), nil} {
+		if isDuplicateKey(err, "idx_ticket_code") {
+			t.Fatalf("isDuplicateKey(%v) = true", err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 func getEnv(key, defaultValue string) string {
@@ -20,3 +21,56 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// nowInTimezone возвращает текущее время в часовом поясе TIMEZONE
+func nowInTimezone() time.Time {
+	// Используем правильный часовой пояс
+	timezone := getEnv("TIMEZONE", "Asia/Almaty")
+
+	// Используем фиксированное смещение для Asia/Almaty (UTC+5)
+	if timezone == "Asia/Almaty" {
+		// Создаем фиксированное смещение UTC+5
+		loc := time.FixedZone("Asia/Almaty", 5*60*60) // +5 часов в секундах
+		return time.Now().In(loc)
+	}
+
+	// Пытаемся загрузить часовой пояс
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		// Если не удалось загрузить часовой пояс, используем UTC
+		loc = time.UTC
+	}
+	return time.Now().In(loc)
+}
+
+// inTimezone переводит время из базы в часовой пояс TIMEZONE для показа пользователю
+func inTimezone(t time.Time) time.Time {
+	return t.In(nowInTimezone().Location())
+}
+
+// truncateRunes обрезает текст до limit символов, чтобы сообщение уложилось в лимит Telegram
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}